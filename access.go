@@ -0,0 +1,100 @@
+package tableau4go
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// AccessUser is a user in an AccessSnapshot. Name and Email are lower cased
+// and trimmed, since identity providers compare them without case. Groups
+// lists the names of the user's groups, sorted.
+type AccessUser struct {
+	ID          UserID   `json:"id"`
+	Name        string   `json:"name"`
+	FullName    string   `json:"fullName,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	SiteRole    SiteRole `json:"siteRole"`
+	License     License  `json:"license,omitempty"`
+	AuthSetting string   `json:"authSetting,omitempty"`
+	Groups      []string `json:"groups"`
+}
+
+// AccessGroup is a group in an AccessSnapshot. Members lists the normalized
+// names of its users, sorted.
+type AccessGroup struct {
+	ID      GroupID  `json:"id"`
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// AccessSnapshot is who has access to a site: every user with their role,
+// license and groups, and every group with its members. Users and groups are
+// sorted by name, so two snapshots, or a snapshot and an export from an HR
+// system or identity provider, can be diffed line by line.
+type AccessSnapshot struct {
+	SiteID  SiteID        `json:"siteId"`
+	TakenAt time.Time     `json:"takenAt"`
+	Users   []AccessUser  `json:"users"`
+	Groups  []AccessGroup `json:"groups"`
+}
+
+// TakeAccessSnapshot lists the site's users and groups and each group's
+// members. It reads every page of each, so it takes one request per group on
+// top of the listings.
+func (api *API) TakeAccessSnapshot(siteId SiteID) (AccessSnapshot, error) {
+	snapshot := AccessSnapshot{SiteID: siteId, TakenAt: api.now().UTC(), Users: []AccessUser{}, Groups: []AccessGroup{}}
+	users := map[UserID]*AccessUser{}
+	siteUsers, err := api.queryAllUsers(siteId, ListOptions{})
+	if err != nil {
+		return snapshot, err
+	}
+	for _, user := range siteUsers {
+		users[user.ID] = &AccessUser{
+			ID:          user.ID,
+			Name:        normalizeIdentity(user.Name),
+			FullName:    strings.TrimSpace(user.FullName),
+			Email:       normalizeIdentity(user.Email),
+			SiteRole:    user.SiteRole,
+			License:     user.SiteRole.License(),
+			AuthSetting: user.AuthSetting,
+			Groups:      []string{},
+		}
+	}
+	groups, err := api.queryAllGroups(siteId, ListOptions{})
+	if err != nil {
+		return snapshot, err
+	}
+	for _, group := range groups {
+		access := AccessGroup{ID: group.ID, Name: group.Name, Members: []string{}}
+		members, err := api.queryAllUsersInGroup(siteId, group.ID)
+		if err != nil {
+			return snapshot, err
+		}
+		for _, member := range members {
+			access.Members = append(access.Members, normalizeIdentity(member.Name))
+			if user, ok := users[member.ID]; ok {
+				user.Groups = append(user.Groups, group.Name)
+			}
+		}
+		sort.Strings(access.Members)
+		snapshot.Groups = append(snapshot.Groups, access)
+	}
+	for _, user := range users {
+		sort.Strings(user.Groups)
+		snapshot.Users = append(snapshot.Users, *user)
+	}
+	sort.Slice(snapshot.Users, func(i, j int) bool {
+		a, b := snapshot.Users[i], snapshot.Users[j]
+		return a.Name < b.Name || (a.Name == b.Name && a.ID < b.ID)
+	})
+	sort.Slice(snapshot.Groups, func(i, j int) bool {
+		a, b := snapshot.Groups[i], snapshot.Groups[j]
+		return a.Name < b.Name || (a.Name == b.Name && a.ID < b.ID)
+	})
+	return snapshot, nil
+}
+
+func normalizeIdentity(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
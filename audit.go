@@ -0,0 +1,62 @@
+package tableau4go
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+)
+
+// AuditRecord describes one mutating call (POST, PUT or DELETE) made by the
+// client. The payload is recorded as a SHA-256 digest so the audit trail can
+// prove what was sent without retaining content or embedded credentials.
+type AuditRecord struct {
+	Time          time.Time
+	UserID        UserID
+	SiteID        SiteID
+	Method        string
+	URL           string
+	PayloadSHA256 string
+	StatusCode    int
+	// Error is the transport or Tableau error, empty if the call succeeded
+	Error  string
+	DryRun bool
+}
+
+// AuditSink receives an AuditRecord for every mutating call, after it
+// completes. It is called synchronously, so slow sinks slow the client down.
+type AuditSink interface {
+	Audit(record AuditRecord)
+}
+
+type AuditSinkFunc func(record AuditRecord)
+
+func (f AuditSinkFunc) Audit(record AuditRecord) {
+	f(record)
+}
+
+func (api *API) audit(ctx context.Context, method, requestUrl string, digest []byte, resp *Response, err error) {
+	session, _ := api.currentSession()
+	record := AuditRecord{
+		Time:          api.now(),
+		UserID:        session.UserID,
+		SiteID:        session.SiteID,
+		Method:        method,
+		URL:           requestUrl,
+		PayloadSHA256: hex.EncodeToString(digest),
+		DryRun:        api.DryRun != nil,
+	}
+	if resp != nil {
+		record.StatusCode = resp.StatusCode
+		if err == nil {
+			err = resp.Err()
+		}
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if sink, ok := api.Auditor.(ContextAuditSink); ok {
+		sink.AuditContext(ctx, record)
+		return
+	}
+	api.Auditor.Audit(record)
+}
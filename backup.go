@@ -0,0 +1,372 @@
+package tableau4go
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// name of the manifest BackupSite writes next to the downloaded content
+const backup_manifest = "manifest.json"
+
+// BackupOptions control BackupSite. Concurrency is how many downloads run at
+// once, 4 when 0. With Resume set, content an earlier, interrupted backup
+// already downloaded into the directory is kept instead of downloaded again.
+type BackupOptions struct {
+	IncludeExtracts bool
+	Concurrency     int
+	Resume          bool
+}
+
+type BackupProject struct {
+	Project     Project      `json:"project"`
+	Path        string       `json:"path"`
+	Permissions *Permissions `json:"permissions,omitempty"`
+}
+
+// BackupWorkbook, BackupDatasource and BackupFlow record a downloaded item.
+// File is relative to the backup directory and empty if the download failed.
+type BackupWorkbook struct {
+	Workbook    Workbook     `json:"workbook"`
+	ProjectPath string       `json:"projectPath"`
+	File        string       `json:"file,omitempty"`
+	Permissions *Permissions `json:"permissions,omitempty"`
+}
+
+type BackupDatasource struct {
+	Datasource  Datasource   `json:"datasource"`
+	ProjectPath string       `json:"projectPath"`
+	File        string       `json:"file,omitempty"`
+	Permissions *Permissions `json:"permissions,omitempty"`
+}
+
+type BackupFlow struct {
+	Flow        Flow   `json:"flow"`
+	ProjectPath string `json:"projectPath"`
+	File        string `json:"file,omitempty"`
+}
+
+// BackupManifest describes a backup made by BackupSite. Users and schedules
+// are recorded for reference; RestoreSite does not recreate them.
+type BackupManifest struct {
+	SiteID      SiteID             `json:"siteId"`
+	CreatedAt   string             `json:"createdAt"`
+	Projects    []BackupProject    `json:"projects"`
+	Workbooks   []BackupWorkbook   `json:"workbooks"`
+	Datasources []BackupDatasource `json:"datasources"`
+	Flows       []BackupFlow       `json:"flows"`
+	Users       []User             `json:"users"`
+	Schedules   []Schedule         `json:"schedules"`
+}
+
+// BackupSite downloads every workbook, datasource and flow on the site into
+// dir, laid out as dir/<type>/<id>/<file name>, and writes dir/manifest.json
+// describing the projects, permissions, users and schedules alongside them.
+//
+// Items that can't be backed up don't stop the rest: the manifest is still
+// written, without a File for them, and the failures are returned as a
+// *MultiError keyed by "<type>:<id>". Running it again with Resume set only
+// downloads what is missing. Schedules are server wide and need a server
+// administrator; if they can't be listed the failure is keyed "schedules",
+// and likewise "users" for users.
+func (api *API) BackupSite(siteId SiteID, dir string, opts BackupOptions) (BackupManifest, error) {
+	manifest := BackupManifest{SiteID: siteId, CreatedAt: api.now().UTC().Format(time.RFC3339)}
+	result := &MultiError{}
+	var mu sync.Mutex
+	record := func(item string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.record(item, err)
+	}
+
+	projects, err := api.queryAllProjects(siteId, ListOptions{})
+	if err != nil {
+		return manifest, err
+	}
+	paths := projectPaths(projects)
+	projectPath := func(project *Project) string {
+		if project == nil {
+			return ""
+		}
+		return paths[project.ID]
+	}
+	for _, project := range projects {
+		backup := BackupProject{Project: project, Path: paths[project.ID]}
+		permissions, err := api.QueryProjectPermissions(siteId, project.ID)
+		record(CONTENT_TYPE_PROJECT+":"+string(project.ID), err)
+		if err == nil {
+			backup.Permissions = &permissions
+		}
+		manifest.Projects = append(manifest.Projects, backup)
+	}
+
+	workbooks, err := api.queryAllWorkbooks(siteId, ListOptions{})
+	if err != nil {
+		return manifest, err
+	}
+	datasources, err := api.queryAllDatasources(siteId, ListOptions{})
+	if err != nil {
+		return manifest, err
+	}
+	flows := []Flow{}
+	if api.Supports("QueryFlows") {
+		if flows, err = api.queryAllFlows(siteId, ListOptions{}); err != nil {
+			return manifest, err
+		}
+	}
+	manifest.Workbooks = make([]BackupWorkbook, len(workbooks))
+	manifest.Datasources = make([]BackupDatasource, len(datasources))
+	manifest.Flows = make([]BackupFlow, len(flows))
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	// each download writes only its own slot in the manifest
+	run := func(task func()) {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			task()
+		}()
+	}
+	fetch := func(contentType, id string, download func(*os.File) (string, error)) (string, error) {
+		path := ""
+		if opts.Resume {
+			path = archived(dir, contentType, id)
+		}
+		if len(path) == 0 {
+			var err error
+			if path, err = archive(dir, contentType, id, download); err != nil {
+				return "", err
+			}
+		}
+		return filepath.Rel(dir, path)
+	}
+	for i, workbook := range workbooks {
+		i, workbook := i, workbook
+		run(func() {
+			key := CONTENT_TYPE_WORKBOOK + ":" + string(workbook.ID)
+			backup := BackupWorkbook{Workbook: workbook, ProjectPath: projectPath(workbook.Project)}
+			file, err := fetch(CONTENT_TYPE_WORKBOOK, string(workbook.ID), func(f *os.File) (string, error) {
+				return api.DownloadWorkbook(siteId, workbook.ID, opts.IncludeExtracts, f)
+			})
+			if err == nil {
+				backup.File = file
+				var permissions Permissions
+				if permissions, err = api.QueryWorkbookPermissions(siteId, workbook.ID); err == nil {
+					backup.Permissions = &permissions
+				}
+			}
+			manifest.Workbooks[i] = backup
+			record(key, err)
+		})
+	}
+	for i, datasource := range datasources {
+		i, datasource := i, datasource
+		run(func() {
+			key := CONTENT_TYPE_DATASOURCE + ":" + string(datasource.ID)
+			backup := BackupDatasource{Datasource: datasource, ProjectPath: projectPath(datasource.Project)}
+			file, err := fetch(CONTENT_TYPE_DATASOURCE, string(datasource.ID), func(f *os.File) (string, error) {
+				return api.DownloadDatasource(siteId, datasource.ID, opts.IncludeExtracts, f)
+			})
+			if err == nil {
+				backup.File = file
+				var permissions Permissions
+				if permissions, err = api.QueryDatasourcePermissions(siteId, datasource.ID); err == nil {
+					backup.Permissions = &permissions
+				}
+			}
+			manifest.Datasources[i] = backup
+			record(key, err)
+		})
+	}
+	for i, flow := range flows {
+		i, flow := i, flow
+		run(func() {
+			backup := BackupFlow{Flow: flow, ProjectPath: projectPath(flow.Project)}
+			file, err := fetch(CONTENT_TYPE_FLOW, string(flow.ID), func(f *os.File) (string, error) {
+				return api.DownloadFlow(siteId, flow.ID, f)
+			})
+			backup.File = file
+			manifest.Flows[i] = backup
+			record(CONTENT_TYPE_FLOW+":"+string(flow.ID), err)
+		})
+	}
+	wg.Wait()
+
+	users, err := api.queryAllUsers(siteId, ListOptions{})
+	manifest.Users = append(manifest.Users, users...)
+	result.record("users", err)
+	schedules, err := api.FindSchedules(ScheduleFilter{})
+	manifest.Schedules = schedules
+	result.record("schedules", err)
+
+	if err := writeManifest(dir, manifest); err != nil {
+		return manifest, err
+	}
+	return manifest, result.err()
+}
+
+func writeManifest(dir string, manifest BackupManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, download_prefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, backup_manifest))
+}
+
+// ReadBackupManifest reads the manifest of a backup made by BackupSite.
+func ReadBackupManifest(dir string) (BackupManifest, error) {
+	manifest := BackupManifest{}
+	data, err := os.ReadFile(filepath.Join(dir, backup_manifest))
+	if err != nil {
+		return manifest, err
+	}
+	err = json.Unmarshal(data, &manifest)
+	return manifest, err
+}
+
+// RestoreOptions control RestoreSite. Overwrite replaces content that already
+// exists on the site. Permissions re-applies the backed up permissions, which
+// only makes sense when restoring to the site the backup was taken from,
+// since they name users and groups by id.
+type RestoreOptions struct {
+	Overwrite   bool
+	Permissions bool
+	Upload      UploadOptions
+}
+
+// RestoreSite publishes a backup made by BackupSite to the site. Projects are
+// matched by path and created where missing, then datasources are published
+// before the workbooks that may use them. Flows, users and schedules are not
+// restored. Items that fail don't stop the rest; the failures are returned as
+// a *MultiError keyed by "<type>:<path>".
+func (api *API) RestoreSite(siteId SiteID, dir string, opts RestoreOptions) error {
+	manifest, err := ReadBackupManifest(dir)
+	if err != nil {
+		return err
+	}
+	existing, err := api.queryAllProjects(siteId, ListOptions{})
+	if err != nil {
+		return err
+	}
+	projectIds := map[string]ProjectID{}
+	for id, path := range projectPaths(existing) {
+		projectIds[path] = id
+	}
+	result := &MultiError{}
+
+	// parents before children
+	projects := append([]BackupProject{}, manifest.Projects...)
+	sort.SliceStable(projects, func(i, j int) bool {
+		return strings.Count(projects[i].Path, "/") < strings.Count(projects[j].Path, "/")
+	})
+	for _, backup := range projects {
+		key := CONTENT_TYPE_PROJECT + ":" + backup.Path
+		id, ok := projectIds[backup.Path]
+		if !ok {
+			project := Project{Name: backup.Project.Name, Description: backup.Project.Description, ContentPermissions: backup.Project.ContentPermissions}
+			if parent := parentPath(backup.Path); len(parent) > 0 {
+				if project.ParentProjectID, ok = projectIds[parent]; !ok {
+					result.fail(key, ErrDoesNotExist)
+					continue
+				}
+			}
+			created, err := api.CreateProject(siteId, project)
+			if err != nil {
+				result.fail(key, err)
+				continue
+			}
+			id = created.ID
+			projectIds[backup.Path] = id
+		}
+		var permissionsErr error
+		if opts.Permissions && backup.Permissions != nil && len(backup.Permissions.GranteeCapabilities) > 0 {
+			_, permissionsErr = api.AddProjectPermissions(siteId, id, backup.Permissions.GranteeCapabilities)
+		}
+		result.record(key, permissionsErr)
+	}
+
+	for _, backup := range manifest.Datasources {
+		key := CONTENT_TYPE_DATASOURCE + ":" + backup.ProjectPath + "/" + backup.Datasource.Name
+		if len(backup.File) == 0 {
+			continue
+		}
+		projectId, ok := projectIds[backup.ProjectPath]
+		if !ok {
+			result.fail(key, ErrDoesNotExist)
+			continue
+		}
+		session, err := api.UploadFile(siteId, filepath.Join(dir, backup.File), opts.Upload)
+		if err != nil {
+			result.fail(key, err)
+			continue
+		}
+		metadata := Datasource{Name: backup.Datasource.Name, Description: backup.Datasource.Description, Project: &Project{ID: projectId}}
+		datasource, err := api.PublishDatasourceFromUpload(siteId, metadata, session.UploadSessionID, fileType(backup.File), opts.Overwrite)
+		if err == nil && opts.Permissions && backup.Permissions != nil && len(backup.Permissions.GranteeCapabilities) > 0 {
+			_, err = api.AddDatasourcePermissions(siteId, datasource.ID, backup.Permissions.GranteeCapabilities)
+		}
+		result.record(key, err)
+	}
+
+	for _, backup := range manifest.Workbooks {
+		key := CONTENT_TYPE_WORKBOOK + ":" + backup.ProjectPath + "/" + backup.Workbook.Name
+		if len(backup.File) == 0 {
+			continue
+		}
+		projectId, ok := projectIds[backup.ProjectPath]
+		if !ok {
+			result.fail(key, ErrDoesNotExist)
+			continue
+		}
+		session, err := api.UploadFile(siteId, filepath.Join(dir, backup.File), opts.Upload)
+		if err != nil {
+			result.fail(key, err)
+			continue
+		}
+		metadata := Workbook{Name: backup.Workbook.Name, ShowTabs: backup.Workbook.ShowTabs, Project: &Project{ID: projectId}}
+		workbook, err := api.PublishWorkbookFromUpload(siteId, metadata, session.UploadSessionID, fileType(backup.File), opts.Overwrite)
+		if err == nil && opts.Permissions && backup.Permissions != nil && len(backup.Permissions.GranteeCapabilities) > 0 {
+			_, err = api.AddWorkbookPermissions(siteId, workbook.ID, backup.Permissions.GranteeCapabilities)
+		}
+		result.record(key, err)
+	}
+	return result.err()
+}
+
+func parentPath(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// fileType returns the extension publishing expects, e.g. "twbx"
+func fileType(path string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+}
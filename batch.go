@@ -0,0 +1,108 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ItemError is the failure of one item in a bulk operation. Item identifies
+// it the way the operation was asked for it, usually by LUID.
+type ItemError struct {
+	Item string
+	Err  error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Item, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the Tableau error code of the failure, or "" if it was not
+// reported by the server.
+func (e *ItemError) Code() string {
+	var tErr Terror
+	if errors.As(e.Err, &tErr) {
+		return tErr.Code
+	}
+	return ""
+}
+
+// MultiError is returned by bulk helpers when some items failed. It records
+// which items succeeded and why each of the others failed, so a caller can
+// retry only the failed subset. Extract it with errors.As; errors.Is and
+// errors.As also see through it to the individual failures.
+type MultiError struct {
+	Succeeded []string
+	Failed    []*ItemError
+}
+
+func (e *MultiError) Error() string {
+	total := len(e.Succeeded) + len(e.Failed)
+	messages := make([]string, 0, len(e.Failed))
+	for _, failure := range e.Failed {
+		messages = append(messages, failure.Error())
+	}
+	return fmt.Sprintf("%d Of %d Items Failed: %s", len(e.Failed), total, strings.Join(messages, "; "))
+}
+
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, failure := range e.Failed {
+		errs = append(errs, failure)
+	}
+	return errs
+}
+
+// FailedItems returns the items that failed, in the order they were tried.
+func (e *MultiError) FailedItems() []string {
+	items := make([]string, 0, len(e.Failed))
+	for _, failure := range e.Failed {
+		items = append(items, failure.Item)
+	}
+	return items
+}
+
+func (e *MultiError) succeed(item string) {
+	e.Succeeded = append(e.Succeeded, item)
+}
+
+func (e *MultiError) fail(item string, err error) {
+	e.Failed = append(e.Failed, &ItemError{Item: item, Err: err})
+}
+
+// record notes the outcome of one item
+func (e *MultiError) record(item string, err error) {
+	if err != nil {
+		e.fail(item, err)
+	} else {
+		e.succeed(item)
+	}
+}
+
+// merge folds the per-item results of another bulk call into e, reporting
+// false if err is some other, fatal error
+func (e *MultiError) merge(err error) bool {
+	if err == nil {
+		return true
+	}
+	var other *MultiError
+	if !errors.As(err, &other) {
+		return false
+	}
+	e.Succeeded = append(e.Succeeded, other.Succeeded...)
+	e.Failed = append(e.Failed, other.Failed...)
+	return true
+}
+
+// err returns e if any item failed and nil otherwise, so bulk helpers can
+// always build one and return it
+func (e *MultiError) err() error {
+	if e == nil || len(e.Failed) == 0 {
+		return nil
+	}
+	return e
+}
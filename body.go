@@ -0,0 +1,102 @@
+package tableau4go
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// sizedReader is a request body whose length is known without reading it,
+// such as a file, so it can be sent with a Content-Length rather than
+// chunked.
+type sizedReader struct {
+	io.Reader
+	size int64
+}
+
+func (r sizedReader) Size() int64 {
+	return r.size
+}
+
+// bodyLength returns how many bytes body holds, or -1 if that can't be told
+// without reading it.
+func bodyLength(body io.Reader) int64 {
+	switch b := body.(type) {
+	case nil:
+		return 0
+	case interface{ Size() int64 }:
+		return b.Size()
+	case interface{ Len() int }:
+		return int64(b.Len())
+	}
+	return -1
+}
+
+// multipartBody sends its parts one after the other through an
+// io.MultiReader, so published content isn't copied into one payload with
+// the multipart headers. Its length is known up front, and seeking back to
+// the start rebuilds the reader so the body can be sent again.
+type multipartBody struct {
+	parts  [][]byte
+	size   int64
+	read   int64
+	reader io.Reader
+}
+
+func newMultipartBody(parts ...[]byte) *multipartBody {
+	body := &multipartBody{parts: parts}
+	for _, part := range parts {
+		body.size += int64(len(part))
+	}
+	body.Seek(0, io.SeekStart)
+	return body
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+func (b *multipartBody) Size() int64 {
+	return b.size
+}
+
+// Seek reports the current offset or goes back to the start; other moves
+// aren't needed to resend a request and aren't supported.
+func (b *multipartBody) Seek(offset int64, whence int) (int64, error) {
+	switch {
+	case offset == 0 && whence == io.SeekCurrent:
+		return b.read, nil
+	case offset == 0 && whence == io.SeekStart:
+		readers := make([]io.Reader, len(b.parts))
+		for i, part := range b.parts {
+			readers[i] = bytes.NewReader(part)
+		}
+		b.reader = io.MultiReader(readers...)
+		b.read = 0
+		return 0, nil
+	}
+	return b.read, errors.New("multipartBody: can only seek to the start")
+}
+
+// rewinder returns a function that moves body back to where it is now, so
+// it can be sent again after signing in again, or nil if body can't be read
+// twice.
+func rewinder(body io.Reader) func() error {
+	if body == nil {
+		return func() error { return nil }
+	}
+	seeker, ok := body.(io.Seeker)
+	if !ok {
+		return nil
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+	return func() error {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+}
@@ -0,0 +1,40 @@
+package tableau4go
+
+import (
+	"io"
+	"testing"
+)
+
+func TestMultipartBodyRewinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    [][]byte
+		expected string
+	}{
+		{"header content and trailer", [][]byte{[]byte("--b\r\n"), []byte("content"), []byte("\r\n--b--\r\n")}, "--b\r\ncontent\r\n--b--\r\n"},
+		{"empty content", [][]byte{[]byte("head"), nil, []byte("tail")}, "headtail"},
+		{"no parts", nil, ""},
+	}
+	for _, test := range tests {
+		body := newMultipartBody(test.parts...)
+		if length := bodyLength(body); length != int64(len(test.expected)) {
+			t.Errorf("%s: got length %d, expected %d", test.name, length, len(test.expected))
+		}
+		rewind := rewinder(body)
+		if rewind == nil {
+			t.Fatalf("%s: body can't be rewound", test.name)
+		}
+		for attempt := 1; attempt <= 2; attempt++ {
+			sent, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			if string(sent) != test.expected {
+				t.Errorf("%s attempt %d: sent %q, expected %q", test.name, attempt, sent, test.expected)
+			}
+			if err := rewind(); err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("Circuit Open: Server Unavailable")
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "Closed"
+	CircuitOpen     CircuitState = "Open"
+	CircuitHalfOpen CircuitState = "HalfOpen"
+)
+
+// CircuitBreaker makes calls fail fast with ErrCircuitOpen while the server
+// is down, instead of each one waiting out its timeouts. Set it on
+// API.Breaker; it is shared by every copy of the client.
+//
+// The circuit opens after Failures consecutive failed requests, 5 by
+// default, where a failure is a request that got no response or a 5xx one;
+// requests abandoned by their caller's context don't count. Once it has been
+// open for Cooldown, 30 seconds by default, the next call probes the server
+// with ServerInfo: if the server answers, the circuit closes and the call
+// goes ahead, otherwise it stays open for another Cooldown. Calls made while
+// the probe runs fail fast. OnStateChange, if set, is called on each change.
+type CircuitBreaker struct {
+	Failures      int
+	Cooldown      time.Duration
+	OnStateChange func(from, to CircuitState)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+// State returns whether the circuit is closed, open or probing.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.state) == 0 {
+		return CircuitClosed
+	}
+	return b.state
+}
+
+// setState changes state and returns the change to report; b.mu must be held.
+func (b *CircuitBreaker) setState(state CircuitState) func() {
+	from := b.state
+	if len(from) == 0 {
+		from = CircuitClosed
+	}
+	b.state = state
+	if from == state || b.OnStateChange == nil {
+		return func() {}
+	}
+	return func() { b.OnStateChange(from, state) }
+}
+
+func (b *CircuitBreaker) threshold() int {
+	if b.Failures <= 0 {
+		return 5
+	}
+	return b.Failures
+}
+
+func (b *CircuitBreaker) cooldown() time.Duration {
+	if b.Cooldown <= 0 {
+		return 30 * time.Second
+	}
+	return b.Cooldown
+}
+
+// admit reports whether a call may go ahead, and whether it has to probe the
+// server first.
+func (b *CircuitBreaker) admit(now time.Time) (bool, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < b.cooldown() {
+			return false, false
+		}
+		defer b.setState(CircuitHalfOpen)()
+		return true, true
+	case CircuitHalfOpen:
+		return false, false
+	}
+	return true, false
+}
+
+// record counts the outcome of a request or probe.
+func (b *CircuitBreaker) record(failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		b.failures = 0
+		defer b.setState(CircuitClosed)()
+		return
+	}
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold() {
+		b.openedAt = now
+		defer b.setState(CircuitOpen)()
+	}
+}
+
+// abandonProbe reopens the circuit without restarting its cooldown, so the
+// next call probes again.
+func (b *CircuitBreaker) abandonProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	defer b.setState(CircuitOpen)()
+}
+
+// send is transmit guarded by api.Breaker, when it is set.
+func (api *API) send(ctx context.Context, method string, requestUrl string, body io.Reader, headers map[string]string) (*Response, error) {
+	breaker := api.Breaker
+	if breaker == nil {
+		return api.transmit(ctx, method, requestUrl, body, headers)
+	}
+	allowed, probe := breaker.admit(api.now())
+	if !allowed {
+		return nil, ErrCircuitOpen
+	}
+	if probe {
+		resp, err := api.transmit(ctx, GET, api.serverInfoURL(), nil, map[string]string{})
+		if ctx.Err() != nil {
+			// the caller gave up; leave the probe to the next call
+			breaker.abandonProbe()
+			return nil, ctx.Err()
+		}
+		failed := err != nil || resp.StatusCode >= 500
+		breaker.record(failed, api.now())
+		if failed {
+			return nil, ErrCircuitOpen
+		}
+	}
+	resp, err := api.transmit(ctx, method, requestUrl, body, headers)
+	if failed, counts := requestOutcome(ctx, resp, err); counts {
+		breaker.record(failed, api.now())
+	}
+	return resp, err
+}
+
+// requestOutcome reports whether the server failed the request: it didn't
+// answer, or answered with a 5xx. Requests abandoned by the caller and errors
+// from before the request was sent, such as a body over the limit, say
+// nothing about the server and don't count.
+func requestOutcome(ctx context.Context, resp *Response, err error) (failed bool, counts bool) {
+	if err != nil {
+		var urlErr *url.Error
+		return true, errors.As(err, &urlErr) && ctx.Err() == nil
+	}
+	return resp.StatusCode >= 500, true
+}
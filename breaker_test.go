@@ -0,0 +1,157 @@
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	type step struct {
+		// how long passes before the call
+		advance time.Duration
+		// what the server answers, in order, to the probe and the call; a
+		// call that fails fast must not reach the server at all
+		statuses []int
+		err      error
+		state    CircuitState
+	}
+	tests := []struct {
+		name    string
+		breaker *CircuitBreaker
+		steps   []step
+		changes [][2]CircuitState
+	}{
+		{
+			name:    "opens after consecutive failures and fails fast",
+			breaker: &CircuitBreaker{Failures: 2},
+			steps: []step{
+				{statuses: []int{500}, state: CircuitClosed},
+				{statuses: []int{502}, state: CircuitOpen},
+				{err: ErrCircuitOpen, state: CircuitOpen},
+			},
+			changes: [][2]CircuitState{{CircuitClosed, CircuitOpen}},
+		},
+		{
+			name:    "a success resets the count",
+			breaker: &CircuitBreaker{Failures: 2},
+			steps: []step{
+				{statuses: []int{500}, state: CircuitClosed},
+				{statuses: []int{200}, state: CircuitClosed},
+				{statuses: []int{500}, state: CircuitClosed},
+			},
+		},
+		{
+			name:    "client errors don't count",
+			breaker: &CircuitBreaker{Failures: 1},
+			steps: []step{
+				{statuses: []int{404}, state: CircuitClosed},
+				{statuses: []int{401}, state: CircuitClosed},
+			},
+		},
+		{
+			name:    "a probe after the cooldown closes the circuit",
+			breaker: &CircuitBreaker{Failures: 1, Cooldown: 30 * time.Second},
+			steps: []step{
+				{statuses: []int{503}, state: CircuitOpen},
+				{advance: 29 * time.Second, err: ErrCircuitOpen, state: CircuitOpen},
+				{advance: time.Second, statuses: []int{200, 200}, state: CircuitClosed},
+			},
+			changes: [][2]CircuitState{{CircuitClosed, CircuitOpen}, {CircuitOpen, CircuitHalfOpen}, {CircuitHalfOpen, CircuitClosed}},
+		},
+		{
+			name:    "a failed probe restarts the cooldown",
+			breaker: &CircuitBreaker{Failures: 1, Cooldown: 30 * time.Second},
+			steps: []step{
+				{statuses: []int{500}, state: CircuitOpen},
+				{advance: 30 * time.Second, statuses: []int{503}, err: ErrCircuitOpen, state: CircuitOpen},
+				{advance: 29 * time.Second, err: ErrCircuitOpen, state: CircuitOpen},
+				{advance: time.Second, statuses: []int{200, 200}, state: CircuitClosed},
+			},
+			changes: [][2]CircuitState{
+				{CircuitClosed, CircuitOpen}, {CircuitOpen, CircuitHalfOpen}, {CircuitHalfOpen, CircuitOpen},
+				{CircuitOpen, CircuitHalfOpen}, {CircuitHalfOpen, CircuitClosed},
+			},
+		},
+		{
+			name:    "default threshold is five",
+			breaker: &CircuitBreaker{},
+			steps: []step{
+				{statuses: []int{500}, state: CircuitClosed},
+				{statuses: []int{500}, state: CircuitClosed},
+				{statuses: []int{500}, state: CircuitClosed},
+				{statuses: []int{500}, state: CircuitClosed},
+				{statuses: []int{500}, state: CircuitOpen},
+			},
+			changes: [][2]CircuitState{{CircuitClosed, CircuitOpen}},
+		},
+	}
+	for _, test := range tests {
+		var mu sync.Mutex
+		var statuses []int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(statuses) == 0 {
+				t.Errorf("%s: unexpected request to %s", test.name, r.URL.Path)
+				w.WriteHeader(http.StatusTeapot)
+				return
+			}
+			w.WriteHeader(statuses[0])
+			statuses = statuses[1:]
+		}))
+		changes := [][2]CircuitState{}
+		test.breaker.OnStateChange = func(from, to CircuitState) {
+			changes = append(changes, [2]CircuitState{from, to})
+		}
+		api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+		clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+		api.Clock = clock
+		api.Breaker = test.breaker
+		for i, step := range test.steps {
+			clock.advance(step.advance)
+			mu.Lock()
+			statuses = step.statuses
+			mu.Unlock()
+			_, err := api.send(context.Background(), GET, server.URL+"/api/"+API_VERSION+"/sites", nil, map[string]string{})
+			if !errors.Is(err, step.err) {
+				t.Errorf("%s step %d: got error %v, expected %v", test.name, i, err, step.err)
+			}
+			if state := test.breaker.State(); state != step.state {
+				t.Errorf("%s step %d: got state %s, expected %s", test.name, i, state, step.state)
+			}
+			mu.Lock()
+			if len(statuses) > 0 {
+				t.Errorf("%s step %d: %d answers not asked for", test.name, i, len(statuses))
+			}
+			mu.Unlock()
+		}
+		server.Close()
+		if test.changes == nil {
+			test.changes = [][2]CircuitState{}
+		}
+		if !reflect.DeepEqual(changes, test.changes) {
+			t.Errorf("%s: got changes %v, expected %v", test.name, changes, test.changes)
+		}
+	}
+}
+
+func TestCircuitBreakerIgnoresAbandonedCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+	api.Breaker = &CircuitBreaker{Failures: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := api.send(ctx, GET, server.URL+"/api/"+API_VERSION+"/sites", nil, map[string]string{}); err == nil {
+		t.Fatal("expected the cancelled call to fail")
+	}
+	if state := api.Breaker.State(); state != CircuitClosed {
+		t.Errorf("got state %s, expected the abandoned call not to count", state)
+	}
+}
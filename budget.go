@@ -0,0 +1,134 @@
+package tableau4go
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UsageBudget counts the requests sent for each site over a rolling window,
+// so a platform shared by several teams can see and cap how much of Tableau
+// Cloud's API allowance each one's site uses. Set it on API.Budget; it is
+// shared by every copy of the client, and may be shared between clients.
+//
+// Limits caps the requests per site in any Window, one hour by default. The
+// "" entry applies to sites without their own; sites without a limit are only
+// counted. A call that would go over its site's limit waits until the oldest
+// request counted leaves the window, or until its context is done. OnWait,
+// if set, is called as each wait begins.
+//
+// Requests are counted against the site in their URL, or the signed in site
+// for those without one, such as sign in itself. Dry runs send nothing and
+// count nothing.
+type UsageBudget struct {
+	Window time.Duration
+	Limits map[SiteID]int
+	OnWait func(siteId SiteID, wait time.Duration)
+
+	mu   sync.Mutex
+	sent map[SiteID][]time.Time
+}
+
+// Usage returns how many requests each site has sent in the window ending
+// now.
+func (b *UsageBudget) Usage(now time.Time) map[SiteID]int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usage := map[SiteID]int{}
+	for siteId := range b.sent {
+		if n := len(b.prune(siteId, now)); n > 0 {
+			usage[siteId] = n
+		}
+	}
+	return usage
+}
+
+func (b *UsageBudget) window() time.Duration {
+	if b.Window <= 0 {
+		return time.Hour
+	}
+	return b.Window
+}
+
+func (b *UsageBudget) limit(siteId SiteID) int {
+	if limit, ok := b.Limits[siteId]; ok {
+		return limit
+	}
+	return b.Limits[""]
+}
+
+// prune drops the site's requests that have left the window; b.mu must be
+// held.
+func (b *UsageBudget) prune(siteId SiteID, now time.Time) []time.Time {
+	sent := b.sent[siteId]
+	cutoff := now.Add(-b.window())
+	i := 0
+	for i < len(sent) && !sent[i].After(cutoff) {
+		i++
+	}
+	sent = sent[i:]
+	if len(sent) == 0 {
+		delete(b.sent, siteId)
+	} else {
+		b.sent[siteId] = sent
+	}
+	return sent
+}
+
+// reserve counts a request for the site if it is within its limit; otherwise
+// it returns how long until it will be.
+func (b *UsageBudget) reserve(siteId SiteID, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.sent == nil {
+		b.sent = map[SiteID][]time.Time{}
+	}
+	sent := b.prune(siteId, now)
+	if limit := b.limit(siteId); limit > 0 && len(sent) >= limit {
+		return sent[0].Add(b.window()).Sub(now)
+	}
+	b.sent[siteId] = append(sent, now)
+	return 0
+}
+
+// spend waits, if need be, for room in the budget of the site requestUrl is
+// for, then counts the request.
+func (api *API) spend(ctx context.Context, requestUrl string) error {
+	budget := api.Budget
+	if budget == nil {
+		return nil
+	}
+	siteId := api.requestSite(requestUrl)
+	for {
+		wait := budget.reserve(siteId, api.now())
+		if wait <= 0 {
+			return nil
+		}
+		if budget.OnWait != nil {
+			budget.OnWait(siteId, wait)
+		}
+		if err := api.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// requestSite returns the site ID in requestUrl, or the signed in site for
+// URLs without one and for lookups of a site by name or content URL.
+func (api *API) requestSite(requestUrl string) SiteID {
+	parsed, err := url.Parse(requestUrl)
+	if err != nil || len(parsed.Query().Get("key")) > 0 {
+		return api.CurrentSite().ID
+	}
+	_, rest, found := strings.Cut(parsed.Path, "/sites/")
+	if !found {
+		return api.CurrentSite().ID
+	}
+	siteId, _, _ := strings.Cut(rest, "/")
+	if len(siteId) == 0 {
+		return api.CurrentSite().ID
+	}
+	return SiteID(siteId)
+}
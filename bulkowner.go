@@ -0,0 +1,120 @@
+package tableau4go
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrEmptyContentFilter = errors.New("Content Filter Matches All Content")
+
+// how many updates BulkReassignOwner sends at once
+const bulk_owner_concurrency = 8
+
+// ContentFilter selects workbooks and datasources. Types holds
+// CONTENT_TYPE_WORKBOOK and CONTENT_TYPE_DATASOURCE, both when empty.
+// Filter is a server side filter expression such as
+// "ownerName:eq:jsmith,tags:in:[finance]" applied to both types; ProjectID
+// and OwnerID are checked on the results, as project and owner can only be
+// filtered on by name.
+type ContentFilter struct {
+	Types     []string
+	Filter    string
+	ProjectID ProjectID
+	OwnerID   UserID
+}
+
+// empty reports whether the filter selects every item of its types
+func (f ContentFilter) empty() bool {
+	return len(f.Filter) == 0 && len(f.ProjectID) == 0 && len(f.OwnerID) == 0
+}
+
+func (f ContentFilter) includes(contentType string) bool {
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+func (f ContentFilter) matches(project *Project, owner *User) bool {
+	if len(f.ProjectID) > 0 && (project == nil || project.ID != f.ProjectID) {
+		return false
+	}
+	if len(f.OwnerID) > 0 && (owner == nil || owner.ID != f.OwnerID) {
+		return false
+	}
+	return true
+}
+
+// FindContent returns the workbooks and datasources matching filter.
+func (api *API) FindContent(siteId SiteID, filter ContentFilter) ([]OwnedContent, error) {
+	opts := ListOptions{Filter: filter.Filter}
+	found := []OwnedContent{}
+	if filter.includes(CONTENT_TYPE_WORKBOOK) {
+		workbooks, err := api.queryAllWorkbooks(siteId, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, wb := range workbooks {
+			if filter.matches(wb.Project, wb.Owner) {
+				found = append(found, OwnedContent{Type: CONTENT_TYPE_WORKBOOK, ID: string(wb.ID), Name: wb.Name})
+			}
+		}
+	}
+	if filter.includes(CONTENT_TYPE_DATASOURCE) {
+		datasources, err := api.queryAllDatasources(siteId, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, ds := range datasources {
+			if filter.matches(ds.Project, ds.Owner) {
+				found = append(found, OwnedContent{Type: CONTENT_TYPE_DATASOURCE, ID: string(ds.ID), Name: ds.Name})
+			}
+		}
+	}
+	return found, nil
+}
+
+// BulkReassignOwner makes newOwnerId the owner of every workbook and
+// datasource matching filter, sending several updates at once. It returns
+// the content it matched; items that couldn't be reassigned are returned as
+// a *MultiError keyed by "<type>:<id>". A filter without a Filter,
+// ProjectID or OwnerID would reassign everything on the site and is refused
+// with ErrEmptyContentFilter.
+func (api *API) BulkReassignOwner(siteId SiteID, filter ContentFilter, newOwnerId UserID) ([]OwnedContent, error) {
+	if filter.empty() {
+		return nil, ErrEmptyContentFilter
+	}
+	matched, err := api.FindContent(siteId, filter)
+	if err != nil {
+		return nil, err
+	}
+	owner := &User{ID: newOwnerId}
+	var mu sync.Mutex
+	result := &MultiError{}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, bulk_owner_concurrency)
+	for _, content := range matched {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(content OwnedContent) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			var err error
+			if content.Type == CONTENT_TYPE_WORKBOOK {
+				_, err = api.UpdateWorkbook(siteId, Workbook{ID: WorkbookID(content.ID), Owner: owner})
+			} else {
+				_, err = api.UpdateDatasource(siteId, Datasource{ID: DatasourceID(content.ID), Owner: owner})
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			result.record(content.key(), err)
+		}(content)
+	}
+	wg.Wait()
+	return matched, result.err()
+}
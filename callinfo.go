@@ -0,0 +1,71 @@
+package tableau4go
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CallInfo describes the last HTTP request a call made. Calls that retry
+// after signing in again report the retry.
+type CallInfo struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// RequestID is the id the server or a gateway in front of it assigned
+	// the request, when it returns one; quote it when raising a support case
+	RequestID string
+	Duration  time.Duration
+	// RateLimit holds the Retry-After and X-RateLimit-* response headers
+	RateLimit http.Header
+	BodySize  int
+}
+
+type callInfoKey struct{}
+
+// response headers that carry a request id, in order of preference
+var requestIdHeaders = []string{"X-Tableau-Request-Id", "X-Request-Id", "X-Amzn-Trace-Id"}
+
+// WithCallInfo returns a context that has calls made with it fill in info.
+// Pass it to WithContext or Do:
+//
+//	var info tableau4go.CallInfo
+//	_, err := api.WithContext(tableau4go.WithCallInfo(ctx, &info)).QueryWorkbook(siteId, id)
+//
+// info is overwritten by each call, so use a context per call when calls run
+// concurrently.
+func WithCallInfo(ctx context.Context, info *CallInfo) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, info)
+}
+
+func recordCallInfo(ctx context.Context, info CallInfo) {
+	if target, ok := ctx.Value(callInfoKey{}).(*CallInfo); ok && target != nil {
+		*target = info
+	}
+}
+
+func newCallInfo(method, requestUrl string, duration time.Duration, resp *http.Response, bodySize int) CallInfo {
+	info := CallInfo{
+		Method:     method,
+		URL:        requestUrl,
+		StatusCode: resp.StatusCode,
+		Duration:   duration,
+		BodySize:   bodySize,
+	}
+	for _, header := range requestIdHeaders {
+		if id := resp.Header.Get(header); len(id) > 0 {
+			info.RequestID = id
+			break
+		}
+	}
+	for header, values := range resp.Header {
+		if header == "Retry-After" || strings.HasPrefix(header, "X-Ratelimit-") {
+			if info.RateLimit == nil {
+				info.RateLimit = http.Header{}
+			}
+			info.RateLimit[header] = values
+		}
+	}
+	return info
+}
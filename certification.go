@@ -0,0 +1,70 @@
+package tableau4go
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrEmptyDatasourceFilter = errors.New("Datasource Filter Matches Every Datasource")
+
+// DatasourceFilter selects datasources by project, tag and owner name. The
+// filter is applied by the server; empty fields match everything.
+type DatasourceFilter struct {
+	ProjectName string
+	Tag         string
+	OwnerName   string
+}
+
+func (f DatasourceFilter) expression() string {
+	terms := []string{}
+	if len(f.ProjectName) > 0 {
+		terms = append(terms, "projectName:eq:"+FilterValue(f.ProjectName))
+	}
+	if len(f.Tag) > 0 {
+		terms = append(terms, "tags:eq:"+FilterValue(f.Tag))
+	}
+	if len(f.OwnerName) > 0 {
+		terms = append(terms, "ownerName:eq:"+FilterValue(f.OwnerName))
+	}
+	return strings.Join(terms, ",")
+}
+
+// FindDatasources returns every datasource matching filter.
+func (api *API) FindDatasources(siteId SiteID, filter DatasourceFilter) ([]Datasource, error) {
+	return api.queryAllDatasources(siteId, ListOptions{Filter: filter.expression()})
+}
+
+// CertifyDatasources certifies, or with certified false uncertifies, every
+// datasource matching filter, with note as the certification note. Datasources
+// already in the requested state with the same note are left alone. It
+// returns the datasources it changed. An empty filter is refused with
+// ErrEmptyDatasourceFilter rather than touching the whole site. Failures are
+// returned as a *MultiError keyed by datasource ID.
+func (api *API) CertifyDatasources(siteId SiteID, filter DatasourceFilter, certified bool, note string) ([]Datasource, error) {
+	if filter == (DatasourceFilter{}) {
+		return nil, ErrEmptyDatasourceFilter
+	}
+	datasources, err := api.FindDatasources(siteId, filter)
+	if err != nil {
+		return nil, err
+	}
+	changed := []Datasource{}
+	result := &MultiError{}
+	for _, ds := range datasources {
+		if ds.IsCertified == certified && (!certified || ds.CertificationNote == note) {
+			continue
+		}
+		updated, err := api.CertifyDatasource(siteId, ds.ID, certified, note)
+		result.record(string(ds.ID), err)
+		if err == nil {
+			changed = append(changed, *updated)
+		}
+	}
+	return changed, result.err()
+}
+
+// CertifiedDatasources reports every certified datasource on the site, with
+// its certification note.
+func (api *API) CertifiedDatasources(siteId SiteID) ([]Datasource, error) {
+	return api.queryAllDatasources(siteId, ListOptions{Filter: "isCertified:eq:true"})
+}
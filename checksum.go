@@ -0,0 +1,159 @@
+package tableau4go
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// content published by PublishIfChanged is tagged with its digest under this
+// prefix when no ChecksumStore is used
+const digest_tag_prefix = "t4g-sha256-"
+
+// ContentDigest returns the hex encoded SHA-256 digest of a TDS/TWB payload.
+func ContentDigest(content []byte) string {
+	digest := sha256.Sum256(content)
+	return hex.EncodeToString(digest[:])
+}
+
+// ChecksumStore keeps content digests outside Tableau, for pipelines that
+// don't want digest tags on published content. Load returns "" for keys it
+// has never seen.
+type ChecksumStore interface {
+	Load(key string) (string, error)
+	Save(key string, digest string) error
+}
+
+// FileChecksumStore is a ChecksumStore keeping one sidecar file per key in a
+// directory, suitable for caching between CI runs.
+type FileChecksumStore struct {
+	Dir string
+}
+
+func (s FileChecksumStore) path(key string) string {
+	return filepath.Join(s.Dir, ContentDigest([]byte(key))+".sha256")
+}
+
+func (s FileChecksumStore) Load(key string) (string, error) {
+	digest, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return strings.TrimSpace(string(digest)), err
+}
+
+func (s FileChecksumStore) Save(key string, digest string) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(key), []byte(digest+"\n"), 0644)
+}
+
+// PublishIfChanged publishes setup with PublishWithSetup unless the content is
+// identical to what was published last time, and reports whether it
+// published. The digest of the previous publish is read from store, or, when
+// store is nil, from a digest tag on the existing workbook or datasource;
+// the tag is replaced on every publish. Content is always published with
+// overwrite when it has changed.
+func (api *API) PublishIfChanged(siteId SiteID, setup PublishSetup, store ChecksumStore) (PublishResult, bool, error) {
+	if (setup.Workbook == nil) == (setup.Datasource == nil) {
+		return PublishResult{}, false, ErrNothingToPublish
+	}
+	digest := ContentDigest(setup.Content)
+	name, kind := "", ""
+	if setup.Workbook != nil {
+		name, kind = setup.Workbook.Name, "workbook"
+	} else {
+		name, kind = setup.Datasource.Name, "datasource"
+	}
+	// the parent is only part of the key when set, so keys of top level
+	// projects stay those of earlier releases
+	key := strings.Join([]string{string(siteId), setup.ProjectName, kind, name}, "/")
+	if len(setup.ParentProjectID) > 0 {
+		key = strings.Join([]string{string(siteId), string(setup.ParentProjectID), setup.ProjectName, kind, name}, "/")
+	}
+
+	previous := ""
+	var staleTags []string
+	if store != nil {
+		stored, err := store.Load(key)
+		if err != nil {
+			return PublishResult{}, false, err
+		}
+		previous = stored
+	} else {
+		labels, err := api.existingTags(siteId, setup)
+		if err != nil {
+			return PublishResult{}, false, err
+		}
+		for _, label := range labels {
+			if strings.HasPrefix(label, digest_tag_prefix) {
+				previous = strings.TrimPrefix(label, digest_tag_prefix)
+				staleTags = append(staleTags, label)
+			}
+		}
+	}
+	if previous == digest {
+		return PublishResult{}, false, nil
+	}
+
+	setup.Overwrite = true
+	if store == nil {
+		setup.Tags = append(append([]string(nil), setup.Tags...), digest_tag_prefix+digest)
+	}
+	result, err := api.PublishWithSetup(siteId, setup)
+	if err != nil {
+		return result, false, err
+	}
+	if store != nil {
+		return result, true, store.Save(key, digest)
+	}
+	for _, label := range staleTags {
+		if result.Workbook != nil {
+			err = api.DeleteTagFromWorkbook(siteId, result.Workbook.ID, label)
+		} else {
+			err = api.DeleteTagFromDatasource(siteId, result.Datasource.ID, label)
+		}
+		if err != nil {
+			return result, true, err
+		}
+	}
+	return result, true, nil
+}
+
+// existingTags returns the tags on the content setup would overwrite, if any.
+// The project is found as PublishWithSetup finds it, by name and parent.
+func (api *API) existingTags(siteId SiteID, setup PublishSetup) ([]string, error) {
+	projects, err := api.queryAllProjects(siteId, ListOptions{Filter: "name:eq:" + FilterValue(setup.ProjectName)})
+	if err != nil {
+		return nil, err
+	}
+	project, found := findProject(projects, setup.ParentProjectID, setup.ProjectName)
+	if !found {
+		return nil, nil
+	}
+	if setup.Workbook != nil {
+		workbooks, err := api.queryAllWorkbooks(siteId, ListOptions{Filter: "name:eq:" + FilterValue(setup.Workbook.Name)})
+		if err != nil {
+			return nil, err
+		}
+		for _, wb := range workbooks {
+			if wb.Name == setup.Workbook.Name && wb.Project != nil && wb.Project.ID == project.ID {
+				return wb.Tags.Labels(), nil
+			}
+		}
+		return nil, nil
+	}
+	datasources, err := api.queryAllDatasources(siteId, ListOptions{Filter: "name:eq:" + FilterValue(setup.Datasource.Name)})
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range datasources {
+		if ds.Name == setup.Datasource.Name && ds.Project != nil && ds.Project.ID == project.ID {
+			return ds.Tags.Labels(), nil
+		}
+	}
+	return nil, nil
+}
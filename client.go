@@ -13,12 +13,14 @@ package tableau4go
 
 import (
 	"bytes"
-	"encoding/xml"
+	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"mime"
 	"net/http"
-	"strconv"
+	neturl "net/url"
 	"strings"
 	"time"
 )
@@ -26,41 +28,99 @@ import (
 const content_type_header = "Content-Type"
 const content_length_header = "Content-Length"
 const auth_header = "X-Tableau-Auth"
+const content_disposition_header = "Content-Disposition"
 const application_xml_content_type = "application/xml"
+const application_json_content_type = "application/json"
 const POST = "POST"
 const GET = "GET"
+const PUT = "PUT"
 const DELETE = "DELETE"
+const PATCH = "PATCH"
 
 var ErrDoesNotExist = errors.New("Does Not Exist")
 
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Sign_In%3FTocPath%3DAPI%2520Reference%7C_____51
-func (api *API) Signin(username, password string, contentUrl string, userIdToImpersonate string) error {
-	url := fmt.Sprintf("%s/api/%s/auth/signin", api.Server, api.Version)
+func (api *API) Signin(username, password string, contentUrl string, userIdToImpersonate UserID) error {
 	credentials := Credentials{Name: username, Password: password}
 	if len(userIdToImpersonate) > 0 {
 		credentials.Impersonate = &User{ID: userIdToImpersonate}
 	}
-	siteName := contentUrl
+	credentials.Site = &Site{ContentUrl: contentUrl}
+	return api.signin(credentials)
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Sign_In%3FTocPath%3DAPI%2520Reference%7C_____51
+//signs in with whatever the configured CredentialProvider currently returns
+func (api *API) SigninWithProvider() error {
+	if api.CredentialProvider == nil {
+		return ErrNoCredentialProvider
+	}
+	credentials, err := api.CredentialProvider.Credentials()
+	if err != nil {
+		return err
+	}
+	if credentials.Site != nil {
+		credentials.Site = &Site{ContentUrl: api.lifecycle().siteContentUrl(credentials.Site.ContentUrl)}
+	}
+	if provider, ok := api.CredentialProvider.(SecretCredentialProvider); ok {
+		secret, err := provider.Secret()
+		if err != nil {
+			return err
+		}
+		defer clear(secret)
+		return api.signinWithSecret(credentials, secret)
+	}
+	return api.signin(credentials)
+}
+
+func (api *API) signin(credentials Credentials) error {
+	return api.signinWithSecret(credentials, nil)
+}
+
+// signinWithSecret zeroes the request body once it is sent, since it holds
+// the password or token in plain text.
+func (api *API) signinWithSecret(credentials Credentials, secret []byte) error {
+	url := fmt.Sprintf("%s/api/%s/auth/signin", api.Server, api.Version)
+	siteName := ""
+	if credentials.Site != nil {
+		siteName = credentials.Site.ContentUrl
+	}
 	// this seems to have changed. If you are looking for the default site, you must pass
 	// blank
-	if api.OmitDefaultSiteName {
-		if contentUrl == api.DefaultSiteName {
-			siteName = ""
-		}
+	if api.isDefaultSite(siteName) {
+		siteName = ""
 	}
 	credentials.Site = &Site{ContentUrl: siteName}
-	request := SigninRequest{Request: credentials}
-	signInXML, err := request.XML()
+	if len(credentials.PersonalAccessTokenName) == 0 && len(credentials.JWT) == 0 {
+		if err := api.requireDeployment("PasswordSignin"); err != nil {
+			return err
+		}
+	}
+	var payload []byte
+	var err error
+	if secret != nil {
+		payload, err = spliceSecret(credentials, secret)
+	} else {
+		payload, err = SigninRequest{Request: credentials}.XML()
+	}
 	if err != nil {
 		return err
 	}
-	payload := string(signInXML)
+	defer clear(payload)
 	headers := make(map[string]string)
 	headers[content_type_header] = application_xml_content_type
 	retval := AuthResponse{}
-	err = api.makeRequest(url, POST, []byte(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	err = api.sendRequest(url, POST, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
 	if err == nil {
-		api.AuthToken = retval.Credentials.Token
+		session := Session{AuthToken: retval.Credentials.Token}
+		if retval.Credentials.Site != nil {
+			session.SiteID = retval.Credentials.Site.ID
+			session.SiteContentUrl = retval.Credentials.Site.ContentUrl
+		}
+		if retval.Credentials.Impersonate != nil {
+			session.UserID = retval.Credentials.Impersonate.ID
+		}
+		api.setSession(session, newSessionClock(api.now(), api.sessionIdleTimeout(retval.Credentials.EstimatedTimeToExpiration)))
 	}
 	return err
 }
@@ -71,31 +131,61 @@ func (api *API) Signout() error {
 	headers := make(map[string]string)
 	headers[content_type_header] = application_xml_content_type
 	err := api.makeRequest(url, POST, nil, nil, headers, connectTimeOut, readWriteTimeout)
+	if err == nil {
+		api.clearSession()
+	}
 	return err
 }
 
+func (api *API) clearSession() {
+	api.setSession(Session{}, nil)
+}
+
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Server_Info%3FTocPath%3DAPI%2520Reference%7C__
+//the answer is cached for ServerInfoTTL, shared by the client's WithContext copies
 func (api *API) ServerInfo() (ServerInfo, error) {
-	// this call only works on apiVersion 2.4 and up
-	url := fmt.Sprintf("%s/api/%s/serverinfo", api.Server, "2.4")
+	if info, ok := api.lifecycle().cachedServerInfo(api.now(), api.serverInfoTTL()); ok {
+		return info, nil
+	}
+	return api.queryServerInfo()
+}
+
+// serverInfoURL is the ServerInfo endpoint; it is asked on API version 2.4,
+// the first to offer it, so it answers whatever version the server runs
+func (api *API) serverInfoURL() string {
+	return fmt.Sprintf("%s/api/%s/serverinfo", api.Server, "2.4")
+}
+
+func (api *API) queryServerInfo() (ServerInfo, error) {
+	url := api.serverInfoURL()
 	headers := make(map[string]string)
 	retval := ServerInfoResponse{}
 	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	if err == nil {
+		api.lifecycle().cacheServerInfo(retval.ServerInfo, api.now())
+	}
 	return retval.ServerInfo, err
 }
 
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Sites%3FTocPath%3DAPI%2520Reference%7C_____40
-func (api *API) QuerySites() ([]Site, error) {
-	url := fmt.Sprintf("%s/api/%s/sites/", api.Server, api.Version)
-	headers := make(map[string]string)
-	retval := QuerySitesResponse{}
-	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
-	return retval.Sites.Sites, err
+//returns every site matching the filter and sort in opts, fetching as many pages as needed.
+//a server administrator sees every site on the server, anyone else only the sites they
+//belong to; servers that refuse non-administrators the list (403) get the signed in site
+func (api *API) QuerySites(opts ListOptions) ([]Site, error) {
+	sites, err := listAt[Site, QuerySitesResponse](api, fmt.Sprintf("%s/api/%s/sites", api.Server, api.Version), opts)
+	if signedIn := api.CurrentSite().ID; isForbidden(err) && len(sites) == 0 && len(signedIn) > 0 {
+		site, err := api.QuerySite(signedIn, false)
+		if err != nil {
+			return sites, err
+		}
+		return []Site{site}, nil
+	}
+	return sites, err
 }
 
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Sites%3FTocPath%3DAPI%2520Reference%7C_____40
-func (api *API) QuerySite(siteID string, includeStorage bool) (Site, error) {
-	url := fmt.Sprintf("%s/api/%s/sites/%s", api.Server, api.Version, siteID)
+func (api *API) QuerySite(siteID SiteID, includeStorage bool) (Site, error) {
+	url := api.siteUrl(siteID)
 	if includeStorage {
 		url += fmt.Sprintf("?includeStorage=%v", includeStorage)
 	}
@@ -129,25 +219,327 @@ func (api *API) querySite(url string) (Site, error) {
 	return retval.Site, err
 }
 
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#create_site
+//the site is checked with Site.Validate before the request is sent
+func (api *API) CreateSite(site Site) (Site, error) {
+	if err := api.requireVersion("CreateSite"); err != nil {
+		return Site{}, err
+	}
+	if err := site.Validate(); err != nil {
+		return Site{}, err
+	}
+	url := fmt.Sprintf("%s/api/%s/sites", api.Server, api.Version)
+	create := site
+	create.ID = ""
+	create.Usage = nil
+	payload, err := api.codec().Marshal(CreateSiteRequest{Request: create})
+	if err != nil {
+		return Site{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := QuerySiteResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Site, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#update_site
+//the fields being changed are checked before the request is sent. renaming the
+//signed in site signs in again under the new name through the CredentialProvider; without one
+//the rename still happens but ErrSigninRequired is returned
+func (api *API) UpdateSite(site Site) (Site, error) {
+	if err := site.validate(false); err != nil {
+		return Site{}, err
+	}
+	url := api.siteUrl(site.ID)
+	update := site
+	update.ID = ""
+	update.Usage = nil
+	payload, err := api.codec().Marshal(UpdateSiteRequest{Request: update})
+	if err != nil {
+		return Site{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := QuerySiteResponse{}
+	renaming, from := api.renaming(site), api.CurrentSite().ContentUrl
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	if err == nil && renaming && api.DryRun == nil {
+		err = api.siteRenamed(from, site.ContentUrl)
+	}
+	return retval.Site, err
+}
+
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_User_On_Site%3FTocPath%3DAPI%2520Reference%7C_____47
-func (api *API) QueryUserOnSite(siteId, userId string) (User, error) {
-	url := fmt.Sprintf("%s/api/%s/sites/%s/users/%s", api.Server, api.Version, siteId, userId)
+func (api *API) QueryUserOnSite(siteId SiteID, userId UserID) (User, error) {
+	url := fmt.Sprintf("%s/users/%s", api.siteUrl(siteId), userId)
 	headers := make(map[string]string)
 	retval := QueryUserOnSiteResponse{}
 	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
 	return retval.User, err
 }
 
-//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Projects%3FTocPath%3DAPI%2520Reference%7C_____38
-func (api *API) QueryProjects(siteId string) ([]Project, error) {
-	url := fmt.Sprintf("%s/api/%s/sites/%s/projects", api.Server, api.Version, siteId)
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#get_users_on_site
+func (api *API) QueryUsersOnSite(siteId SiteID, opts ListOptions) ([]User, Pagination, error) {
+	return ListPage[User, QueryUsersResponse](api, siteId, "users", opts)
+}
+
+func (api *API) queryAllUsers(siteId SiteID, opts ListOptions) ([]User, error) {
+	return List[User, QueryUsersResponse](api, siteId, "users", opts)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#add_user_to_site
+func (api *API) AddUserToSite(siteId SiteID, user User, opts AddUserOptions) (User, error) {
+	if err := user.Validate(); err != nil {
+		return User{}, err
+	}
+	if err := opts.IfExists.Validate(); err != nil {
+		return User{}, err
+	}
+	url := fmt.Sprintf("%s/users%s", api.siteUrl(siteId), opts.query())
+	user.Language, user.Locale = "", ""
+	payload, err := api.codec().Marshal(AddUserToSiteRequest{Request: user})
+	if err != nil {
+		return User{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := QueryUserOnSiteResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	if isConflict(err) && opts.IfExists == IfExistsReturn {
+		return api.existingUser(siteId, user.Name, err)
+	}
+	return retval.User, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#update_user
+func (api *API) UpdateUser(siteId SiteID, user User) (User, error) {
+	if err := user.validate(false); err != nil {
+		return User{}, err
+	}
+	url := fmt.Sprintf("%s/users/%s", api.siteUrl(siteId), user.ID)
+	update := user
+	update.ID = ""
+	update.Language, update.Locale = "", ""
+	payload, err := api.codec().Marshal(UpdateUserRequest{Request: update})
+	if err != nil {
+		return User{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := QueryUserOnSiteResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.User, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#remove_user_from_site
+//content the user owns goes to mapAssetsTo when set; the server refuses to remove a user who still owns content otherwise
+func (api *API) RemoveUserFromSite(siteId SiteID, userId UserID, mapAssetsTo UserID) error {
+	url := fmt.Sprintf("%s/users/%s", api.siteUrl(siteId), userId)
+	if len(mapAssetsTo) > 0 {
+		url += fmt.Sprintf("?mapAssetsTo=%s", mapAssetsTo)
+	}
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#get_groups_for_a_user
+func (api *API) QueryGroupsForUser(siteId SiteID, userId UserID, opts ListOptions) ([]Group, Pagination, error) {
+	if err := api.requireVersion("QueryGroupsForUser"); err != nil {
+		return nil, Pagination{}, err
+	}
+	return ListPage[Group, QueryGroupsResponse](api, siteId, fmt.Sprintf("users/%s/groups", userId), opts)
+}
+
+func (api *API) queryAllGroupsForUser(siteId SiteID, userId UserID) ([]Group, error) {
+	if err := api.requireVersion("QueryGroupsForUser"); err != nil {
+		return nil, err
+	}
+	return List[Group, QueryGroupsResponse](api, siteId, fmt.Sprintf("users/%s/groups", userId), ListOptions{})
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_subscriptions.htm#query_subscriptions
+func (api *API) QuerySubscriptions(siteId SiteID, opts ListOptions) ([]Subscription, Pagination, error) {
+	return ListPage[Subscription, QuerySubscriptionsResponse](api, siteId, "subscriptions", opts)
+}
+
+func (api *API) queryAllSubscriptions(siteId SiteID, opts ListOptions) ([]Subscription, error) {
+	return List[Subscription, QuerySubscriptionsResponse](api, siteId, "subscriptions", opts)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_subscriptions.htm#create_subscription
+func (api *API) CreateSubscription(siteId SiteID, subscription Subscription) (Subscription, error) {
+	url := fmt.Sprintf("%s/subscriptions", api.siteUrl(siteId))
+	create := subscription
+	create.ID = ""
+	payload, err := api.codec().Marshal(SubscriptionRequest{Request: create})
+	if err != nil {
+		return Subscription{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := SubscriptionResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Subscription, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_subscriptions.htm#update_subscription
+func (api *API) UpdateSubscription(siteId SiteID, subscription Subscription) (Subscription, error) {
+	url := fmt.Sprintf("%s/subscriptions/%s", api.siteUrl(siteId), subscription.ID)
+	update := subscription
+	update.ID = ""
+	payload, err := api.codec().Marshal(SubscriptionRequest{Request: update})
+	if err != nil {
+		return Subscription{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := SubscriptionResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Subscription, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_subscriptions.htm#delete_subscription
+func (api *API) DeleteSubscription(siteId SiteID, subscriptionId SubscriptionID) error {
+	url := fmt.Sprintf("%s/subscriptions/%s", api.siteUrl(siteId), subscriptionId)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_notifications.htm#create_webhook
+func (api *API) CreateWebhook(siteId SiteID, webhook Webhook) (Webhook, error) {
+	if err := api.requireVersion("CreateWebhook"); err != nil {
+		return Webhook{}, err
+	}
+	url := fmt.Sprintf("%s/webhooks", api.siteUrl(siteId))
+	payload, err := api.codec().Marshal(WebhookRequest{Request: webhook})
+	if err != nil {
+		return Webhook{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := WebhookResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Webhook, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_notifications.htm#list_webhooks_for_site
+func (api *API) QueryWebhooks(siteId SiteID) ([]Webhook, error) {
+	if err := api.requireVersion("QueryWebhooks"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/webhooks", api.siteUrl(siteId))
+	headers := make(map[string]string)
+	retval := QueryWebhooksResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Webhooks.Webhooks, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_notifications.htm#delete_webhook
+func (api *API) DeleteWebhook(siteId SiteID, webhookId WebhookID) error {
+	if err := api.requireVersion("DeleteWebhook"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/webhooks/%s", api.siteUrl(siteId), webhookId)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_driven_alerts.htm#query_data-driven_alerts
+func (api *API) QueryDataAlerts(siteId SiteID, opts ListOptions) ([]DataAlert, Pagination, error) {
+	if err := api.requireVersion("QueryDataAlerts"); err != nil {
+		return nil, Pagination{}, err
+	}
+	return ListPage[DataAlert, QueryDataAlertsResponse](api, siteId, "dataAlerts", opts)
+}
+
+func (api *API) queryAllDataAlerts(siteId SiteID, opts ListOptions) ([]DataAlert, error) {
+	if err := api.requireVersion("QueryDataAlerts"); err != nil {
+		return nil, err
+	}
+	return List[DataAlert, QueryDataAlertsResponse](api, siteId, "dataAlerts", opts)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_driven_alerts.htm#delete_data-driven_alert
+func (api *API) DeleteDataAlert(siteId SiteID, alertId DataAlertID) error {
+	if err := api.requireVersion("DeleteDataAlert"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/dataAlerts/%s", api.siteUrl(siteId), alertId)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#get_favorites_for_user
+func (api *API) QueryFavorites(siteId SiteID, userId UserID) ([]Favorite, error) {
+	url := fmt.Sprintf("%s/favorites/%s", api.siteUrl(siteId), userId)
 	headers := make(map[string]string)
-	retval := QueryProjectsResponse{}
+	retval := FavoritesResponse{}
 	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
-	return retval.Projects.Projects, err
+	return retval.Favorites.Favorites, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#add_favorite
+//favorite must have a Label and exactly one content reference with its ID set
+func (api *API) AddFavorite(siteId SiteID, userId UserID, favorite Favorite) ([]Favorite, error) {
+	url := fmt.Sprintf("%s/favorites/%s", api.siteUrl(siteId), userId)
+	payload, err := api.codec().Marshal(AddFavoriteRequest{Request: favorite})
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := FavoritesResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Favorites.Favorites, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#organize_favorites
+//each order moves one favorite to just after another
+func (api *API) OrderFavorites(siteId SiteID, userId UserID, orders []FavoriteOrder) error {
+	if err := api.requireVersion("OrderFavorites"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/orderFavorites/%s", api.siteUrl(siteId), userId)
+	payload, err := api.codec().Marshal(OrderFavoritesRequest{Request: FavoriteOrderings{FavoriteOrders: orders}})
+	if err != nil {
+		return err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	return api.makeRequest(url, PUT, bytes.NewReader(payload), nil, headers, connectTimeOut, readWriteTimeout)
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Projects%3FTocPath%3DAPI%2520Reference%7C_____38
+//returns every project, fetching as many pages as needed
+func (api *API) QueryProjects(siteId SiteID) ([]Project, error) {
+	return api.queryAllProjects(siteId, ListOptions{})
+}
+
+//returns every project matching opts, fetching as many pages as needed. with opts.Fields FIELDS_ALL
+//the owner's name and email and the controlling permissions project are included
+func (api *API) QueryProjectsWithOptions(siteId SiteID, opts ListOptions) ([]Project, error) {
+	return api.queryAllProjects(siteId, opts)
 }
 
-func (api *API) GetProjectByName(siteId, name string) (Project, error) {
+func (api *API) queryAllProjects(siteId SiteID, opts ListOptions) ([]Project, error) {
+	return List[Project, QueryProjectsResponse](api, siteId, "projects", opts)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_projects.htm#update_project
+func (api *API) UpdateProject(siteId SiteID, project Project) (*Project, error) {
+	if err := project.validate(false); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/projects/%s", api.siteUrl(siteId), project.ID)
+	payload, err := api.codec().Marshal(CreateProjectRequest{Request: project.request()})
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := CreateProjectResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Project, err
+}
+
+func (api *API) GetProjectByName(siteId SiteID, name string) (Project, error) {
 	projects, err := api.QueryProjects(siteId)
 	if err != nil {
 		return Project{}, err
@@ -160,7 +552,7 @@ func (api *API) GetProjectByName(siteId, name string) (Project, error) {
 	return Project{}, fmt.Errorf("Project Named '%s' Not Found", name)
 }
 
-func (api *API) GetProjectByID(siteId, ID string) (Project, error) {
+func (api *API) GetProjectByID(siteId SiteID, ID ProjectID) (Project, error) {
 	projects, err := api.QueryProjects(siteId)
 	if err != nil {
 		return Project{}, err
@@ -174,130 +566,1570 @@ func (api *API) GetProjectByID(siteId, ID string) (Project, error) {
 }
 
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Datasources%3FTocPath%3DAPI%2520Reference%7C_____33
-func (api *API) QueryDatasources(siteId string) ([]Datasource, error) {
-	url := fmt.Sprintf("%s/api/%s/sites/%s/datasources", api.Server, api.Version, siteId)
+//returns every datasource, fetching as many pages as needed
+func (api *API) QueryDatasources(siteId SiteID) ([]Datasource, error) {
+	return api.queryAllDatasources(siteId, ListOptions{})
+}
+
+func (api *API) queryAllDatasources(siteId SiteID, opts ListOptions) ([]Datasource, error) {
+	return List[Datasource, QueryDatasourcesResponse](api, siteId, "datasources", opts)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#query_data_source
+func (api *API) QueryDatasource(siteId SiteID, datasourceId DatasourceID) (Datasource, error) {
+	url := fmt.Sprintf("%s/datasources/%s", api.siteUrl(siteId), datasourceId)
+	headers := make(map[string]string)
+	retval := DatasourceResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Datasource, err
+}
+
+//looks the datasource up with a server side name filter instead of listing the site;
+//returns ErrDoesNotExist if the project has no datasource of that name
+func (api *API) GetDatasourceByName(siteId SiteID, projectId ProjectID, name string) (Datasource, error) {
+	datasources, err := api.queryAllDatasources(siteId, ListOptions{Filter: "name:eq:" + FilterValue(name)})
+	if err != nil {
+		return Datasource{}, err
+	}
+	for _, ds := range datasources {
+		if ds.Name == name && ds.Project != nil && ds.Project.ID == projectId {
+			return ds, nil
+		}
+	}
+	return Datasource{}, ErrDoesNotExist
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#update_data_source
+func (api *API) UpdateDatasource(siteId SiteID, datasource Datasource) (*Datasource, error) {
+	if err := datasource.validate(false); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/datasources/%s", api.siteUrl(siteId), datasource.ID)
+	update := datasource
+	update.ID = ""
+	update.ContentUrl = ""
+	update.Usage = nil
+	payload, err := api.codec().Marshal(DatasourceCreateRequest{Request: update})
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := DatasourceResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Datasource, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#update_data_source
+func (api *API) CertifyDatasource(siteId SiteID, datasourceId DatasourceID, certified bool, note string) (*Datasource, error) {
+	url := fmt.Sprintf("%s/datasources/%s", api.siteUrl(siteId), datasourceId)
+	request := CertifyDatasourceRequest{}
+	request.Request.IsCertified = certified
+	request.Request.CertificationNote = note
+	payload, err := api.codec().Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := DatasourceResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Datasource, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#query_data_source_connections
+func (api *API) QueryDatasourceConnections(siteId SiteID, datasourceId DatasourceID) ([]Connection, error) {
+	url := fmt.Sprintf("%s/datasources/%s/connections", api.siteUrl(siteId), datasourceId)
 	headers := make(map[string]string)
-	retval := QueryDatasourcesResponse{}
+	retval := QueryConnectionsResponse{}
 	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
-	return retval.Datasources.Datasources, err
+	return retval.Connections.Connections, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#update_data_source_connection
+//only the server address, port, user name and password can be changed
+func (api *API) UpdateDatasourceConnection(siteId SiteID, datasourceId DatasourceID, connection Connection) (Connection, error) {
+	url := fmt.Sprintf("%s/datasources/%s/connections/%s", api.siteUrl(siteId), datasourceId, connection.ID)
+	update := connection
+	update.ID = ""
+	update.Type = ""
+	update.Datasource = nil
+	payload, err := api.codec().Marshal(UpdateConnectionRequest{Request: update})
+	if err != nil {
+		return Connection{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := ConnectionResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Connection, err
 }
 
-func (api *API) GetSiteID(siteName string) (string, error) {
+// GetSiteID caches the ids it looks up for the life of the client, shared by
+// its WithContext copies. An entry is dropped when the server says its site
+// doesn't exist; ForgetSiteIDs drops them all.
+func (api *API) GetSiteID(siteName string) (SiteID, error) {
+	if id, ok := api.lifecycle().cachedSiteID(siteName); ok {
+		return id, nil
+	}
 	site, err := api.QuerySiteByName(siteName, false)
 	if err != nil {
 		return "", err
 	}
+	api.lifecycle().cacheSiteID(siteName, site.ID)
 	return site.ID, err
 }
 
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Create_Project%3FTocPath%3DAPI%2520Reference%7C_____14
 //POST /api/api-version/sites/site-id/projects
-func (api *API) CreateProject(siteId string, project Project) (*Project, error) {
-	url := fmt.Sprintf("%s/api/%s/sites/%s/projects", api.Server, api.Version, siteId)
-	createProjectRequest := CreateProjectRequest{Request: project}
-	xmlRep, err := createProjectRequest.XML()
+func (api *API) CreateProject(siteId SiteID, project Project) (*Project, error) {
+	return api.CreateProjectWithOptions(siteId, project, CreateOptions{})
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Create_Project%3FTocPath%3DAPI%2520Reference%7C_____14
+//with IfExistsReturn a name already taken under the same parent returns that project
+func (api *API) CreateProjectWithOptions(siteId SiteID, project Project, opts CreateOptions) (*Project, error) {
+	if err := opts.IfExists.Validate(); err != nil {
+		return nil, err
+	}
+	if err := project.Validate(); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/projects", api.siteUrl(siteId))
+	createProjectRequest := CreateProjectRequest{Request: project.request()}
+	xmlRep, err := api.codec().Marshal(createProjectRequest)
 	if err != nil {
 		return nil, err
 	}
 	headers := make(map[string]string)
-	headers[content_type_header] = application_xml_content_type
+	headers[content_type_header] = api.codec().ContentType()
 	createProjectResponse := CreateProjectResponse{}
-	err = api.makeRequest(url, POST, xmlRep, &createProjectResponse, headers, connectTimeOut, readWriteTimeout)
+	err = api.makeRequest(url, POST, bytes.NewReader(xmlRep), &createProjectResponse, headers, connectTimeOut, readWriteTimeout)
+	if isConflict(err) && opts.IfExists == IfExistsReturn {
+		return api.existingProject(siteId, project, err)
+	}
 	return &createProjectResponse.Project, err
 }
 
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Publish_Datasource%3FTocPath%3DAPI%2520Reference%7C_____31
-func (api *API) PublishTDS(siteId string, tdsMetadata Datasource, fullTds string, overwrite bool) (retval *Datasource, err error) {
-	return api.publishDatasource(siteId, tdsMetadata, fullTds, "tds", overwrite)
+func (api *API) PublishTDS(siteId SiteID, tdsMetadata Datasource, fullTds string, overwrite bool) (retval *Datasource, err error) {
+	return api.publishDatasource(siteId, tdsMetadata, []byte(fullTds), "tds", overwriteMode(overwrite))
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Publish_Datasource%3FTocPath%3DAPI%2520Reference%7C_____31
+//datasourceType is the file extension of content: tds, tdsx or hyper
+func (api *API) PublishDatasource(siteId SiteID, metadata Datasource, content []byte, datasourceType string, overwrite bool) (*Datasource, error) {
+	return api.publishDatasource(siteId, metadata, content, datasourceType, overwriteMode(overwrite))
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#publish_data_source
+//adds the rows in content to the existing extract of the same name; content must be a single table tdsx or hyper
+func (api *API) AppendDatasource(siteId SiteID, metadata Datasource, content []byte, datasourceType string) (*Datasource, error) {
+	return api.publishDatasource(siteId, metadata, content, datasourceType, PublishModeAppend)
 }
 
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Publish_Datasource%3FTocPath%3DAPI%2520Reference%7C_____31
-func (api *API) publishDatasource(siteId string, tdsMetadata Datasource, datasource string, datasourceType string, overwrite bool) (retval *Datasource, err error) {
-	url := fmt.Sprintf("%s/api/%s/sites/%s/datasources?datasourceType=%s&overwrite=%v", api.Server, api.Version, siteId, datasourceType, overwrite)
-	payload := fmt.Sprintf("--%s\r\n", api.Boundary)
-	payload += "Content-Disposition: name=\"request_payload\"\r\n"
-	payload += "Content-Type: text/xml\r\n"
-	payload += "\r\n"
+func (api *API) publishDatasource(siteId SiteID, tdsMetadata Datasource, datasource []byte, datasourceType string, mode PublishMode) (retval *Datasource, err error) {
+	if err := mode.Validate(); err != nil {
+		return nil, err
+	}
+	if err := tdsMetadata.Validate(); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/datasources?datasourceType=%s&%s", api.siteUrl(siteId), datasourceType, mode.query())
+	// the content URL is derived from the name
+	tdsMetadata.ContentUrl = ""
 	tdsRequest := DatasourceCreateRequest{Request: tdsMetadata}
 	xmlRepresentation, err := tdsRequest.XML()
 	if err != nil {
 		return retval, err
 	}
-	payload += string(xmlRepresentation)
-	payload += fmt.Sprintf("\r\n--%s\r\n", api.Boundary)
-	payload += fmt.Sprintf("Content-Disposition: name=\"tableau_datasource\"; filename=\"%s.tds\"\r\n", tdsMetadata.Name)
-	payload += "Content-Type: application/octet-stream\r\n"
-	payload += "\r\n"
-	payload += datasource
-	payload += fmt.Sprintf("\r\n--%s--\r\n", api.Boundary)
+	boundary, err := api.newBoundary(xmlRepresentation, datasource)
+	if err != nil {
+		return retval, err
+	}
+	header := fmt.Sprintf("--%s\r\n", boundary)
+	header += "Content-Disposition: name=\"request_payload\"\r\n"
+	header += "Content-Type: text/xml\r\n"
+	header += "\r\n"
+	header += string(xmlRepresentation)
+	header += fmt.Sprintf("\r\n--%s\r\n", boundary)
+	header += fmt.Sprintf("Content-Disposition: name=\"tableau_datasource\"; filename=\"%s.%s\"\r\n", tdsMetadata.Name, datasourceType)
+	header += "Content-Type: application/octet-stream\r\n"
+	header += "\r\n"
+	trailer := fmt.Sprintf("\r\n--%s--\r\n", boundary)
 	headers := make(map[string]string)
-	headers[content_type_header] = fmt.Sprintf("multipart/mixed; boundary=%s", api.Boundary)
-	err = api.makeRequest(url, POST, []byte(payload), retval, headers, connectTimeOut, readWriteTimeout)
-	return retval, err
+	headers[content_type_header] = fmt.Sprintf("multipart/mixed; boundary=%s", boundary)
+	response := DatasourceResponse{}
+	payload := newMultipartBody([]byte(header), datasource, []byte(trailer))
+	err = api.makeRequest(url, POST, payload, &response, headers, connectTimeOut, readWriteTimeout)
+	return &response.Datasource, err
 }
 
-//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Datasource%3FTocPath%3DAPI%2520Reference%7C_____15
-func (api *API) DeleteDatasource(siteId string, datasourceId string) error {
-	url := fmt.Sprintf("%s/api/%s/sites/%s/datasources/%s", api.Server, api.Version, siteId, datasourceId)
-	return api.delete(url)
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_revisions.htm#get_data_source_revisions
+//returns every revision of the datasource, oldest first
+func (api *API) QueryDatasourceRevisions(siteId SiteID, datasourceId DatasourceID) ([]Revision, error) {
+	return List[Revision, QueryRevisionsResponse](api, siteId, fmt.Sprintf("datasources/%s/revisions", datasourceId), ListOptions{})
 }
 
-//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Project%3FTocPath%3DAPI%2520Reference%7C_____17
-func (api *API) DeleteProject(siteId string, projectId string) error {
-	url := fmt.Sprintf("%s/api/%s/sites/%s/projects/%s", api.Server, api.Version, siteId, projectId)
-	return api.delete(url)
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#download_data_source
+//streams the datasource file to w as it arrives and returns its file name, whose extension tells tds from tdsx
+func (api *API) DownloadDatasource(siteId SiteID, datasourceId DatasourceID, includeExtract bool, w io.Writer) (string, error) {
+	url := fmt.Sprintf("%s/datasources/%s/content?includeExtract=%v", api.siteUrl(siteId), datasourceId, includeExtract)
+	return api.download(url, w)
 }
 
-//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Project%3FTocPath%3DAPI%2520Reference%7C_____17
-func (api *API) DeleteSite(siteId string) error {
-	url := fmt.Sprintf("%s/api/%s/sites/%s", api.Server, api.Version, siteId)
-	return api.delete(url)
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#update_data_source_now
+func (api *API) UpdateDatasourceNow(siteId SiteID, datasourceId DatasourceID) (Job, error) {
+	if err := api.requireVersion("UpdateDatasourceNow"); err != nil {
+		return Job{}, err
+	}
+	url := fmt.Sprintf("%s/datasources/%s/refresh", api.siteUrl(siteId), datasourceId)
+	return api.refresh(url)
 }
 
-//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Site%3FTocPath%3DAPI%2520Reference%7C_____19
-func (api *API) DeleteSiteByName(name string) error {
-	return api.deleteSiteByKey("name", name)
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_permissions.htm#query_data_source_permissions
+func (api *API) QueryDatasourcePermissions(siteId SiteID, datasourceId DatasourceID) (Permissions, error) {
+	url := fmt.Sprintf("%s/datasources/%s/permissions", api.siteUrl(siteId), datasourceId)
+	return api.queryPermissions(url)
 }
 
-//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Site%3FTocPath%3DAPI%2520Reference%7C_____19
-func (api *API) DeleteSiteByContentUrl(contentUrl string) error {
-	return api.deleteSiteByKey("contentUrl", contentUrl)
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_workbooks_for_site
+//returns every workbook, fetching as many pages as needed
+func (api *API) QueryWorkbooks(siteId SiteID) ([]Workbook, error) {
+	return api.queryAllWorkbooks(siteId, ListOptions{})
 }
 
-//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Site%3FTocPath%3DAPI%2520Reference%7C_____19
-func (api *API) deleteSiteByKey(key string, value string) error {
-	url := fmt.Sprintf("%s/api/%s/sites/%s?key=%s", api.Server, api.Version, value, key)
-	return api.delete(url)
+func (api *API) queryAllWorkbooks(siteId SiteID, opts ListOptions) ([]Workbook, error) {
+	return List[Workbook, QueryWorkbooksResponse](api, siteId, "workbooks", opts)
 }
 
-func (api *API) delete(url string) error {
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#update_workbook
+func (api *API) UpdateWorkbook(siteId SiteID, workbook Workbook) (*Workbook, error) {
+	url := fmt.Sprintf("%s/workbooks/%s", api.siteUrl(siteId), workbook.ID)
+	update := workbook
+	update.ID = ""
+	update.Views = nil
+	update.Usage = nil
+	payload, err := api.codec().Marshal(WorkbookCreateRequest{Request: update})
+	if err != nil {
+		return nil, err
+	}
 	headers := make(map[string]string)
-	return api.makeRequest(url, DELETE, nil, nil, headers, connectTimeOut, readWriteTimeout)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := WorkbookResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Workbook, err
 }
 
-func (api *API) makeRequest(requestUrl string, method string, payload []byte, result interface{}, headers map[string]string,
-	cTimeout time.Duration, rwTimeout time.Duration) error {
-	var debug = false
-	if debug {
-		fmt.Printf("%s:%v\n", method, requestUrl)
-		if payload != nil {
-			fmt.Printf("%v\n", string(payload))
-		}
-	}
-	client := DefaultTimeoutClient()
-	var req *http.Request
-	if len(payload) > 0 {
-		var httpErr error
-		req, httpErr = http.NewRequest(strings.TrimSpace(method), strings.TrimSpace(requestUrl), bytes.NewBuffer(payload))
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_workbook
+func (api *API) QueryWorkbook(siteId SiteID, workbookId WorkbookID) (Workbook, error) {
+	url := fmt.Sprintf("%s/workbooks/%s", api.siteUrl(siteId), workbookId)
+	headers := make(map[string]string)
+	retval := WorkbookResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Workbook, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#get_workbook_downgrade_info
+//productVersion is the Desktop version to open the workbook in, e.g. 2021.4
+func (api *API) GetWorkbookDowngradeInfo(siteId SiteID, workbookId WorkbookID, productVersion string) (DowngradeInfo, error) {
+	url := fmt.Sprintf("%s/workbooks/%s/downGradeInfo?productVersion=%s", api.siteUrl(siteId), workbookId, neturl.QueryEscape(productVersion))
+	headers := make(map[string]string)
+	retval := DowngradeInfoResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.DowngradeInfo, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#publish_workbook
+//workbookType is the file extension of content: twb or twbx
+func (api *API) PublishWorkbook(siteId SiteID, metadata Workbook, content []byte, workbookType string, overwrite bool) (*Workbook, error) {
+	workbook, _, err := api.PublishWorkbookWithOptions(siteId, metadata, content, workbookType, WorkbookPublishOptions{Overwrite: overwrite})
+	if workbook == nil {
+		workbook = &Workbook{}
+	}
+	return workbook, err
+}
+
+// WorkbookPublishOptions are the less common settings for publishing a
+// workbook. HideViews names sheets to publish hidden, in addition to any
+// marked Hidden in the workbook's Views. With AsJob set the workbook is
+// published in the background and a Job is returned instead of the workbook;
+// pass it to WaitForJob to follow it. Progress, if not nil, is told when the
+// request is sent and when it is answered.
+type WorkbookPublishOptions struct {
+	Overwrite bool
+	HideViews []string
+	AsJob     bool
+	Progress  ProgressReporter
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#publish_workbook
+//returns the published workbook, or the publishing job when opts.AsJob is set
+func (api *API) PublishWorkbookWithOptions(siteId SiteID, metadata Workbook, content []byte, workbookType string, opts WorkbookPublishOptions) (*Workbook, *Job, error) {
+	url := fmt.Sprintf("%s/workbooks?workbookType=%s&overwrite=%v", api.siteUrl(siteId), workbookType, opts.Overwrite)
+	if opts.AsJob {
+		url += "&asJob=true"
+	}
+	if len(opts.HideViews) > 0 {
+		metadata.Views = hideViews(metadata.Views, opts.HideViews)
+	}
+	request := WorkbookCreateRequest{Request: metadata}
+	xmlRepresentation, err := request.XML()
+	if err != nil {
+		return nil, nil, err
+	}
+	boundary, err := api.newBoundary(xmlRepresentation, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := fmt.Sprintf("--%s\r\n", boundary)
+	header += "Content-Disposition: name=\"request_payload\"\r\n"
+	header += "Content-Type: text/xml\r\n"
+	header += "\r\n"
+	header += string(xmlRepresentation)
+	header += fmt.Sprintf("\r\n--%s\r\n", boundary)
+	header += fmt.Sprintf("Content-Disposition: name=\"tableau_workbook\"; filename=\"%s.%s\"\r\n", metadata.Name, workbookType)
+	header += "Content-Type: application/octet-stream\r\n"
+	header += "\r\n"
+	trailer := fmt.Sprintf("\r\n--%s--\r\n", boundary)
+	headers := make(map[string]string)
+	headers[content_type_header] = fmt.Sprintf("multipart/mixed; boundary=%s", boundary)
+	retval := PublishWorkbookResponse{}
+	payload := newMultipartBody([]byte(header), content, []byte(trailer))
+	total := payload.Size()
+	reportProgress(opts.Progress, PublishProgress{Stage: ProgressPublish, TotalBytes: total})
+	err = api.makeRequest(url, POST, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	if err == nil {
+		reportProgress(opts.Progress, PublishProgress{Stage: ProgressPublish, BytesSent: total, TotalBytes: total, Job: retval.Job})
+	}
+	return retval.Workbook, retval.Job, err
+}
+
+// hideViews returns views with each of names marked hidden, adding views
+// that are not listed yet.
+func hideViews(views *Views, names []string) *Views {
+	hidden := &Views{}
+	if views != nil {
+		hidden.Views = append(hidden.Views, views.Views...)
+	}
+	for _, name := range names {
+		found := false
+		for i := range hidden.Views {
+			if hidden.Views[i].Name == name {
+				hidden.Views[i].Hidden = true
+				found = true
+			}
+		}
+		if !found {
+			hidden.Views = append(hidden.Views, View{Name: name, Hidden: true})
+		}
+	}
+	return hidden
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_revisions.htm#get_workbook_revisions
+//returns every revision of the workbook, oldest first
+func (api *API) QueryWorkbookRevisions(siteId SiteID, workbookId WorkbookID) ([]Revision, error) {
+	return List[Revision, QueryRevisionsResponse](api, siteId, fmt.Sprintf("workbooks/%s/revisions", workbookId), ListOptions{})
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#download_workbook
+//streams the workbook file to w as it arrives and returns its file name, whose extension tells twb from twbx
+func (api *API) DownloadWorkbook(siteId SiteID, workbookId WorkbookID, includeExtract bool, w io.Writer) (string, error) {
+	url := fmt.Sprintf("%s/workbooks/%s/content?includeExtract=%v", api.siteUrl(siteId), workbookId, includeExtract)
+	return api.download(url, w)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_workbook_connections
+func (api *API) QueryWorkbookConnections(siteId SiteID, workbookId WorkbookID) ([]Connection, error) {
+	url := fmt.Sprintf("%s/workbooks/%s/connections", api.siteUrl(siteId), workbookId)
+	headers := make(map[string]string)
+	retval := QueryConnectionsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Connections.Connections, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#update_workbook_now
+func (api *API) UpdateWorkbookNow(siteId SiteID, workbookId WorkbookID) (Job, error) {
+	if err := api.requireVersion("UpdateWorkbookNow"); err != nil {
+		return Job{}, err
+	}
+	url := fmt.Sprintf("%s/workbooks/%s/refresh", api.siteUrl(siteId), workbookId)
+	return api.refresh(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_extract_and_encryption.htm#create_extract_for_datasource
+func (api *API) CreateExtractForDatasource(siteId SiteID, datasourceId DatasourceID, encrypt bool) (Job, error) {
+	if err := api.requireVersion("CreateExtractForDatasource"); err != nil {
+		return Job{}, err
+	}
+	url := fmt.Sprintf("%s/datasources/%s/createExtract?encrypt=%v", api.siteUrl(siteId), datasourceId, encrypt)
+	return api.refresh(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_extract_and_encryption.htm#delete_extract_from_datasource
+func (api *API) DeleteExtractFromDatasource(siteId SiteID, datasourceId DatasourceID) error {
+	if err := api.requireVersion("DeleteExtractFromDatasource"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/datasources/%s/deleteExtract", api.siteUrl(siteId), datasourceId)
+	headers := make(map[string]string)
+	return api.makeRequest(url, POST, nil, nil, headers, connectTimeOut, readWriteTimeout)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_extract_and_encryption.htm#create_extracts_for_workbook
+//extracts the given embedded datasources, or all of them when datasourceIds is empty
+func (api *API) CreateExtractsForWorkbook(siteId SiteID, workbookId WorkbookID, encrypt bool, datasourceIds []DatasourceID) (Job, error) {
+	if err := api.requireVersion("CreateExtractsForWorkbook"); err != nil {
+		return Job{}, err
+	}
+	url := fmt.Sprintf("%s/workbooks/%s/createExtract?encrypt=%v", api.siteUrl(siteId), workbookId, encrypt)
+	payload, err := api.codec().Marshal(newExtractDatasourcesRequest(datasourceIds))
+	if err != nil {
+		return Job{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := JobResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Job, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_extract_and_encryption.htm#delete_extracts_from_workbook
+//removes the extracts of the given embedded datasources, or all of them when datasourceIds is empty
+func (api *API) DeleteExtractsFromWorkbook(siteId SiteID, workbookId WorkbookID, datasourceIds []DatasourceID) error {
+	if err := api.requireVersion("DeleteExtractsFromWorkbook"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/workbooks/%s/deleteExtract", api.siteUrl(siteId), workbookId)
+	payload, err := api.codec().Marshal(newExtractDatasourcesRequest(datasourceIds))
+	if err != nil {
+		return err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	return api.makeRequest(url, POST, bytes.NewReader(payload), nil, headers, connectTimeOut, readWriteTimeout)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_views_for_site
+func (api *API) QueryViews(siteId SiteID, opts ListOptions) ([]View, Pagination, error) {
+	return ListPage[View, QueryViewsResponse](api, siteId, "views", opts)
+}
+
+func (api *API) queryAllViews(siteId SiteID, opts ListOptions) ([]View, error) {
+	return List[View, QueryViewsResponse](api, siteId, "views", opts)
+}
+
+//path is "Workbook/Sheet" as it appears in view urls, or the workbook and view names if no url matches;
+//the lookup is filtered on the server so the site's views are not listed
+func (api *API) GetViewByPath(siteId SiteID, path string) (View, error) {
+	workbook, sheet, found := strings.Cut(strings.Trim(path, "/"), "/")
+	if !found || len(workbook) == 0 || len(sheet) == 0 {
+		return View{}, fmt.Errorf("Invalid View Path '%s'", path)
+	}
+	filters := []string{
+		fmt.Sprintf("contentUrl:eq:%s/sheets/%s", FilterValue(workbook), FilterValue(sheet)),
+		fmt.Sprintf("workbookName:eq:%s,name:eq:%s", FilterValue(workbook), FilterValue(sheet)),
+	}
+	for _, filter := range filters {
+		views, _, err := api.QueryViews(siteId, ListOptions{Filter: filter})
+		if err != nil {
+			return View{}, err
+		}
+		if len(views) > 0 {
+			return views[0], nil
+		}
+	}
+	return View{}, ErrDoesNotExist
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_view_image
+//writes the view rendered as a PNG to w
+func (api *API) DownloadViewImage(siteId SiteID, viewId ViewID, opts ViewExportOptions, w io.Writer) error {
+	if err := api.requireVersion("DownloadViewImage"); err != nil {
+		return err
+	}
+	params := neturl.Values{}
+	if opts.HighResolution {
+		params.Set("resolution", "high")
+	}
+	url := fmt.Sprintf("%s/views/%s/image%s", api.siteUrl(siteId), viewId, opts.query(params))
+	_, err := api.download(url, w)
+	return err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_view_pdf
+func (api *API) DownloadViewPDF(siteId SiteID, viewId ViewID, opts ViewExportOptions, w io.Writer) error {
+	if err := api.requireVersion("DownloadViewPDF"); err != nil {
+		return err
+	}
+	params := neturl.Values{}
+	if len(opts.PageType) > 0 {
+		params.Set("type", opts.PageType)
+	}
+	if len(opts.Orientation) > 0 {
+		params.Set("orientation", opts.Orientation)
+	}
+	url := fmt.Sprintf("%s/views/%s/pdf%s", api.siteUrl(siteId), viewId, opts.query(params))
+	_, err := api.download(url, w)
+	return err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_view_data
+//writes the view's summary data to w as CSV
+func (api *API) DownloadViewData(siteId SiteID, viewId ViewID, opts ViewExportOptions, w io.Writer) error {
+	if err := api.requireVersion("DownloadViewData"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/views/%s/data%s", api.siteUrl(siteId), viewId, opts.query(neturl.Values{}))
+	_, err := api.download(url, w)
+	return err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#delete_workbook
+func (api *API) DeleteWorkbook(siteId SiteID, workbookId WorkbookID) error {
+	url := fmt.Sprintf("%s/workbooks/%s", api.siteUrl(siteId), workbookId)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_permissions.htm#query_workbook_permissions
+func (api *API) QueryWorkbookPermissions(siteId SiteID, workbookId WorkbookID) (Permissions, error) {
+	url := fmt.Sprintf("%s/workbooks/%s/permissions", api.siteUrl(siteId), workbookId)
+	return api.queryPermissions(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_permissions.htm#query_project_permissions
+func (api *API) QueryProjectPermissions(siteId SiteID, projectId ProjectID) (Permissions, error) {
+	url := fmt.Sprintf("%s/projects/%s/permissions", api.siteUrl(siteId), projectId)
+	return api.queryPermissions(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_permissions.htm#add_data_source_permissions
+func (api *API) AddDatasourcePermissions(siteId SiteID, datasourceId DatasourceID, grants []GranteeCapabilities) (Permissions, error) {
+	url := fmt.Sprintf("%s/datasources/%s/permissions", api.siteUrl(siteId), datasourceId)
+	return api.addPermissions(url, grants)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_permissions.htm#add_workbook_permissions
+func (api *API) AddWorkbookPermissions(siteId SiteID, workbookId WorkbookID, grants []GranteeCapabilities) (Permissions, error) {
+	url := fmt.Sprintf("%s/workbooks/%s/permissions", api.siteUrl(siteId), workbookId)
+	return api.addPermissions(url, grants)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_permissions.htm#add_project_permissions
+func (api *API) AddProjectPermissions(siteId SiteID, projectId ProjectID, grants []GranteeCapabilities) (Permissions, error) {
+	url := fmt.Sprintf("%s/projects/%s/permissions", api.siteUrl(siteId), projectId)
+	return api.addPermissions(url, grants)
+}
+
+func (api *API) addPermissions(url string, grants []GranteeCapabilities) (Permissions, error) {
+	for _, grant := range grants {
+		for _, capability := range grant.Capabilities.Capabilities {
+			if err := capability.Mode.Validate(); err != nil {
+				return Permissions{}, err
+			}
+		}
+	}
+	request := PermissionsRequest{Request: Permissions{GranteeCapabilities: grants}}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return Permissions{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := PermissionsResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Permissions, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#add_tags_to_data_source
+func (api *API) AddTagsToDatasource(siteId SiteID, datasourceId DatasourceID, tags []string) ([]Tag, error) {
+	url := fmt.Sprintf("%s/datasources/%s/tags", api.siteUrl(siteId), datasourceId)
+	return api.addTags(url, tags)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#add_tags_to_workbook
+func (api *API) AddTagsToWorkbook(siteId SiteID, workbookId WorkbookID, tags []string) ([]Tag, error) {
+	url := fmt.Sprintf("%s/workbooks/%s/tags", api.siteUrl(siteId), workbookId)
+	return api.addTags(url, tags)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#add_tags_to_flow
+func (api *API) AddTagsToFlow(siteId SiteID, flowId FlowID, tags []string) ([]Tag, error) {
+	if err := api.requireVersion("AddTagsToFlow"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/flows/%s/tags", api.siteUrl(siteId), flowId)
+	return api.addTags(url, tags)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#delete_tag_from_data_source
+func (api *API) DeleteTagFromDatasource(siteId SiteID, datasourceId DatasourceID, tag string) error {
+	url := fmt.Sprintf("%s/datasources/%s/tags/%s", api.siteUrl(siteId), datasourceId, neturl.PathEscape(tag))
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#delete_tag_from_workbook
+func (api *API) DeleteTagFromWorkbook(siteId SiteID, workbookId WorkbookID, tag string) error {
+	url := fmt.Sprintf("%s/workbooks/%s/tags/%s", api.siteUrl(siteId), workbookId, neturl.PathEscape(tag))
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#delete_tag_from_flow
+func (api *API) DeleteTagFromFlow(siteId SiteID, flowId FlowID, tag string) error {
+	if err := api.requireVersion("DeleteTagFromFlow"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/flows/%s/tags/%s", api.siteUrl(siteId), flowId, neturl.PathEscape(tag))
+	return api.delete(url)
+}
+
+//the server deletes one tag per call; failures are returned as a *MultiError keyed by tag
+func (api *API) DeleteTagsFromDatasource(siteId SiteID, datasourceId DatasourceID, tags []string) error {
+	return api.deleteTags(fmt.Sprintf("%s/datasources/%s/tags", api.siteUrl(siteId), datasourceId), tags)
+}
+
+//the server deletes one tag per call; failures are returned as a *MultiError keyed by tag
+func (api *API) DeleteTagsFromWorkbook(siteId SiteID, workbookId WorkbookID, tags []string) error {
+	return api.deleteTags(fmt.Sprintf("%s/workbooks/%s/tags", api.siteUrl(siteId), workbookId), tags)
+}
+
+//the server deletes one tag per call; failures are returned as a *MultiError keyed by tag
+func (api *API) DeleteTagsFromFlow(siteId SiteID, flowId FlowID, tags []string) error {
+	if err := api.requireVersion("DeleteTagFromFlow"); err != nil {
+		return err
+	}
+	return api.deleteTags(fmt.Sprintf("%s/flows/%s/tags", api.siteUrl(siteId), flowId), tags)
+}
+
+func (api *API) deleteTags(url string, tags []string) error {
+	result := &MultiError{}
+	for _, tag := range tags {
+		result.record(tag, api.delete(url+"/"+neturl.PathEscape(tag)))
+	}
+	return result.err()
+}
+
+func (api *API) addTags(url string, labels []string) ([]Tag, error) {
+	tags := Tags{}
+	for _, label := range labels {
+		tags.Tags = append(tags.Tags, Tag{Label: label})
+	}
+	request := TagsRequest{Request: tags}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := TagsResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Tags.Tags, err
+}
+
+func (api *API) queryPermissions(url string) (Permissions, error) {
+	headers := make(map[string]string)
+	retval := PermissionsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Permissions, err
+}
+
+func (api *API) refresh(url string) (Job, error) {
+	payload, err := api.codec().Marshal(struct{}{})
+	if err != nil {
+		return Job{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := JobResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Job, err
+}
+
+type downloadKey struct{}
+
+// downloadTo returns the writer a successful response to a call made with ctx
+// is streamed to, or nil if its body is read into the Response.
+func downloadTo(ctx context.Context) io.Writer {
+	w, _ := ctx.Value(downloadKey{}).(io.Writer)
+	return w
+}
+
+// download streams the file at url into w as it arrives, so large files
+// aren't held in memory; MaxResponseBody still applies.
+func (api *API) download(url string, w io.Writer) (string, error) {
+	ctx := context.WithValue(api.context(), downloadKey{}, w)
+	resp, err := api.do(ctx, GET, url, nil, map[string]string{})
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == 404 {
+		return "", ErrDoesNotExist
+	}
+	if err := resp.Err(); err != nil {
+		return "", err
+	}
+	_, params, err := mime.ParseMediaType(resp.Header.Get(content_disposition_header))
+	if err != nil {
+		return "", nil
+	}
+	return params["filename"], nil
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#initiate_file_upload
+func (api *API) InitiateFileUpload(siteId SiteID) (FileUpload, error) {
+	url := fmt.Sprintf("%s/fileUploads", api.siteUrl(siteId))
+	headers := make(map[string]string)
+	retval := FileUploadResponse{}
+	err := api.makeRequest(url, POST, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.FileUpload, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#append_to_file_upload
+func (api *API) AppendToFileUpload(siteId SiteID, uploadSessionId string, chunk []byte) (FileUpload, error) {
+	url := fmt.Sprintf("%s/fileUploads/%s", api.siteUrl(siteId), uploadSessionId)
+	boundary, err := api.newBoundary(chunk)
+	if err != nil {
+		return FileUpload{}, err
+	}
+	header := fmt.Sprintf("--%s\r\n", boundary)
+	header += "Content-Disposition: name=\"request_payload\"\r\n"
+	header += "Content-Type: text/xml\r\n"
+	header += "\r\n"
+	header += fmt.Sprintf("\r\n--%s\r\n", boundary)
+	header += "Content-Disposition: name=\"tableau_file\"; filename=\"file\"\r\n"
+	header += "Content-Type: application/octet-stream\r\n"
+	header += "\r\n"
+	trailer := fmt.Sprintf("\r\n--%s--\r\n", boundary)
+	headers := make(map[string]string)
+	headers[content_type_header] = fmt.Sprintf("multipart/mixed; boundary=%s", boundary)
+	retval := FileUploadResponse{}
+	payload := newMultipartBody([]byte(header), chunk, []byte(trailer))
+	err = api.makeRequest(url, PUT, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.FileUpload, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#publish_workbook
+//publishes a workbook whose file was previously sent with InitiateFileUpload/AppendToFileUpload
+func (api *API) PublishWorkbookFromUpload(siteId SiteID, metadata Workbook, uploadSessionId string, workbookType string, overwrite bool) (*Workbook, error) {
+	url := fmt.Sprintf("%s/workbooks?uploadSessionId=%s&workbookType=%s&overwrite=%v", api.siteUrl(siteId), uploadSessionId, workbookType, overwrite)
+	request := WorkbookCreateRequest{Request: metadata}
+	xmlRepresentation, err := request.XML()
+	if err != nil {
+		return nil, err
+	}
+	boundary, err := api.newBoundary(xmlRepresentation)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("--%s\r\n", boundary)
+	header += "Content-Disposition: name=\"request_payload\"\r\n"
+	header += "Content-Type: text/xml\r\n"
+	header += "\r\n"
+	trailer := fmt.Sprintf("\r\n--%s--\r\n", boundary)
+	headers := make(map[string]string)
+	headers[content_type_header] = fmt.Sprintf("multipart/mixed; boundary=%s", boundary)
+	retval := WorkbookResponse{}
+	payload := newMultipartBody([]byte(header), xmlRepresentation, []byte(trailer))
+	err = api.makeRequest(url, POST, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Workbook, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#publish_data_source
+//publishes a datasource whose file was previously sent with InitiateFileUpload/AppendToFileUpload
+func (api *API) PublishDatasourceFromUpload(siteId SiteID, metadata Datasource, uploadSessionId string, datasourceType string, overwrite bool) (*Datasource, error) {
+	return api.publishDatasourceFromUpload(siteId, metadata, uploadSessionId, datasourceType, overwriteMode(overwrite))
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_publishing.htm#publish_data_source
+func (api *API) AppendDatasourceFromUpload(siteId SiteID, metadata Datasource, uploadSessionId string, datasourceType string) (*Datasource, error) {
+	return api.publishDatasourceFromUpload(siteId, metadata, uploadSessionId, datasourceType, PublishModeAppend)
+}
+
+func (api *API) publishDatasourceFromUpload(siteId SiteID, metadata Datasource, uploadSessionId string, datasourceType string, mode PublishMode) (*Datasource, error) {
+	if err := mode.Validate(); err != nil {
+		return nil, err
+	}
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/datasources?uploadSessionId=%s&datasourceType=%s&%s", api.siteUrl(siteId), uploadSessionId, datasourceType, mode.query())
+	// the content URL is derived from the name
+	metadata.ContentUrl = ""
+	request := DatasourceCreateRequest{Request: metadata}
+	xmlRepresentation, err := request.XML()
+	if err != nil {
+		return nil, err
+	}
+	boundary, err := api.newBoundary(xmlRepresentation)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("--%s\r\n", boundary)
+	header += "Content-Disposition: name=\"request_payload\"\r\n"
+	header += "Content-Type: text/xml\r\n"
+	header += "\r\n"
+	trailer := fmt.Sprintf("\r\n--%s--\r\n", boundary)
+	headers := make(map[string]string)
+	headers[content_type_header] = fmt.Sprintf("multipart/mixed; boundary=%s", boundary)
+	retval := DatasourceResponse{}
+	payload := newMultipartBody([]byte(header), xmlRepresentation, []byte(trailer))
+	err = api.makeRequest(url, POST, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Datasource, err
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Datasource%3FTocPath%3DAPI%2520Reference%7C_____15
+func (api *API) DeleteDatasource(siteId SiteID, datasourceId DatasourceID) error {
+	url := fmt.Sprintf("%s/datasources/%s", api.siteUrl(siteId), datasourceId)
+	return api.delete(url)
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Project%3FTocPath%3DAPI%2520Reference%7C_____17
+func (api *API) DeleteProject(siteId SiteID, projectId ProjectID) error {
+	url := fmt.Sprintf("%s/projects/%s", api.siteUrl(siteId), projectId)
+	return api.delete(url)
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Project%3FTocPath%3DAPI%2520Reference%7C_____17
+func (api *API) DeleteSite(siteId SiteID) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s", api.Server, api.Version, siteId)
+	return api.delete(url)
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Site%3FTocPath%3DAPI%2520Reference%7C_____19
+func (api *API) DeleteSiteByName(name string) error {
+	return api.deleteSiteByKey("name", name)
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Site%3FTocPath%3DAPI%2520Reference%7C_____19
+func (api *API) DeleteSiteByContentUrl(contentUrl string) error {
+	return api.deleteSiteByKey("contentUrl", contentUrl)
+}
+
+//http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Site%3FTocPath%3DAPI%2520Reference%7C_____19
+func (api *API) deleteSiteByKey(key string, value string) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s?key=%s", api.Server, api.Version, value, key)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#query_flows_for_site
+func (api *API) QueryFlows(siteId SiteID, opts ListOptions) ([]Flow, Pagination, error) {
+	if err := api.requireVersion("QueryFlows"); err != nil {
+		return nil, Pagination{}, err
+	}
+	return ListPage[Flow, QueryFlowsResponse](api, siteId, "flows", opts)
+}
+
+func (api *API) queryAllFlows(siteId SiteID, opts ListOptions) ([]Flow, error) {
+	if err := api.requireVersion("QueryFlows"); err != nil {
+		return nil, err
+	}
+	return List[Flow, QueryFlowsResponse](api, siteId, "flows", opts)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#update_flow
+func (api *API) UpdateFlow(siteId SiteID, flow Flow) (*Flow, error) {
+	if err := api.requireVersion("UpdateFlow"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/flows/%s", api.siteUrl(siteId), flow.ID)
+	update := flow
+	update.ID = ""
+	payload, err := api.codec().Marshal(FlowRequest{Request: update})
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := FlowResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Flow, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#download_flow
+//streams the flow file to w as it arrives and returns its file name, whose extension tells tfl from tflx
+func (api *API) DownloadFlow(siteId SiteID, flowId FlowID, w io.Writer) (string, error) {
+	if err := api.requireVersion("DownloadFlow"); err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("%s/flows/%s/content", api.siteUrl(siteId), flowId)
+	return api.download(url, w)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#delete_flow
+func (api *API) DeleteFlow(siteId SiteID, flowId FlowID) error {
+	if err := api.requireVersion("DeleteFlow"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/flows/%s", api.siteUrl(siteId), flowId)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#query_groups
+func (api *API) QueryGroups(siteId SiteID, opts ListOptions) ([]Group, Pagination, error) {
+	return ListPage[Group, QueryGroupsResponse](api, siteId, "groups", opts)
+}
+
+func (api *API) queryAllGroups(siteId SiteID, opts ListOptions) ([]Group, error) {
+	return List[Group, QueryGroupsResponse](api, siteId, "groups", opts)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#create_group
+func (api *API) CreateGroup(siteId SiteID, group Group) (*Group, error) {
+	return api.CreateGroupWithOptions(siteId, group, CreateOptions{})
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#create_group
+//with IfExistsReturn a name already taken returns that group
+func (api *API) CreateGroupWithOptions(siteId SiteID, group Group, opts CreateOptions) (*Group, error) {
+	if err := opts.IfExists.Validate(); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/groups", api.siteUrl(siteId))
+	createGroupRequest := CreateGroupRequest{Request: group}
+	xmlRep, err := api.codec().Marshal(createGroupRequest)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := GroupResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	if isConflict(err) && opts.IfExists == IfExistsReturn {
+		return api.existingGroup(siteId, group.Name, err)
+	}
+	return &retval.Group, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#delete_group
+func (api *API) DeleteGroup(siteId SiteID, groupId GroupID) error {
+	url := fmt.Sprintf("%s/groups/%s", api.siteUrl(siteId), groupId)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#get_users_in_group
+//Tableau can't filter group members by role, so opts.SiteRole is applied to each page after it
+//is fetched; pages may therefore hold fewer users than the requested page size
+func (api *API) GetUsersInGroup(siteId SiteID, groupId GroupID, opts GroupUsersOptions) ([]User, Pagination, error) {
+	page, pagination, err := ListPage[User, GetUsersInGroupResponse](api, siteId, fmt.Sprintf("groups/%s/users", groupId), opts.ListOptions)
+	users := page
+	if len(opts.SiteRole) > 0 {
+		users = []User{}
+		for _, user := range page {
+			if user.SiteRole == opts.SiteRole {
+				users = append(users, user)
+			}
+		}
+	}
+	return users, pagination, err
+}
+
+func (api *API) queryAllUsersInGroup(siteId SiteID, groupId GroupID) ([]User, error) {
+	return List[User, GetUsersInGroupResponse](api, siteId, fmt.Sprintf("groups/%s/users", groupId), ListOptions{})
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#add_user_to_group
+func (api *API) AddUserToGroup(siteId SiteID, groupId GroupID, userId UserID) (User, error) {
+	url := fmt.Sprintf("%s/groups/%s/users", api.siteUrl(siteId), groupId)
+	request := AddUserToGroupRequest{Request: User{ID: userId}}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return User{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := QueryUserOnSiteResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.User, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#remove_user_to_group
+func (api *API) RemoveUserFromGroup(siteId SiteID, groupId GroupID, userId UserID) error {
+	url := fmt.Sprintf("%s/groups/%s/users/%s", api.siteUrl(siteId), groupId, userId)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#create_group_set
+//group sets require API 3.22 or later
+func (api *API) CreateGroupSet(siteId SiteID, groupSet GroupSet) (*GroupSet, error) {
+	if err := api.requireVersion("CreateGroupSet"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/groupsets", api.siteUrl(siteId))
+	request := GroupSetRequest{Request: groupSet}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := GroupSetResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.GroupSet, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#list_group_sets
+func (api *API) QueryGroupSets(siteId SiteID, opts ListOptions) ([]GroupSet, Pagination, error) {
+	if err := api.requireVersion("QueryGroupSets"); err != nil {
+		return nil, Pagination{}, err
+	}
+	url := fmt.Sprintf("%s/groupsets%s", api.siteUrl(siteId), opts.query())
+	headers := make(map[string]string)
+	retval := QueryGroupSetsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.GroupSets.GroupSets, retval.Pagination, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#get_group_set
+//the returned group set lists its member groups
+func (api *API) QueryGroupSet(siteId SiteID, groupSetId GroupSetID) (GroupSet, error) {
+	if err := api.requireVersion("QueryGroupSet"); err != nil {
+		return GroupSet{}, err
+	}
+	url := fmt.Sprintf("%s/groupsets/%s", api.siteUrl(siteId), groupSetId)
+	headers := make(map[string]string)
+	retval := GroupSetResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.GroupSet, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#update_group_set
+//updates the name and, where set, the license mode and minimum site role granted to members
+func (api *API) UpdateGroupSet(siteId SiteID, groupSet GroupSet) (*GroupSet, error) {
+	if err := api.requireVersion("UpdateGroupSet"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/groupsets/%s", api.siteUrl(siteId), groupSet.ID)
+	request := GroupSetRequest{Request: GroupSet{Name: groupSet.Name, GrantLicenseMode: groupSet.GrantLicenseMode, SiteRole: groupSet.SiteRole}}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := GroupSetResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.GroupSet, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#delete_group_set
+func (api *API) DeleteGroupSet(siteId SiteID, groupSetId GroupSetID) error {
+	if err := api.requireVersion("DeleteGroupSet"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/groupsets/%s", api.siteUrl(siteId), groupSetId)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#add_group_to_group_set
+func (api *API) AddGroupToGroupSet(siteId SiteID, groupSetId GroupSetID, groupId GroupID) error {
+	if err := api.requireVersion("AddGroupToGroupSet"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/groupsets/%s/groups/%s", api.siteUrl(siteId), groupSetId, groupId)
+	headers := make(map[string]string)
+	return api.makeRequest(url, PUT, nil, nil, headers, connectTimeOut, readWriteTimeout)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_users_and_groups.htm#remove_group_from_group_set
+func (api *API) RemoveGroupFromGroupSet(siteId SiteID, groupSetId GroupSetID, groupId GroupID) error {
+	if err := api.requireVersion("RemoveGroupFromGroupSet"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/groupsets/%s/groups/%s", api.siteUrl(siteId), groupSetId, groupId)
+	return api.delete(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_server.htm#update_server_active_directory_domain
+//renames the domain identified by domain.ID; requires a server administrator
+func (api *API) UpdateServerADDomain(domain Domain) (Domain, error) {
+	if err := api.requireVersion("UpdateServerADDomain"); err != nil {
+		return Domain{}, err
+	}
+	url := fmt.Sprintf("%s/api/%s/domains", api.Server, api.Version)
+	request := DomainRequest{Request: domain}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return Domain{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := DomainResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Domain, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_server.htm#list_mobile_security_settings_for_server
+//requires a server administrator
+func (api *API) QueryServerMobileSecuritySettings() ([]MobileSecuritySetting, error) {
+	if err := api.requireVersion("QueryServerMobileSecuritySettings"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/api/%s/settings/mobilesecuritysettings", api.Server, api.Version)
+	return api.queryMobileSecuritySettings(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#list_mobile_security_settings_for_site
+func (api *API) QuerySiteMobileSecuritySettings(siteId SiteID) ([]MobileSecuritySetting, error) {
+	if err := api.requireVersion("QuerySiteMobileSecuritySettings"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/mobilesecuritysettings", api.siteUrl(siteId))
+	return api.queryMobileSecuritySettings(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#update_mobile_security_settings_for_site
+func (api *API) UpdateSiteMobileSecuritySettings(siteId SiteID, settings []MobileSecuritySetting) ([]MobileSecuritySetting, error) {
+	if err := api.requireVersion("UpdateSiteMobileSecuritySettings"); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/mobilesecuritysettings", api.siteUrl(siteId))
+	request := MobileSecuritySettingsRequest{Request: MobileSecuritySettings{Settings: settings}}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := MobileSecuritySettingsResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Settings.Settings, err
+}
+
+func (api *API) queryMobileSecuritySettings(url string) ([]MobileSecuritySetting, error) {
+	headers := make(map[string]string)
+	retval := MobileSecuritySettingsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Settings.Settings, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#get_embedding_settings_for_site
+func (api *API) QueryEmbeddingSettings(siteId SiteID) (EmbeddingSettings, error) {
+	if err := api.requireVersion("QueryEmbeddingSettings"); err != nil {
+		return EmbeddingSettings{}, err
+	}
+	url := fmt.Sprintf("%s/settings/embedding", api.siteUrl(siteId))
+	headers := make(map[string]string)
+	retval := EmbeddingSettingsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Settings, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#update_embedding_settings_for_site
+func (api *API) UpdateEmbeddingSettings(siteId SiteID, settings EmbeddingSettings) (EmbeddingSettings, error) {
+	if err := api.requireVersion("UpdateEmbeddingSettings"); err != nil {
+		return EmbeddingSettings{}, err
+	}
+	url := fmt.Sprintf("%s/settings/embedding", api.siteUrl(siteId))
+	request := EmbeddingSettingsRequest{Request: settings}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return EmbeddingSettings{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := EmbeddingSettingsResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Settings, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_tableau_extensions_settings.htm#get_tableau_extensions_server_settings
+//requires a server administrator
+func (api *API) QueryServerExtensionsSettings() (ExtensionsServerSettings, error) {
+	if err := api.requireVersion("QueryServerExtensionsSettings"); err != nil {
+		return ExtensionsServerSettings{}, err
+	}
+	url := fmt.Sprintf("%s/api/%s/settings/extensions", api.Server, api.Version)
+	headers := make(map[string]string)
+	retval := ExtensionsServerSettingsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Settings, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_tableau_extensions_settings.htm#update_tableau_extensions_server_settings
+func (api *API) UpdateServerExtensionsSettings(settings ExtensionsServerSettings) (ExtensionsServerSettings, error) {
+	if err := api.requireVersion("UpdateServerExtensionsSettings"); err != nil {
+		return ExtensionsServerSettings{}, err
+	}
+	url := fmt.Sprintf("%s/api/%s/settings/extensions", api.Server, api.Version)
+	request := ExtensionsServerSettingsRequest{Request: settings}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return ExtensionsServerSettings{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := ExtensionsServerSettingsResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Settings, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_tableau_extensions_settings.htm#get_tableau_extensions_site_settings
+func (api *API) QuerySiteExtensionsSettings(siteId SiteID) (ExtensionsSiteSettings, error) {
+	if err := api.requireVersion("QuerySiteExtensionsSettings"); err != nil {
+		return ExtensionsSiteSettings{}, err
+	}
+	url := fmt.Sprintf("%s/settings/extensions", api.siteUrl(siteId))
+	headers := make(map[string]string)
+	retval := ExtensionsSiteSettingsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Settings, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_tableau_extensions_settings.htm#update_tableau_extensions_site_settings
+//the safe list sent replaces the site's current safe list
+func (api *API) UpdateSiteExtensionsSettings(siteId SiteID, settings ExtensionsSiteSettings) (ExtensionsSiteSettings, error) {
+	if err := api.requireVersion("UpdateSiteExtensionsSettings"); err != nil {
+		return ExtensionsSiteSettings{}, err
+	}
+	url := fmt.Sprintf("%s/settings/extensions", api.siteUrl(siteId))
+	request := ExtensionsSiteSettingsRequest{Request: settings}
+	xmlRep, err := api.codec().Marshal(request)
+	if err != nil {
+		return ExtensionsSiteSettings{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := ExtensionsSiteSettingsResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(xmlRep), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Settings, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#query_jobs
+//filter on status, jobType, createdAt, startedAt or endedAt, e.g. "status:eq:Failed,jobType:eq:refresh_extracts"
+func (api *API) QueryJobs(siteId SiteID, opts ListOptions) ([]BackgroundJob, Pagination, error) {
+	if err := api.requireVersion("QueryJobs"); err != nil {
+		return nil, Pagination{}, err
+	}
+	return ListPage[BackgroundJob, QueryJobsResponse](api, siteId, "jobs", opts)
+}
+
+func (api *API) queryAllJobs(siteId SiteID, opts ListOptions) ([]BackgroundJob, error) {
+	if err := api.requireVersion("QueryJobs"); err != nil {
+		return nil, err
+	}
+	return List[BackgroundJob, QueryJobsResponse](api, siteId, "jobs", opts)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#cancel_job
+//only pending and running jobs can be cancelled
+func (api *API) CancelJob(siteId SiteID, jobId JobID) error {
+	if err := api.requireVersion("CancelJob"); err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/jobs/%s", api.siteUrl(siteId), jobId)
+	headers := make(map[string]string)
+	return api.makeRequest(url, PUT, nil, nil, headers, connectTimeOut, readWriteTimeout)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#query_job
+func (api *API) QueryJob(siteId SiteID, jobId JobID) (Job, error) {
+	url := fmt.Sprintf("%s/jobs/%s", api.siteUrl(siteId), jobId)
+	headers := make(map[string]string)
+	retval := JobResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Job, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#run_extract_refresh_task
+func (api *API) RunExtractRefreshTask(siteId SiteID, taskId string) (Job, error) {
+	url := fmt.Sprintf("%s/tasks/extractRefreshes/%s/runNow", api.siteUrl(siteId), taskId)
+	return api.refresh(url)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#query_schedules
+//schedules are server wide, so this needs a server administrator
+func (api *API) QuerySchedules(opts ListOptions) ([]Schedule, Pagination, error) {
+	if err := api.requireVersion("QuerySchedules"); err != nil {
+		return nil, Pagination{}, err
+	}
+	return listPageAt[Schedule, QuerySchedulesResponse](api, fmt.Sprintf("%s/api/%s/schedules", api.Server, api.Version), opts)
+}
+
+func (api *API) queryAllSchedules(opts ListOptions) ([]Schedule, error) {
+	if err := api.requireVersion("QuerySchedules"); err != nil {
+		return nil, err
+	}
+	return listAt[Schedule, QuerySchedulesResponse](api, fmt.Sprintf("%s/api/%s/schedules", api.Server, api.Version), opts)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#create_schedule
+//schedules are server wide, so this needs a server administrator
+func (api *API) CreateSchedule(schedule Schedule) (Schedule, error) {
+	if err := api.requireVersion("CreateSchedule"); err != nil {
+		return Schedule{}, err
+	}
+	if err := schedule.Type.Validate(); err != nil {
+		return Schedule{}, err
+	}
+	if err := schedule.Frequency.Validate(); err != nil {
+		return Schedule{}, err
+	}
+	url := fmt.Sprintf("%s/api/%s/schedules", api.Server, api.Version)
+	payload, err := api.codec().Marshal(CreateScheduleRequest{Request: schedule})
+	if err != nil {
+		return Schedule{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := ScheduleResponse{}
+	err = api.makeRequest(url, POST, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Schedule, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#list_extract_refresh_tasks1
+func (api *API) QueryExtractRefreshTasks(siteId SiteID) ([]ExtractRefreshTask, error) {
+	url := fmt.Sprintf("%s/tasks/extractRefreshes", api.siteUrl(siteId))
+	headers := make(map[string]string)
+	retval := QueryTasksResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	tasks := []ExtractRefreshTask{}
+	for _, task := range retval.Tasks.Tasks {
+		if task.ExtractRefresh != nil {
+			tasks = append(tasks, *task.ExtractRefresh)
+		}
+	}
+	return tasks, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#update_schedule
+func (api *API) UpdateSchedule(schedule Schedule) (Schedule, error) {
+	if err := api.requireVersion("UpdateSchedule"); err != nil {
+		return Schedule{}, err
+	}
+	if len(schedule.State) > 0 {
+		if err := schedule.State.Validate(); err != nil {
+			return Schedule{}, err
+		}
+	}
+	url := fmt.Sprintf("%s/api/%s/schedules/%s", api.Server, api.Version, schedule.ID)
+	update := schedule
+	update.ID = ""
+	payload, err := api.codec().Marshal(UpdateScheduleRequest{Request: update})
+	if err != nil {
+		return Schedule{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := ScheduleResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Schedule, err
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#add_data_source_to_schedule
+func (api *API) AddDatasourceToSchedule(siteId SiteID, scheduleId ScheduleID, datasourceId DatasourceID) (ExtractRefreshTask, error) {
+	if err := api.requireVersion("AddDatasourceToSchedule"); err != nil {
+		return ExtractRefreshTask{}, err
+	}
+	url := fmt.Sprintf("%s/schedules/%s/datasources", api.siteUrl(siteId), scheduleId)
+	task := Task{ExtractRefresh: &ExtractRefreshTask{Datasource: &Datasource{ID: datasourceId}}}
+	return api.addToSchedule(url, task)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#add_workbook_to_schedule
+func (api *API) AddWorkbookToSchedule(siteId SiteID, scheduleId ScheduleID, workbookId WorkbookID) (ExtractRefreshTask, error) {
+	if err := api.requireVersion("AddWorkbookToSchedule"); err != nil {
+		return ExtractRefreshTask{}, err
+	}
+	url := fmt.Sprintf("%s/schedules/%s/workbooks", api.siteUrl(siteId), scheduleId)
+	task := Task{ExtractRefresh: &ExtractRefreshTask{Workbook: &Workbook{ID: workbookId}}}
+	return api.addToSchedule(url, task)
+}
+
+func (api *API) addToSchedule(url string, task Task) (ExtractRefreshTask, error) {
+	payload, err := api.codec().Marshal(AddToScheduleRequest{Request: task})
+	if err != nil {
+		return ExtractRefreshTask{}, err
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = api.codec().ContentType()
+	retval := TaskResponse{}
+	err = api.makeRequest(url, PUT, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	if retval.Task.ExtractRefresh == nil {
+		return ExtractRefreshTask{}, err
+	}
+	return *retval.Task.ExtractRefresh, err
+}
+
+// siteUrl returns the base url for site scoped calls. An empty siteId means the
+// site the client is signed in to.
+func (api *API) siteUrl(siteId SiteID) string {
+	if len(siteId) == 0 {
+		siteId = api.CurrentSite().ID
+	}
+	return fmt.Sprintf("%s/api/%s/sites/%s", api.Server, api.Version, siteId)
+}
+
+func (api *API) delete(url string) error {
+	headers := make(map[string]string)
+	return api.makeRequest(url, DELETE, nil, nil, headers, connectTimeOut, readWriteTimeout)
+}
+
+// makeRequest sends the request and, if the server rejects the auth token and
+// a CredentialProvider is configured, signs in again and retries once. The
+// body is streamed; it is sent with a Content-Length when bodyLength knows
+// it, and can only be retried when it is an io.Seeker. With Maintenance set,
+// a server in maintenance is waited for and the request sent again.
+func (api *API) makeRequest(requestUrl string, method string, body io.Reader, result interface{}, headers map[string]string,
+	cTimeout time.Duration, rwTimeout time.Duration) error {
+	rewind := rewinder(body)
+	token := api.authToken()
+	err := api.sendRequest(requestUrl, method, body, result, headers, cTimeout, rwTimeout)
+	var maintenance *MaintenanceError
+	for api.Maintenance != nil && rewind != nil && errors.As(err, &maintenance) {
+		if waitErr := api.waitForServer(maintenance); waitErr != nil {
+			return waitErr
+		}
+		if rewindErr := rewind(); rewindErr != nil {
+			return rewindErr
+		}
+		err = api.sendRequest(requestUrl, method, body, result, headers, cTimeout, rwTimeout)
+	}
+	if api.CredentialProvider != nil && isUnauthorized(err) && rewind != nil {
+		if signinErr := api.resignin(token); signinErr != nil {
+			return signinErr
+		}
+		if rewindErr := rewind(); rewindErr != nil {
+			return rewindErr
+		}
+		err = api.sendRequest(requestUrl, method, body, result, headers, cTimeout, rwTimeout)
+	}
+	return err
+}
+
+func (api *API) sendRequest(requestUrl string, method string, body io.Reader, result interface{}, headers map[string]string,
+	cTimeout time.Duration, rwTimeout time.Duration) error {
+	codec := api.codec()
+	if _, ok := headers[accept_header]; !ok && codec.ContentType() != application_xml_content_type {
+		headers[accept_header] = codec.ContentType()
+	}
+	resp, err := api.roundTrip(api.context(), method, requestUrl, body, headers)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == 404 {
+		tErrorResponse := ErrorResponse{}
+		if codec.Unmarshal(resp.Body, &tErrorResponse) == nil && tErrorResponse.Error.Code == site_not_found_code {
+			api.lifecycle().forgetSite(requestUrl)
+		}
+		return ErrDoesNotExist
+	}
+	if isMaintenance(resp) {
+		return newMaintenanceError(resp)
+	}
+	if isHTML(resp) {
+		return newNonAPIResponseError(resp)
+	}
+	if resp.StatusCode >= 300 {
+		tErrorResponse := ErrorResponse{}
+		err := codec.Unmarshal(resp.Body, &tErrorResponse)
+		if err != nil || len(tErrorResponse.Error.Code) == 0 {
+			return newNonAPIResponseError(resp)
+		}
+		return tErrorResponse.Error
+	}
+	if result != nil && len(resp.Body) > 0 {
+		// else unmarshall to the result type specified by caller
+		err := codec.Unmarshal(resp.Body, result)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roundTrip sends a single authenticated request and reads the whole response,
+// whatever its status.
+func (api *API) roundTrip(ctx context.Context, method string, requestUrl string, body io.Reader, headers map[string]string) (*Response, error) {
+	ctx, done, beginErr := api.lifecycle().begin(ctx)
+	if beginErr != nil {
+		return nil, beginErr
+	}
+	defer done()
+	if !isMutating(method, requestUrl) {
+		resp, err := api.send(ctx, method, requestUrl, body, headers)
+		api.touchSession(resp)
+		return resp, err
+	}
+	var resp *Response
+	var err error
+	digest := sha256.New()
+	if api.DryRun != nil {
+		// nothing is sent, so the plan has to keep the payload itself
+		var payload []byte
+		if body != nil {
+			if payload, err = io.ReadAll(body); err != nil {
+				return nil, err
+			}
+		}
+		digest.Write(payload)
+		api.DryRun.record(method, requestUrl, payload)
+		resp = &Response{StatusCode: http.StatusNoContent, Header: http.Header{}}
+	} else {
+		if api.Auditor != nil && body != nil {
+			// digest the payload as it is sent rather than holding it
+			body = sizedReader{Reader: io.TeeReader(body, digest), size: bodyLength(body)}
+		}
+		resp, err = api.send(ctx, method, requestUrl, body, headers)
+		api.touchSession(resp)
+	}
+	if api.Auditor != nil {
+		api.audit(ctx, method, requestUrl, digest.Sum(nil), resp, err)
+	}
+	return resp, err
+}
+
+// signing in and out doesn't change anything on the server
+func isMutating(method string, requestUrl string) bool {
+	return method != GET && !strings.Contains(requestUrl, "/auth/")
+}
+
+func (api *API) transmit(ctx context.Context, method string, requestUrl string, body io.Reader, headers map[string]string) (*Response, error) {
+	var debug = false
+	if debug {
+		fmt.Printf("%s:%v\n", method, requestUrl)
+	}
+	client, err := api.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	requestUrl, err = withQueryParams(ctx, strings.TrimSpace(requestUrl))
+	if err != nil {
+		return nil, err
+	}
+	maxRequest, maxResponse := api.bodyLimits(ctx)
+	length := bodyLength(body)
+	if maxRequest > 0 {
+		if length > maxRequest {
+			return nil, &BodyTooLargeError{Limit: maxRequest, Size: length}
+		}
+		if length < 0 {
+			body = &limitedBody{r: body, limit: maxRequest}
+		}
+	}
+	if err := api.spend(ctx, requestUrl); err != nil {
+		return nil, err
+	}
+	if api.Observer != nil {
+		ctx = api.Observer.Begin(ctx, method, requestUrl)
+	}
+	var req *http.Request
+	if length != 0 {
+		var httpErr error
+		req, httpErr = http.NewRequestWithContext(ctx, strings.TrimSpace(method), strings.TrimSpace(requestUrl), body)
 		if httpErr != nil {
-			return httpErr
+			api.observe(ctx, CallInfo{Method: method, URL: requestUrl}, httpErr)
+			return nil, httpErr
 		}
-		req.Header.Add(content_length_header, strconv.Itoa(len(payload)))
+		// -1 sends the body chunked
+		req.ContentLength = length
 	} else {
 		var httpErr error
-		req, httpErr = http.NewRequest(strings.TrimSpace(method), strings.TrimSpace(requestUrl), nil)
+		req, httpErr = http.NewRequestWithContext(ctx, strings.TrimSpace(method), strings.TrimSpace(requestUrl), nil)
 		if httpErr != nil {
-			return httpErr
+			api.observe(ctx, CallInfo{Method: method, URL: requestUrl}, httpErr)
+			return nil, httpErr
 		}
 	}
 	if headers != nil {
@@ -305,42 +2137,42 @@ func (api *API) makeRequest(requestUrl string, method string, payload []byte, re
 			req.Header.Add(header, headerValue)
 		}
 	}
-	if len(api.AuthToken) > 0 {
+	if token := api.authToken(); len(token) > 0 {
 		if debug {
-			fmt.Printf("%s:%s\n", auth_header, api.AuthToken)
+			fmt.Printf("%s:%s\n", auth_header, token)
+		}
+		req.Header.Add(auth_header, token)
+		if api.SessionCookie {
+			req.AddCookie(&http.Cookie{Name: session_cookie, Value: token})
 		}
-		req.Header.Add(auth_header, api.AuthToken)
 	}
 	var httpErr error
+	started := api.now()
 	resp, httpErr := client.Do(req)
 	if httpErr != nil {
-		return httpErr
+		api.observe(ctx, CallInfo{Method: method, URL: requestUrl, Duration: api.now().Sub(started)}, httpErr)
+		return nil, httpErr
 	}
 	defer resp.Body.Close()
-	body, readBodyError := ioutil.ReadAll(resp.Body)
+	if w := downloadTo(ctx); w != nil && resp.StatusCode < 300 {
+		written, copyErr := copyLimited(w, resp.Body, resp.ContentLength, maxResponse)
+		info := newCallInfo(method, requestUrl, api.now().Sub(started), resp, int(written))
+		recordCallInfo(ctx, info)
+		api.observe(ctx, info, copyErr)
+		if copyErr != nil {
+			return nil, copyErr
+		}
+		return &Response{StatusCode: resp.StatusCode, Header: resp.Header, codec: api.codec()}, nil
+	}
+	respBody, readBodyError := readLimited(resp.Body, resp.ContentLength, maxResponse)
+	info := newCallInfo(method, requestUrl, api.now().Sub(started), resp, len(respBody))
+	recordCallInfo(ctx, info)
+	api.observe(ctx, info, readBodyError)
 	if debug {
-		fmt.Printf("t4g Response:%v\n", string(body))
+		fmt.Printf("t4g Response:%v\n", string(respBody))
 	}
 	if readBodyError != nil {
-		return readBodyError
+		return nil, readBodyError
 	}
-	if resp.StatusCode == 404 {
-		return ErrDoesNotExist
-	}
-	if resp.StatusCode >= 300 {
-		tErrorResponse := ErrorResponse{}
-		err := xml.Unmarshal(body, &tErrorResponse)
-		if err != nil {
-			return err
-		}
-		return tErrorResponse.Error
-	}
-	if result != nil {
-		// else unmarshall to the result type specified by caller
-		err := xml.Unmarshal(body, &result)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody, codec: api.codec()}, nil
 }
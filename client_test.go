@@ -0,0 +1,161 @@
+package tableau4go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuerySites(t *testing.T) {
+	tests := []struct {
+		name     string
+		siteId   SiteID
+		handler  http.HandlerFunc
+		expected []SiteID
+		failed   bool
+	}{
+		{
+			name: "fetches every page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Query().Get("pageNumber") {
+				case "1":
+					fmt.Fprint(w, `<tsResponse><pagination pageNumber="1" pageSize="2" totalAvailable="3"/><sites><site id="a"/><site id="b"/></sites></tsResponse>`)
+				case "2":
+					fmt.Fprint(w, `<tsResponse><pagination pageNumber="2" pageSize="2" totalAvailable="3"/><sites><site id="c"/></sites></tsResponse>`)
+				default:
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			},
+			expected: []SiteID{"a", "b", "c"},
+		},
+		{
+			name:   "falls back to the signed in site when refused",
+			siteId: "mine",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/"+API_VERSION+"/sites" {
+					w.WriteHeader(http.StatusForbidden)
+					fmt.Fprint(w, `<tsResponse><error code="403014"><summary>Forbidden</summary></error></tsResponse>`)
+					return
+				}
+				fmt.Fprint(w, `<tsResponse><site id="mine"/></tsResponse>`)
+			},
+			expected: []SiteID{"mine"},
+		},
+		{
+			name: "reports the refusal when not signed in to a site",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `<tsResponse><error code="403014"><summary>Forbidden</summary></error></tsResponse>`)
+			},
+			expected: []SiteID{},
+			failed:   true,
+		},
+	}
+	for _, test := range tests {
+		server := httptest.NewServer(test.handler)
+		api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+		api.SiteID = test.siteId
+		sites, err := api.QuerySites(ListOptions{})
+		server.Close()
+		if (err != nil) != test.failed {
+			t.Errorf("%s: got error %v", test.name, err)
+			continue
+		}
+		ids := []SiteID{}
+		for _, site := range sites {
+			ids = append(ids, site.ID)
+		}
+		if !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("%s: got sites %v, expected %v", test.name, ids, test.expected)
+		}
+	}
+}
+
+func TestMakeRequestSignsInAgainOnUnauthorized(t *testing.T) {
+	signinFailed := errors.New("signin failed")
+	provider := CredentialProviderFunc(func() (Credentials, error) {
+		return Credentials{PersonalAccessTokenName: "name", PersonalAccessTokenSecret: "secret"}, nil
+	})
+	tests := []struct {
+		name     string
+		provider CredentialProvider
+		body     func() io.Reader
+		signin   int
+		bodies   []string
+		failed   bool
+	}{
+		{
+			name:     "resends a rewindable body with the new token",
+			provider: provider,
+			body:     func() io.Reader { return bytes.NewReader([]byte("<tsRequest/>")) },
+			signin:   1,
+			bodies:   []string{"<tsRequest/>", "<tsRequest/>"},
+		},
+		{
+			name:     "resends a multipart body",
+			provider: provider,
+			body:     func() io.Reader { return newMultipartBody([]byte("head"), []byte("content"), []byte("tail")) },
+			signin:   1,
+			bodies:   []string{"headcontenttail", "headcontenttail"},
+		},
+		{
+			name:   "fails without a CredentialProvider",
+			body:   func() io.Reader { return bytes.NewReader([]byte("<tsRequest/>")) },
+			bodies: []string{"<tsRequest/>"},
+			failed: true,
+		},
+		{
+			name:     "fails when the body can't be sent twice",
+			provider: provider,
+			body:     func() io.Reader { return io.MultiReader(strings.NewReader("<tsRequest/>")) },
+			bodies:   []string{"<tsRequest/>"},
+			failed:   true,
+		},
+		{
+			name:     "reports a failed sign in",
+			provider: CredentialProviderFunc(func() (Credentials, error) { return Credentials{}, signinFailed }),
+			body:     func() io.Reader { return bytes.NewReader([]byte("<tsRequest/>")) },
+			bodies:   []string{"<tsRequest/>"},
+			failed:   true,
+		},
+	}
+	for _, test := range tests {
+		signins := 0
+		bodies := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/"+API_VERSION+"/auth/signin" {
+				signins++
+				fmt.Fprint(w, `<tsResponse><credentials token="fresh"><site id="s" contentUrl=""/></credentials></tsResponse>`)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			if r.Header.Get(auth_header) != "fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `<tsResponse><error code="401002"><summary>Unauthorized</summary></error></tsResponse>`)
+				return
+			}
+			fmt.Fprint(w, `<tsResponse/>`)
+		}))
+		api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+		api.AuthToken = "expired"
+		api.CredentialProvider = test.provider
+		err := api.makeRequest(server.URL+"/api/"+API_VERSION+"/sites/s/projects", POST, test.body(), nil, map[string]string{}, connectTimeOut, readWriteTimeout)
+		server.Close()
+		if (err != nil) != test.failed {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+		if signins != test.signin {
+			t.Errorf("%s: signed in %d times, expected %d", test.name, signins, test.signin)
+		}
+		if !reflect.DeepEqual(bodies, test.bodies) {
+			t.Errorf("%s: server got bodies %q, expected %q", test.name, bodies, test.bodies)
+		}
+	}
+}
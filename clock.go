@@ -0,0 +1,57 @@
+package tableau4go
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"time"
+)
+
+// Clock is the client's source of time. Session expiry, retry backoff and
+// audit timestamps read it, so a test can replace it with a fake clock and
+// step through token expiry or retries without waiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (api *API) now() time.Time {
+	if api.Clock == nil {
+		return time.Now()
+	}
+	return api.Clock.Now()
+}
+
+// sleep waits for d on the client's Clock, or until ctx is done.
+func (api *API) sleep(ctx context.Context, d time.Duration) error {
+	clock := api.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clock.After(d):
+		return nil
+	}
+}
+
+// random returns the source of multipart boundaries and request IDs:
+// api.Random when set, crypto/rand otherwise. Credential sealing keys always
+// come from crypto/rand.
+func (api *API) random() io.Reader {
+	if api.Random == nil {
+		return rand.Reader
+	}
+	return api.Random
+}
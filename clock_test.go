@@ -0,0 +1,88 @@
+package tableau4go
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock stands still and returns from every wait at once, recording how
+// long it was asked to wait.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	fired := make(chan time.Time, 1)
+	fired <- c.now
+	return fired
+}
+
+// advance moves the clock on, as if d had passed between calls.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestWaitForJobWaitsOnClock(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls < 3 {
+			fmt.Fprint(w, `<tsResponse><job id="j" progress="50"/></tsResponse>`)
+			return
+		}
+		fmt.Fprint(w, `<tsResponse><job id="j" progress="100" finishCode="0" completedAt="2026-01-01T00:00:00Z"/></tsResponse>`)
+	}))
+	defer server.Close()
+	api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	api.Clock = clock
+
+	job, err := api.WaitForJob(context.Background(), "site", "j", time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Progress != 100 {
+		t.Errorf("got progress %d, expected 100", job.Progress)
+	}
+	if len(clock.waits) != 2 || clock.waits[0] != time.Hour || clock.waits[1] != time.Hour {
+		t.Errorf("got waits %v, expected two of an hour", clock.waits)
+	}
+}
+
+func TestBatchSchedulerUsesClockAndRandom(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	scheduler := NewBatchScheduler(nil, time.UTC)
+	scheduler.Clock = &fakeClock{now: now}
+	scheduler.Random = bytes.NewReader([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+
+	op, err := scheduler.Enqueue(QueuedOperation{Kind: "refresh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.ID != "0102030405060708" {
+		t.Errorf("got ID %s, expected 0102030405060708", op.ID)
+	}
+	if !op.EnqueuedAt.Equal(now) {
+		t.Errorf("got EnqueuedAt %v, expected %v", op.EnqueuedAt, now)
+	}
+}
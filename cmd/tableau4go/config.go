@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/groundfoundation/tableau4go"
+)
+
+// the API version used when a profile doesn't name one; signing in with a
+// personal access token needs 3.6 or later
+const default_api_version = "3.6"
+
+type config struct {
+	Profiles map[string]profile `json:"profiles"`
+}
+
+// profile holds everything needed to reach and sign in to one site. Either
+// tokenName and tokenSecretEnv or username and passwordEnv should be set.
+type profile struct {
+	Server         string `json:"server"`
+	APIVersion     string `json:"apiVersion"`
+	Site           string `json:"site"`
+	TokenName      string `json:"tokenName"`
+	TokenSecretEnv string `json:"tokenSecretEnv"`
+	Username       string `json:"username"`
+	PasswordEnv    string `json:"passwordEnv"`
+}
+
+func loadConfig(path string) (config, error) {
+	c := config{}
+	f, err := os.Open(path)
+	if err != nil {
+		return c, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&c); err != nil {
+		return c, fmt.Errorf("reading %s: %v", path, err)
+	}
+	return c, nil
+}
+
+func (p profile) api() *tableau4go.API {
+	version := p.APIVersion
+	if len(version) == 0 {
+		version = default_api_version
+	}
+	api := tableau4go.NewAPI(p.Server, version, tableau4go.BOUNDARY_STRING, "", true)
+	// secrets are looked up at sign in time so a rotated environment is honoured
+	api.CredentialProvider = tableau4go.CredentialProviderFunc(func() (tableau4go.Credentials, error) {
+		site := &tableau4go.Site{ContentUrl: p.Site}
+		if len(p.TokenName) > 0 {
+			return tableau4go.Credentials{PersonalAccessTokenName: p.TokenName, PersonalAccessTokenSecret: os.Getenv(p.TokenSecretEnv), Site: site}, nil
+		}
+		if len(p.Username) > 0 {
+			return tableau4go.Credentials{Name: p.Username, Password: os.Getenv(p.PasswordEnv), Site: site}, nil
+		}
+		return tableau4go.Credentials{}, fmt.Errorf("profile for %s has neither tokenName nor username", p.Server)
+	})
+	return &api
+}
@@ -0,0 +1,362 @@
+// Command tableau4go exposes common Tableau REST operations on the command line.
+//
+// Connection details live in named profiles in a JSON config file (by default
+// tableau4go/config.json under the user config directory):
+//
+//	{
+//	  "profiles": {
+//	    "default": {
+//	      "server": "https://tableau.example.com",
+//	      "apiVersion": "3.6",
+//	      "site": "marketing",
+//	      "tokenName": "automation",
+//	      "tokenSecretEnv": "TABLEAU_TOKEN_SECRET"
+//	    }
+//	  }
+//	}
+//
+// apiVersion may be left out, in which case 3.6 is used. Secrets are read
+// from the environment variables named by tokenSecretEnv or passwordEnv
+// rather than stored in the file. The session is cached next to the config
+// file so consecutive invocations reuse one sign in.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/groundfoundation/tableau4go"
+)
+
+const usage = `usage: tableau4go [-config file] [-profile name] <command> [arguments]
+
+commands:
+  signin                                     sign in and cache the session
+  signout                                    sign out and forget the session
+  sites                                      list sites
+  projects                                   list projects on the site
+  workbooks                                  list workbooks on the site
+  datasources                                list datasources on the site
+  publish [-project name] [-name name] [-overwrite] file
+                                             publish a .twb/.twbx/.tds/.tdsx/.hyper file
+  download (-workbook id | -datasource id) [-no-extract] [-o file]
+                                             download a workbook or datasource
+  refresh (-workbook id | -datasource id)    run an extract refresh now
+  permissions (-project id | -workbook id | -datasource id)
+                                             show explicit permissions
+`
+
+func main() {
+	defaultConfig := ""
+	if dir, err := os.UserConfigDir(); err == nil {
+		defaultConfig = filepath.Join(dir, "tableau4go", "config.json")
+	}
+	configPath := flag.String("config", defaultConfig, "config file")
+	profileName := flag.String("profile", "default", "profile to use")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*configPath, *profileName, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "tableau4go: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath, profileName, command string, args []string) error {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	profile, ok := config.Profiles[profileName]
+	if !ok {
+		return fmt.Errorf("no profile named %q in %s", profileName, configPath)
+	}
+	api := profile.api()
+	sessionPath := strings.TrimSuffix(configPath, filepath.Ext(configPath)) + "." + profileName + ".session"
+	switch command {
+	case "signin":
+		if err := api.SigninWithProvider(); err != nil {
+			return err
+		}
+		return saveSession(api, sessionPath)
+	case "signout":
+		if err := resumeSession(api, sessionPath); err != nil {
+			return err
+		}
+		err := api.Signout()
+		os.Remove(sessionPath)
+		return err
+	}
+	handler, ok := commands[command]
+	if !ok {
+		return fmt.Errorf("unknown command %q", command)
+	}
+	if err := resumeSession(api, sessionPath); err != nil {
+		return err
+	}
+	err = handler(api.Site(api.SiteID), args)
+	// the client signs in again by itself if the cached token was rejected
+	if saveErr := saveSession(api, sessionPath); err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+var commands = map[string]func(site tableau4go.SiteClient, args []string) error{
+	"sites":       listSites,
+	"projects":    listProjects,
+	"workbooks":   listWorkbooks,
+	"datasources": listDatasources,
+	"publish":     publish,
+	"download":    download,
+	"refresh":     refresh,
+	"permissions": permissions,
+}
+
+func resumeSession(api *tableau4go.API, path string) error {
+	f, err := os.Open(path)
+	if err == nil {
+		err = api.LoadSession(f)
+		f.Close()
+		if err == nil {
+			return nil
+		}
+	}
+	if err := api.SigninWithProvider(); err != nil {
+		return err
+	}
+	return saveSession(api, path)
+}
+
+func saveSession(api *tableau4go.API, path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := api.SaveSession(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func table(header string, rows func(w *tabwriter.Writer)) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	rows(w)
+	return w.Flush()
+}
+
+func listSites(site tableau4go.SiteClient, args []string) error {
+	sites, err := site.API().QuerySites(tableau4go.ListOptions{})
+	if err != nil {
+		return err
+	}
+	return table("ID\tNAME\tCONTENT URL\tSTATE", func(w *tabwriter.Writer) {
+		for _, s := range sites {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.ID, s.Name, s.ContentUrl, s.State)
+		}
+	})
+}
+
+func listProjects(site tableau4go.SiteClient, args []string) error {
+	projects, err := site.QueryProjects()
+	if err != nil {
+		return err
+	}
+	return table("ID\tNAME\tDESCRIPTION", func(w *tabwriter.Writer) {
+		for _, p := range projects {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", p.ID, p.Name, p.Description)
+		}
+	})
+}
+
+func listWorkbooks(site tableau4go.SiteClient, args []string) error {
+	workbooks, err := site.QueryWorkbooks()
+	if err != nil {
+		return err
+	}
+	return table("ID\tNAME\tPROJECT\tUPDATED", func(w *tabwriter.Writer) {
+		for _, wb := range workbooks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", wb.ID, wb.Name, projectName(wb.Project), wb.UpdatedAt)
+		}
+	})
+}
+
+func listDatasources(site tableau4go.SiteClient, args []string) error {
+	datasources, err := site.QueryDatasources()
+	if err != nil {
+		return err
+	}
+	return table("ID\tNAME\tTYPE\tPROJECT", func(w *tabwriter.Writer) {
+		for _, ds := range datasources {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ds.ID, ds.Name, ds.Type, projectName(ds.Project))
+		}
+	})
+}
+
+func projectName(p *tableau4go.Project) string {
+	if p == nil {
+		return ""
+	}
+	return p.Name
+}
+
+func publish(site tableau4go.SiteClient, args []string) error {
+	flags := flag.NewFlagSet("publish", flag.ExitOnError)
+	projectFlag := flags.String("project", "Default", "name of the target project")
+	nameFlag := flags.String("name", "", "name to publish as (defaults to the file name)")
+	overwrite := flags.Bool("overwrite", false, "replace existing content with the same name")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("publish needs exactly one file")
+	}
+	path := flags.Arg(0)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fileType := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	name := *nameFlag
+	if len(name) == 0 {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	project, err := site.GetProjectByName(*projectFlag)
+	if err != nil {
+		return err
+	}
+	switch fileType {
+	case "twb", "twbx":
+		wb, err := site.PublishWorkbook(tableau4go.Workbook{Name: name, Project: &tableau4go.Project{ID: project.ID}}, content, fileType, *overwrite)
+		if err != nil {
+			return err
+		}
+		fmt.Println(wb.ID)
+	case "tds", "tdsx", "hyper":
+		ds, err := site.PublishDatasource(tableau4go.Datasource{Name: name, Project: &tableau4go.Project{ID: project.ID}}, content, fileType, *overwrite)
+		if err != nil {
+			return err
+		}
+		fmt.Println(ds.ID)
+	default:
+		return fmt.Errorf("don't know how to publish %q files", fileType)
+	}
+	return nil
+}
+
+func download(site tableau4go.SiteClient, args []string) error {
+	flags := flag.NewFlagSet("download", flag.ExitOnError)
+	workbookId := flags.String("workbook", "", "workbook ID")
+	datasourceId := flags.String("datasource", "", "datasource ID")
+	noExtract := flags.Bool("no-extract", false, "leave out the extract")
+	output := flags.String("o", "", "output file (defaults to the server supplied name)")
+	flags.Parse(args)
+	tmp, err := os.CreateTemp(".", ".tableau4go-download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	var name string
+	switch {
+	case len(*workbookId) > 0:
+		name, err = site.DownloadWorkbook(tableau4go.WorkbookID(*workbookId), !*noExtract, tmp)
+	case len(*datasourceId) > 0:
+		name, err = site.DownloadDatasource(tableau4go.DatasourceID(*datasourceId), !*noExtract, tmp)
+	default:
+		err = errors.New("download needs -workbook or -datasource")
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if len(*output) > 0 {
+		return os.Rename(tmp.Name(), *output)
+	}
+	name, err = localName(name)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// localName turns the file name the server sent into one in the working
+// directory, so a name such as ../../.bashrc can't write anywhere else.
+func localName(name string) (string, error) {
+	if len(name) == 0 {
+		return "", errors.New("server did not name the file, use -o")
+	}
+	base := filepath.Base(name)
+	if base == "." || base == ".." || base == string(filepath.Separator) {
+		return "", fmt.Errorf("server sent unusable file name %q, use -o", name)
+	}
+	return base, nil
+}
+
+func refresh(site tableau4go.SiteClient, args []string) error {
+	flags := flag.NewFlagSet("refresh", flag.ExitOnError)
+	workbookId := flags.String("workbook", "", "workbook ID")
+	datasourceId := flags.String("datasource", "", "datasource ID")
+	flags.Parse(args)
+	var job tableau4go.Job
+	var err error
+	switch {
+	case len(*workbookId) > 0:
+		job, err = site.UpdateWorkbookNow(tableau4go.WorkbookID(*workbookId))
+	case len(*datasourceId) > 0:
+		job, err = site.UpdateDatasourceNow(tableau4go.DatasourceID(*datasourceId))
+	default:
+		err = errors.New("refresh needs -workbook or -datasource")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(job.ID)
+	return nil
+}
+
+func permissions(site tableau4go.SiteClient, args []string) error {
+	flags := flag.NewFlagSet("permissions", flag.ExitOnError)
+	projectId := flags.String("project", "", "project ID")
+	workbookId := flags.String("workbook", "", "workbook ID")
+	datasourceId := flags.String("datasource", "", "datasource ID")
+	flags.Parse(args)
+	api := site.API()
+	var perms tableau4go.Permissions
+	var err error
+	switch {
+	case len(*projectId) > 0:
+		perms, err = api.QueryProjectPermissions(site.ID, tableau4go.ProjectID(*projectId))
+	case len(*workbookId) > 0:
+		perms, err = api.QueryWorkbookPermissions(site.ID, tableau4go.WorkbookID(*workbookId))
+	case len(*datasourceId) > 0:
+		perms, err = api.QueryDatasourcePermissions(site.ID, tableau4go.DatasourceID(*datasourceId))
+	default:
+		err = errors.New("permissions needs -project, -workbook or -datasource")
+	}
+	if err != nil {
+		return err
+	}
+	return table("GRANTEE\tID\tCAPABILITY\tMODE", func(w *tabwriter.Writer) {
+		for _, grantee := range perms.GranteeCapabilities {
+			kind, id := "", ""
+			if grantee.Group != nil {
+				kind, id = "group", string(grantee.Group.ID)
+			} else if grantee.User != nil {
+				kind, id = "user", string(grantee.User.ID)
+			}
+			for _, c := range grantee.Capabilities.Capabilities {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", kind, id, c.Name, c.Mode)
+			}
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package tableau4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+)
+
+const accept_header = "Accept"
+
+// Codec encodes request bodies and decodes response bodies. Set API.Codec to
+// JSONCodec to talk JSON to the server; it defaults to XMLCodec. Publishing
+// and sign in always send XML, which the server accepts either way, but their
+// responses are decoded with the configured codec.
+type Codec interface {
+	ContentType() string
+	// Marshal encodes one of the *Request types
+	Marshal(request interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// XMLCodec wraps requests in a tsRequest element.
+type XMLCodec struct{}
+
+func (XMLCodec) ContentType() string {
+	return application_xml_content_type
+}
+
+func (XMLCodec) Marshal(request interface{}) ([]byte, error) {
+	if r, ok := request.(interface{ XML() ([]byte, error) }); ok {
+		return r.XML()
+	}
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "   ")
+	if err := encoder.EncodeElement(request, xml.StartElement{Name: xml.Name{Local: "tsRequest"}}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (XMLCodec) Unmarshal(data []byte, v interface{}) error {
+	return xml.Unmarshal(data, v)
+}
+
+// JSONCodec sends the request types as they are, which gives the JSON shape
+// the server expects since their json tags mirror the XML element names.
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string {
+	return application_json_content_type
+}
+
+func (JSONCodec) Marshal(request interface{}) ([]byte, error) {
+	return json.Marshal(request)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (api *API) codec() Codec {
+	if api.Codec == nil {
+		return XMLCodec{}
+	}
+	return api.Codec
+}
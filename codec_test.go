@@ -0,0 +1,125 @@
+package tableau4go
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// the server sends numbers and booleans as attributes in XML and as strings
+// in JSON; both have to decode to the same values
+func TestCodecsDecodeFixtures(t *testing.T) {
+	tests := []struct {
+		name     string
+		xml      string
+		json     string
+		decoded  func() interface{}
+		expected interface{}
+	}{
+		{
+			name:     "workbook",
+			xml:      `<workbook id="wb" showTabs="true" size="12"><usage totalViewCount="40" hitsTotal="7"/></workbook>`,
+			json:     `{"id":"wb","showTabs":"true","size":"12","usage":{"totalViewCount":"40","hitsTotal":"7"}}`,
+			decoded:  func() interface{} { return &Workbook{} },
+			expected: &Workbook{ID: "wb", ShowTabs: true, Size: 12, Usage: &Usage{TotalViewCount: 40, HitsTotal: 7}},
+		},
+		{
+			name:     "job",
+			xml:      `<job id="j" progress="50" finishCode="1"/>`,
+			json:     `{"id":"j","progress":"50","finishCode":"1"}`,
+			decoded:  func() interface{} { return &Job{} },
+			expected: &Job{ID: "j", Progress: 50, FinishCode: 1},
+		},
+		{
+			name:     "background job",
+			xml:      `<backgroundJob id="j" priority="50"/>`,
+			json:     `{"id":"j","priority":"50"}`,
+			decoded:  func() interface{} { return &BackgroundJob{} },
+			expected: &BackgroundJob{ID: "j", Priority: 50},
+		},
+		{
+			name:     "schedule",
+			xml:      `<schedule id="s" priority="20"/>`,
+			json:     `{"id":"s","priority":"20"}`,
+			decoded:  func() interface{} { return &Schedule{} },
+			expected: &Schedule{ID: "s", Priority: 20},
+		},
+		{
+			name:     "extract refresh task",
+			xml:      `<extractRefresh id="t" priority="10"/>`,
+			json:     `{"id":"t","priority":"10"}`,
+			decoded:  func() interface{} { return &ExtractRefreshTask{} },
+			expected: &ExtractRefreshTask{ID: "t", Priority: 10},
+		},
+		{
+			name:     "group set",
+			xml:      `<groupSet id="gs" groupCount="3"/>`,
+			json:     `{"id":"gs","groupCount":"3"}`,
+			decoded:  func() interface{} { return &GroupSet{} },
+			expected: &GroupSet{ID: "gs", GroupCount: 3},
+		},
+		{
+			name:     "site",
+			xml:      `<site id="s" storageQuota="100"/>`,
+			json:     `{"id":"s","storageQuota":"100"}`,
+			decoded:  func() interface{} { return &Site{} },
+			expected: &Site{ID: "s", StorageQuota: 100},
+		},
+		{
+			name:     "project",
+			xml:      `<project id="p" topLevelProject="true"/>`,
+			json:     `{"id":"p","topLevelProject":"true"}`,
+			decoded:  func() interface{} { return &Project{} },
+			expected: &Project{ID: "p", TopLevelProject: true},
+		},
+		{
+			name:     "datasource",
+			xml:      `<datasource id="d" isCertified="true"/>`,
+			json:     `{"id":"d","isCertified":"true"}`,
+			decoded:  func() interface{} { return &Datasource{} },
+			expected: &Datasource{ID: "d", IsCertified: true},
+		},
+		{
+			name:     "view",
+			xml:      `<view id="v" hidden="true"/>`,
+			json:     `{"id":"v","hidden":"true"}`,
+			decoded:  func() interface{} { return &View{} },
+			expected: &View{ID: "v", Hidden: true},
+		},
+	}
+	for _, test := range tests {
+		for _, c := range []struct {
+			codec Codec
+			data  string
+		}{{XMLCodec{}, test.xml}, {JSONCodec{}, test.json}} {
+			decoded := test.decoded()
+			if err := c.codec.Unmarshal([]byte(c.data), decoded); err != nil {
+				t.Errorf("%s %T: %v", test.name, c.codec, err)
+				continue
+			}
+			if !reflect.DeepEqual(decoded, test.expected) {
+				t.Errorf("%s %T: got %+v, expected %+v", test.name, c.codec, decoded, test.expected)
+			}
+		}
+	}
+}
+
+func TestResponseErrUsesCodec(t *testing.T) {
+	expected := Terror{Code: "409004", Summary: "Conflict", Detail: "Name taken"}
+	tests := []struct {
+		name  string
+		codec Codec
+		body  string
+	}{
+		{"xml", XMLCodec{}, `<tsResponse><error code="409004"><summary>Conflict</summary><detail>Name taken</detail></error></tsResponse>`},
+		{"json", JSONCodec{}, `{"error":{"code":"409004","summary":"Conflict","detail":"Name taken"}}`},
+	}
+	for _, test := range tests {
+		resp := &Response{StatusCode: http.StatusConflict, Header: http.Header{}, Body: []byte(test.body), codec: test.codec}
+		var terr Terror
+		if err := resp.Err(); !errors.As(err, &terr) || terr != expected {
+			t.Errorf("%s: got %v, expected %v", test.name, err, expected)
+		}
+	}
+}
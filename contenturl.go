@@ -0,0 +1,74 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidContentUrl = errors.New("Invalid Content URL")
+
+// max_content_url_length is the longest site content URL Tableau accepts
+const max_content_url_length = 255
+
+// ContentUrlError says why a content URL was rejected. It matches
+// ErrInvalidContentUrl.
+type ContentUrlError struct {
+	ContentUrl string
+	Reason     string
+}
+
+func (e *ContentUrlError) Error() string {
+	return fmt.Sprintf("Invalid Content URL '%s': %s", e.ContentUrl, e.Reason)
+}
+
+func (e *ContentUrlError) Unwrap() error {
+	return ErrInvalidContentUrl
+}
+
+// ValidateContentUrl checks a site content URL the way the server does, so a
+// bad one fails with a readable error instead of a bare 400. Content URLs may
+// only use ASCII letters, digits, hyphens and underscores and are at most 255
+// characters long. The empty content URL is the default site, which can't be
+// created or renamed to.
+func ValidateContentUrl(contentUrl string) error {
+	if len(contentUrl) == 0 {
+		return &ContentUrlError{ContentUrl: contentUrl, Reason: "empty content URL is reserved for the default site"}
+	}
+	if len(contentUrl) > max_content_url_length {
+		return &ContentUrlError{ContentUrl: contentUrl, Reason: fmt.Sprintf("longer than %d characters", max_content_url_length)}
+	}
+	for _, r := range contentUrl {
+		if !contentUrlRune(r) {
+			return &ContentUrlError{ContentUrl: contentUrl, Reason: fmt.Sprintf("'%c' is not allowed, only letters, digits, '-' or '_'", r)}
+		}
+	}
+	return nil
+}
+
+// NormalizeContentUrl derives a content URL from a site name: spaces are
+// dropped, other characters that aren't allowed are replaced with hyphens, and
+// the result is cut to the maximum length. The result can still be empty, so
+// validate it before use.
+func NormalizeContentUrl(name string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(name) {
+		switch {
+		case contentUrlRune(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+		default:
+			b.WriteByte('-')
+		}
+	}
+	normalized := b.String()
+	if len(normalized) > max_content_url_length {
+		normalized = normalized[:max_content_url_length]
+	}
+	return normalized
+}
+
+func contentUrlRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_')
+}
@@ -0,0 +1,54 @@
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// CredentialCheck is the outcome of VerifyDatasourceConnection. Working is
+// false when the refresh run with the new credentials failed; Notes then
+// holds the server's explanation, e.g. the database's login error.
+type CredentialCheck struct {
+	Connection Connection
+	Job        Job
+	Working    bool
+	Notes      string
+}
+
+// VerifyDatasourceConnection updates the connection, normally with new
+// embedded credentials, then refreshes the datasource's extract and waits for
+// the refresh, polling every interval, to find out whether the server can
+// actually sign in with them. A rejected password only shows up when the
+// refresh runs, so without this the connection is silently broken until the
+// next scheduled refresh fails.
+//
+// The error is only set when the check itself couldn't be done, e.g. the
+// update or refresh was refused or ctx ended; a failed refresh is reported
+// through the CredentialCheck. Live connections have no extract to refresh,
+// so the refresh is refused for them.
+func (api *API) VerifyDatasourceConnection(ctx context.Context, siteId SiteID, datasourceId DatasourceID, connection Connection, interval time.Duration) (CredentialCheck, error) {
+	check := CredentialCheck{}
+	client := api.WithContext(ctx)
+	updated, err := client.UpdateDatasourceConnection(siteId, datasourceId, connection)
+	if err != nil {
+		return check, err
+	}
+	check.Connection = updated
+	job, err := client.UpdateDatasourceNow(siteId, datasourceId)
+	if err != nil {
+		return check, err
+	}
+	job, err = api.WaitForJob(ctx, siteId, job.ID, interval, nil)
+	check.Job = job
+	if job.ExtractRefreshJob != nil {
+		check.Notes = job.ExtractRefreshJob.Notes
+	}
+	switch {
+	case err == nil:
+		check.Working = true
+	case errors.Is(err, ErrJobFailed):
+		return check, nil
+	}
+	return check, err
+}
@@ -0,0 +1,199 @@
+package tableau4go
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/xml"
+	"errors"
+	"strings"
+)
+
+var ErrNoCredentialProvider = errors.New("No Credential Provider Configured")
+
+// CredentialProvider supplies sign-in credentials on demand. The client asks
+// for them every time it has to authenticate, including when the server
+// rejects an expired token, so implementations backed by a secret store pick
+// up rotated secrets without the client being rebuilt.
+//
+// The returned Credentials should set exactly one of Name/Password,
+// PersonalAccessTokenName/PersonalAccessTokenSecret or JWT, and Site to the
+// contentUrl of the site to sign in to.
+type CredentialProvider interface {
+	Credentials() (Credentials, error)
+}
+
+type CredentialProviderFunc func() (Credentials, error)
+
+func (f CredentialProviderFunc) Credentials() (Credentials, error) {
+	return f()
+}
+
+func NewPasswordCredentialProvider(username, password, contentUrl string) CredentialProvider {
+	return CredentialProviderFunc(func() (Credentials, error) {
+		return Credentials{Name: username, Password: password, Site: &Site{ContentUrl: contentUrl}}, nil
+	})
+}
+
+func NewPersonalAccessTokenProvider(tokenName, tokenSecret, contentUrl string) CredentialProvider {
+	return CredentialProviderFunc(func() (Credentials, error) {
+		return Credentials{PersonalAccessTokenName: tokenName, PersonalAccessTokenSecret: tokenSecret, Site: &Site{ContentUrl: contentUrl}}, nil
+	})
+}
+
+// NewJWTProvider calls mint for a fresh token on every sign in, since
+// connected app JWTs are short lived and single use.
+func NewJWTProvider(mint func() (string, error), contentUrl string) CredentialProvider {
+	return CredentialProviderFunc(func() (Credentials, error) {
+		jwt, err := mint()
+		if err != nil {
+			return Credentials{}, err
+		}
+		return Credentials{JWT: jwt, Site: &Site{ContentUrl: contentUrl}}, nil
+	})
+}
+
+// SecretCredentialProvider is a CredentialProvider that leaves the password or
+// personal access token secret out of Credentials and hands it over as a byte
+// slice instead. Sign in writes it straight into the request body and zeroes
+// both once the request is sent, so the secret is never held in a string or a
+// field of the API.
+type SecretCredentialProvider interface {
+	CredentialProvider
+	// Secret returns a new copy of the secret, which the caller zeroes
+	Secret() ([]byte, error)
+}
+
+// SealedCredentialProvider keeps a secret encrypted in memory under a random
+// key and only decrypts it for the duration of a sign in.
+type SealedCredentialProvider struct {
+	credentials Credentials
+	aead        cipher.AEAD
+	nonce       []byte
+	sealed      []byte
+}
+
+// NewSealedPasswordProvider seals password and zeroes it; the caller should
+// not keep another copy.
+func NewSealedPasswordProvider(username string, password []byte, contentUrl string) (*SealedCredentialProvider, error) {
+	return newSealedProvider(Credentials{Name: username, Site: &Site{ContentUrl: contentUrl}}, password)
+}
+
+// NewSealedPersonalAccessTokenProvider seals tokenSecret and zeroes it; the
+// caller should not keep another copy.
+func NewSealedPersonalAccessTokenProvider(tokenName string, tokenSecret []byte, contentUrl string) (*SealedCredentialProvider, error) {
+	return newSealedProvider(Credentials{PersonalAccessTokenName: tokenName, Site: &Site{ContentUrl: contentUrl}}, tokenSecret)
+}
+
+func newSealedProvider(credentials Credentials, secret []byte) (*SealedCredentialProvider, error) {
+	defer clear(secret)
+	key := make([]byte, 32)
+	defer clear(key)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &SealedCredentialProvider{credentials: credentials, aead: aead, nonce: nonce, sealed: aead.Seal(nil, nonce, secret, nil)}, nil
+}
+
+// Credentials returns everything but the secret.
+func (p *SealedCredentialProvider) Credentials() (Credentials, error) {
+	credentials := p.credentials
+	if p.credentials.Site != nil {
+		site := *p.credentials.Site
+		credentials.Site = &site
+	}
+	return credentials, nil
+}
+
+func (p *SealedCredentialProvider) Secret() ([]byte, error) {
+	return p.aead.Open(nil, p.nonce, p.sealed, nil)
+}
+
+// deriveProvider returns a provider whose Credentials are those of provider
+// passed through modify, keeping the secret out of them if provider does.
+func deriveProvider(provider CredentialProvider, modify func(*Credentials)) CredentialProvider {
+	derived := CredentialProviderFunc(func() (Credentials, error) {
+		credentials, err := provider.Credentials()
+		if err != nil {
+			return credentials, err
+		}
+		modify(&credentials)
+		return credentials, nil
+	})
+	if secret, ok := provider.(SecretCredentialProvider); ok {
+		return derivedSecretProvider{CredentialProviderFunc: derived, secret: secret.Secret}
+	}
+	return derived
+}
+
+type derivedSecretProvider struct {
+	CredentialProviderFunc
+	secret func() ([]byte, error)
+}
+
+func (p derivedSecretProvider) Secret() ([]byte, error) {
+	return p.secret()
+}
+
+// stands in for the secret while the sign in request is marshaled
+const secret_placeholder = "t4g-sealed-secret-placeholder"
+
+// spliceSecret marshals the sign in request with secret in place of the
+// password, or of the personal access token secret when a token name is set.
+// The buffer is sized up front so no partial copy of the secret is left
+// behind by it growing.
+func spliceSecret(credentials Credentials, secret []byte) ([]byte, error) {
+	if len(credentials.PersonalAccessTokenName) > 0 {
+		credentials.PersonalAccessTokenSecret = secret_placeholder
+	} else {
+		credentials.Password = secret_placeholder
+	}
+	payload, err := SigninRequest{Request: credentials}.XML()
+	if err != nil {
+		return nil, err
+	}
+	i := bytes.Index(payload, []byte(secret_placeholder))
+	if i < 0 {
+		return nil, errors.New("Secret Placeholder Not Found")
+	}
+	// xml escapes take at most six bytes per input byte
+	buf := bytes.NewBuffer(make([]byte, 0, len(payload)+6*len(secret)))
+	buf.Write(payload[:i])
+	if err := xml.EscapeText(buf, secret); err != nil {
+		clear(buf.Bytes())
+		return nil, err
+	}
+	buf.Write(payload[i+len(secret_placeholder):])
+	return buf.Bytes(), nil
+}
+
+// tableau reports a missing, expired or invalid auth token with a 401xxx code
+func isUnauthorized(err error) bool {
+	var tErr Terror
+	return errors.As(err, &tErr) && strings.HasPrefix(tErr.Code, "401")
+}
+
+// tableau reports a call the user lacks the role for with a 403xxx code
+func isForbidden(err error) bool {
+	var tErr Terror
+	return errors.As(err, &tErr) && strings.HasPrefix(tErr.Code, "403")
+}
+
+// tableau reports a name that is already taken with a 409xxx code
+func isConflict(err error) bool {
+	var tErr Terror
+	return errors.As(err, &tErr) && strings.HasPrefix(tErr.Code, "409")
+}
@@ -0,0 +1,118 @@
+package tableau4go
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/groundfoundation/tableau4go/tabdoc"
+)
+
+var ErrConnectionNotMatched = errors.New("No Published Connection Matches")
+
+// DatasourceMetadataUpdate is what UpdateDatasourceMetadata changes. The
+// name, description, project and owner set on Datasource are applied with
+// UpdateDatasource; leave them empty to keep them. TDS is a .tds, or a .tdsx
+// whose .tds is used, with the connection details to apply. Credentials are
+// keyed by server address and applied to connections to that server, since a
+// .tds saved by Tableau Desktop doesn't carry passwords.
+type DatasourceMetadataUpdate struct {
+	Datasource  Datasource
+	TDS         []byte
+	Credentials map[string]ConnectionCredentials
+}
+
+func (u DatasourceMetadataUpdate) hasMetadata() bool {
+	ds := u.Datasource
+	return len(ds.Name) > 0 || len(ds.Description) > 0 || ds.Project != nil || ds.Owner != nil
+}
+
+// UpdateDatasourceMetadata changes a published datasource without uploading
+// it again, so a large extract stays where it is. The REST API has no way to
+// replace the .tds of a published extract on its own: calculated fields,
+// folders and other document changes still need a republish. What it can
+// change is the datasource's metadata and the server, port, user name and
+// password of each connection, which is what this applies.
+//
+// Each database connection in the .tds, other than its extract, is matched to
+// a published connection of the same type, preferring one to the same server;
+// failing that, the only unmatched connection of the type is used, so a
+// changed server still matches. The connections updated are returned. Connections that can't be matched or
+// updated don't stop the rest; the failures are returned as a *MultiError
+// keyed by "connection:<server>".
+func (api *API) UpdateDatasourceMetadata(siteId SiteID, datasourceId DatasourceID, update DatasourceMetadataUpdate) ([]Connection, error) {
+	if update.hasMetadata() {
+		datasource := update.Datasource
+		datasource.ID = datasourceId
+		if _, err := api.UpdateDatasource(siteId, datasource); err != nil {
+			return nil, err
+		}
+	}
+	if len(update.TDS) == 0 {
+		return nil, nil
+	}
+	document := update.TDS
+	if bytes.HasPrefix(document, []byte("PK")) {
+		var err error
+		if _, document, err = tabdoc.PackageDocument(document); err != nil {
+			return nil, err
+		}
+	}
+	doc, err := tabdoc.Parse(document)
+	if err != nil {
+		return nil, err
+	}
+	published, err := api.QueryDatasourceConnections(siteId, datasourceId)
+	if err != nil {
+		return nil, err
+	}
+	updated := []Connection{}
+	failures := &MultiError{}
+	used := map[ConnectionID]bool{}
+	for _, c := range doc.Connections() {
+		// extracts are stored with the datasource and have no connection
+		// details to update
+		if c.Class() == "federated" || c.IsExtract() {
+			continue
+		}
+		key := "connection:" + c.Server()
+		match, ok := matchConnection(published, used, c)
+		if !ok {
+			failures.fail(key, ErrConnectionNotMatched)
+			continue
+		}
+		used[match.ID] = true
+		match.ServerAddress, match.ServerPort, match.UserName = c.Server(), c.Port(), c.Username()
+		if credentials, ok := update.Credentials[c.Server()]; ok {
+			if len(credentials.Name) > 0 {
+				match.UserName = credentials.Name
+			}
+			match.Password = credentials.Password
+			match.EmbedPassword = credentials.Embed
+		}
+		connection, err := api.UpdateDatasourceConnection(siteId, datasourceId, match)
+		if err == nil {
+			updated = append(updated, connection)
+		}
+		failures.record(key, err)
+	}
+	return updated, failures.err()
+}
+
+// matchConnection finds the unused published connection for c: one of the same
+// type to the same server, or else the only unused one of that type.
+func matchConnection(published []Connection, used map[ConnectionID]bool, c *tabdoc.Connection) (Connection, bool) {
+	candidates := []Connection{}
+	for _, connection := range published {
+		if used[connection.ID] || connection.Type != c.Class() {
+			continue
+		}
+		if connection.ServerAddress == c.Server() {
+			return connection, true
+		}
+		candidates = append(candidates, connection)
+	}
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+	return Connection{}, false
+}
@@ -0,0 +1,133 @@
+package tableau4go
+
+import (
+	"bytes"
+
+	"github.com/groundfoundation/tableau4go/tabdoc"
+)
+
+// the connection type of connections to published datasources
+const published_connection_type = "sqlproxy"
+
+// EmbeddedDatasource is a datasource stored inside a workbook, which connects
+// to the data itself.
+type EmbeddedDatasource struct {
+	ID          DatasourceID
+	Name        string
+	Connections []Connection
+}
+
+// PublishedDependency is a published datasource a workbook connects to.
+// Datasource is nil when no datasource on the site has the content URL the
+// workbook refers to, e.g. it was deleted or the workbook was moved without
+// it.
+type PublishedDependency struct {
+	ContentUrl string
+	Datasource *Datasource
+}
+
+// WorkbookDependencies says what data a workbook depends on. A workbook with
+// embedded datasources carries its own connections and credentials, while one
+// built on published datasources needs them to exist wherever it goes.
+type WorkbookDependencies struct {
+	WorkbookID WorkbookID
+	Embedded   []EmbeddedDatasource
+	Published  []PublishedDependency
+}
+
+// Unresolved returns the content URLs of the published datasources the
+// workbook refers to that aren't on the site.
+func (d WorkbookDependencies) Unresolved() []string {
+	unresolved := []string{}
+	for _, dependency := range d.Published {
+		if dependency.Datasource == nil {
+			unresolved = append(unresolved, dependency.ContentUrl)
+		}
+	}
+	return unresolved
+}
+
+// WorkbookDependencies reports the workbook's embedded and published
+// datasources. The embedded ones and their connections come from
+// QueryWorkbookConnections. The REST API only names the published ones, so
+// the workbook is downloaded, without extracts, to read which datasources
+// its connections point at; each is then looked up on the site with a
+// content URL filter.
+func (api *API) WorkbookDependencies(siteId SiteID, workbookId WorkbookID) (WorkbookDependencies, error) {
+	deps := WorkbookDependencies{WorkbookID: workbookId}
+	connections, err := api.QueryWorkbookConnections(siteId, workbookId)
+	if err != nil {
+		return deps, err
+	}
+	embedded := map[DatasourceID]int{}
+	usesPublished := false
+	for _, connection := range connections {
+		if connection.Type == published_connection_type {
+			usesPublished = true
+			continue
+		}
+		datasource := EmbeddedDatasource{}
+		if connection.Datasource != nil {
+			datasource.ID, datasource.Name = connection.Datasource.ID, connection.Datasource.Name
+		}
+		i, ok := embedded[datasource.ID]
+		if !ok {
+			i = len(deps.Embedded)
+			embedded[datasource.ID] = i
+			deps.Embedded = append(deps.Embedded, datasource)
+		}
+		deps.Embedded[i].Connections = append(deps.Embedded[i].Connections, connection)
+	}
+	if !usesPublished {
+		return deps, nil
+	}
+	contentUrls, err := api.publishedContentUrls(siteId, workbookId)
+	if err != nil {
+		return deps, err
+	}
+	for _, contentUrl := range contentUrls {
+		datasources, err := api.queryAllDatasources(siteId, ListOptions{Filter: "contentUrl:eq:" + FilterValue(contentUrl)})
+		if err != nil {
+			return deps, err
+		}
+		dependency := PublishedDependency{ContentUrl: contentUrl}
+		for i := range datasources {
+			if datasources[i].ContentUrl == contentUrl {
+				dependency.Datasource = &datasources[i]
+			}
+		}
+		deps.Published = append(deps.Published, dependency)
+	}
+	return deps, nil
+}
+
+// publishedContentUrls reads the content URLs of the published datasources
+// the workbook's connections point at from the workbook itself, where they
+// are the dbname of each sqlproxy connection.
+func (api *API) publishedContentUrls(siteId SiteID, workbookId WorkbookID) ([]string, error) {
+	var buf bytes.Buffer
+	if _, err := api.DownloadWorkbook(siteId, workbookId, false, &buf); err != nil {
+		return nil, err
+	}
+	document := buf.Bytes()
+	if bytes.HasPrefix(document, []byte("PK")) {
+		var err error
+		if _, document, err = tabdoc.PackageDocument(document); err != nil {
+			return nil, err
+		}
+	}
+	doc, err := tabdoc.Parse(document)
+	if err != nil {
+		return nil, err
+	}
+	contentUrls := []string{}
+	seen := map[string]bool{}
+	for _, c := range doc.Connections() {
+		if c.Class() != published_connection_type || seen[c.DBName()] {
+			continue
+		}
+		seen[c.DBName()] = true
+		contentUrls = append(contentUrls, c.DBName())
+	}
+	return contentUrls, nil
+}
@@ -0,0 +1,88 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var ErrUnsupportedDeployment = errors.New("Unsupported On This Deployment")
+
+// serverOnly lists the wrapped endpoints Tableau Cloud doesn't offer, mostly
+// server wide administration. Password sign in is listed as "PasswordSignin";
+// Cloud only accepts personal access tokens and connected app JWTs.
+var serverOnly = map[string]bool{
+	"PasswordSignin":                    true,
+	"GetTrustedTicket":                  true,
+	"CreateSite":                        true,
+	"QuerySchedules":                    true,
+	"UpdateSchedule":                    true,
+	"CreateSchedule":                    true,
+	"UpdateServerADDomain":              true,
+	"QueryServerMobileSecuritySettings": true,
+	"QueryServerExtensionsSettings":     true,
+	"UpdateServerExtensionsSettings":    true,
+}
+
+// cloud_host_suffix is the domain Tableau Cloud pods are served from
+const cloud_host_suffix = ".online.tableau.com"
+
+// DeploymentError is returned when an endpoint isn't available on the
+// client's Deployment. It matches ErrUnsupportedDeployment.
+type DeploymentError struct {
+	Endpoint   string
+	Deployment Deployment
+}
+
+func (e *DeploymentError) Error() string {
+	return fmt.Sprintf("%s is not available on Tableau %s", e.Endpoint, e.Deployment)
+}
+
+func (e *DeploymentError) Unwrap() error {
+	return ErrUnsupportedDeployment
+}
+
+// DetectDeployment guesses the deployment from the server URL: Tableau
+// Cloud pods are served from online.tableau.com. Set API.Deployment
+// explicitly for Cloud sites behind a custom domain.
+func DetectDeployment(server string) Deployment {
+	parsed, err := url.Parse(server)
+	if err != nil {
+		return DeploymentServer
+	}
+	if strings.HasSuffix(strings.ToLower(parsed.Hostname()), cloud_host_suffix) {
+		return DeploymentCloud
+	}
+	return DeploymentServer
+}
+
+// DeploymentLimits are the documented limits of a deployment. Zero means
+// no limit is known.
+type DeploymentLimits struct {
+	// the largest request body the server accepts; bigger files must be sent
+	// with UploadFile
+	MaxRequestSize int64
+	// the largest workbook or datasource that can be published
+	MaxPublishSize int64
+}
+
+const (
+	max_request_size       = 64 * 1024 * 1024
+	max_cloud_publish_size = 15 * 1024 * 1024 * 1024
+)
+
+func (d Deployment) Limits() DeploymentLimits {
+	limits := DeploymentLimits{MaxRequestSize: max_request_size}
+	if d == DeploymentCloud {
+		limits.MaxPublishSize = max_cloud_publish_size
+	}
+	return limits
+}
+
+func (api *API) requireDeployment(endpoint string) error {
+	if api.Deployment == DeploymentCloud && serverOnly[endpoint] {
+		return &DeploymentError{Endpoint: endpoint, Deployment: api.Deployment}
+	}
+	return nil
+}
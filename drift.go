@@ -0,0 +1,227 @@
+package tableau4go
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/groundfoundation/tableau4go/tabdoc"
+)
+
+// InventoryItem is one project, workbook, datasource or flow in an Inventory.
+// Path is the project path followed by the name, which identifies the item
+// across sites where its ID differs. Checksum and Revision, the number of the
+// current revision, are only set on workbooks and datasources when the
+// inventory was taken with checksums.
+type InventoryItem struct {
+	Type      string `json:"type"`
+	Path      string `json:"path"`
+	ID        string `json:"id"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	Size      int    `json:"size,omitempty"`
+	Checksum  string `json:"checksum,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+}
+
+func (item InventoryItem) key() string {
+	return item.Type + ":" + item.Path
+}
+
+// Inventory lists a site's content keyed by "<type>:<path>".
+type Inventory struct {
+	Items map[string]InventoryItem `json:"items"`
+}
+
+// TakeInventory lists every project, workbook, datasource and flow on the
+// site. With checksums set each workbook and datasource is downloaded without
+// extracts and the digest of its .twb or .tds recorded, along with its
+// current revision, which is slow on large sites but the only way to tell
+// whether content really differs.
+func (api *API) TakeInventory(siteId SiteID, checksums bool) (Inventory, error) {
+	inventory := Inventory{Items: map[string]InventoryItem{}}
+	add := func(item InventoryItem) {
+		inventory.Items[item.key()] = item
+	}
+	projects, err := api.queryAllProjects(siteId, ListOptions{})
+	if err != nil {
+		return inventory, err
+	}
+	paths := projectPaths(projects)
+	contentPath := func(project *Project, name string) string {
+		if project == nil {
+			return name
+		}
+		return paths[project.ID] + "/" + name
+	}
+	for _, project := range projects {
+		add(InventoryItem{Type: CONTENT_TYPE_PROJECT, Path: paths[project.ID], ID: string(project.ID)})
+	}
+	workbooks, err := api.queryAllWorkbooks(siteId, ListOptions{})
+	if err != nil {
+		return inventory, err
+	}
+	for _, workbook := range workbooks {
+		item := InventoryItem{Type: CONTENT_TYPE_WORKBOOK, Path: contentPath(workbook.Project, workbook.Name), ID: string(workbook.ID), UpdatedAt: workbook.UpdatedAt, Size: workbook.Size}
+		if checksums {
+			var buf bytes.Buffer
+			if _, err := api.DownloadWorkbook(siteId, workbook.ID, false, &buf); err != nil {
+				return inventory, err
+			}
+			item.Checksum = documentDigest(buf.Bytes())
+			revisions, err := api.QueryWorkbookRevisions(siteId, workbook.ID)
+			if err != nil {
+				return inventory, err
+			}
+			item.Revision = currentRevision(revisions)
+		}
+		add(item)
+	}
+	datasources, err := api.queryAllDatasources(siteId, ListOptions{})
+	if err != nil {
+		return inventory, err
+	}
+	for _, datasource := range datasources {
+		item := InventoryItem{Type: CONTENT_TYPE_DATASOURCE, Path: contentPath(datasource.Project, datasource.Name), ID: string(datasource.ID), UpdatedAt: datasource.UpdatedAt}
+		if checksums {
+			var buf bytes.Buffer
+			if _, err := api.DownloadDatasource(siteId, datasource.ID, false, &buf); err != nil {
+				return inventory, err
+			}
+			item.Checksum = documentDigest(buf.Bytes())
+			revisions, err := api.QueryDatasourceRevisions(siteId, datasource.ID)
+			if err != nil {
+				return inventory, err
+			}
+			item.Revision = currentRevision(revisions)
+		}
+		add(item)
+	}
+	if api.Supports("QueryFlows") {
+		flows, err := api.queryAllFlows(siteId, ListOptions{})
+		if err != nil {
+			return inventory, err
+		}
+		for _, flow := range flows {
+			add(InventoryItem{Type: CONTENT_TYPE_FLOW, Path: contentPath(flow.Project, flow.Name), ID: string(flow.ID), UpdatedAt: flow.UpdatedAt})
+		}
+	}
+	return inventory, nil
+}
+
+// documentDigest digests the .twb or .tds rather than the whole package, since
+// packages are rebuilt with fresh timestamps on every download.
+func documentDigest(data []byte) string {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		if _, document, err := tabdoc.PackageDocument(data); err == nil {
+			return ContentDigest(document)
+		}
+	}
+	return ContentDigest(data)
+}
+
+// currentRevision returns the number of the current revision, or of the
+// latest one if none is marked current.
+func currentRevision(revisions []Revision) string {
+	for _, revision := range revisions {
+		if revision.Current {
+			return revision.RevisionNumber
+		}
+	}
+	if len(revisions) == 0 {
+		return ""
+	}
+	return revisions[len(revisions)-1].RevisionNumber
+}
+
+// ItemDrift is an item present on both sites that differs. Fields names the
+// attributes that differ.
+type ItemDrift struct {
+	Source InventoryItem `json:"source"`
+	Target InventoryItem `json:"target"`
+	Fields []string      `json:"fields"`
+}
+
+// DriftReport lists how a target site differs from a source site: Added is
+// only on the target, Removed only on the source.
+type DriftReport struct {
+	Added   []InventoryItem `json:"added"`
+	Removed []InventoryItem `json:"removed"`
+	Changed []ItemDrift     `json:"changed"`
+}
+
+func (r DriftReport) InSync() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// CompareInventories reports the drift from source to target. Content is
+// compared by checksum when both items have one, and otherwise by size when
+// both have one. updatedAt differs between sites even for identical content,
+// so items with neither, such as datasources listed without checksums, are
+// reported when the source was updated after the target, meaning the target
+// hasn't caught up with it. Revisions are compared when both items have one.
+func CompareInventories(source, target Inventory) DriftReport {
+	report := DriftReport{}
+	for key, s := range source.Items {
+		t, ok := target.Items[key]
+		if !ok {
+			report.Removed = append(report.Removed, s)
+			continue
+		}
+		fields := []string{}
+		switch {
+		case len(s.Checksum) > 0 && len(t.Checksum) > 0:
+			if s.Checksum != t.Checksum {
+				fields = append(fields, "checksum")
+			}
+		case s.Size > 0 && t.Size > 0:
+			if s.Size != t.Size {
+				fields = append(fields, "size")
+			}
+		case len(s.UpdatedAt) > 0 && len(t.UpdatedAt) > 0:
+			if updatedAfter(s.UpdatedAt, t.UpdatedAt) {
+				fields = append(fields, "updatedAt")
+			}
+		}
+		if len(s.Revision) > 0 && len(t.Revision) > 0 && s.Revision != t.Revision {
+			fields = append(fields, "revision")
+		}
+		if len(fields) > 0 {
+			report.Changed = append(report.Changed, ItemDrift{Source: s, Target: t, Fields: fields})
+		}
+	}
+	for key, t := range target.Items {
+		if _, ok := source.Items[key]; !ok {
+			report.Added = append(report.Added, t)
+		}
+	}
+	sort.Slice(report.Added, func(i, j int) bool { return report.Added[i].key() < report.Added[j].key() })
+	sort.Slice(report.Removed, func(i, j int) bool { return report.Removed[i].key() < report.Removed[j].key() })
+	sort.Slice(report.Changed, func(i, j int) bool { return report.Changed[i].Source.key() < report.Changed[j].Source.key() })
+	return report
+}
+
+// updatedAfter reports whether timestamp a is later than b. Unparsable
+// timestamps are compared as strings, which orders the server's ISO 8601
+// UTC timestamps correctly too.
+func updatedAfter(a, b string) bool {
+	at, aErr := time.Parse(time.RFC3339, a)
+	bt, bErr := time.Parse(time.RFC3339, b)
+	if aErr != nil || bErr != nil {
+		return a > b
+	}
+	return at.After(bt)
+}
+
+// CompareSites takes an inventory of both sites and compares them, e.g. a
+// production site and its disaster recovery standby.
+func CompareSites(source, target SiteClient, checksums bool) (DriftReport, error) {
+	s, err := source.api.TakeInventory(source.ID, checksums)
+	if err != nil {
+		return DriftReport{}, err
+	}
+	t, err := target.api.TakeInventory(target.ID, checksums)
+	if err != nil {
+		return DriftReport{}, err
+	}
+	return CompareInventories(s, t), nil
+}
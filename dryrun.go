@@ -0,0 +1,37 @@
+package tableau4go
+
+import "sync"
+
+// PlannedRequest is a mutating call that a dry run recorded instead of sending.
+type PlannedRequest struct {
+	Method  string
+	URL     string
+	Payload []byte
+}
+
+// DryRunPlan collects the requests a client would have sent. Set API.DryRun to
+// a plan to preview a migration or provisioning run: GET requests and sign in
+// still go to the server, while POST, PUT and DELETE calls are recorded and
+// reported as successful with an empty response. Results of calls that create
+// content are therefore empty, so later steps that depend on server assigned
+// IDs see blank IDs in the plan.
+type DryRunPlan struct {
+	mu       sync.Mutex
+	requests []PlannedRequest
+}
+
+func NewDryRunPlan() *DryRunPlan {
+	return &DryRunPlan{}
+}
+
+func (p *DryRunPlan) Requests() []PlannedRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PlannedRequest(nil), p.requests...)
+}
+
+func (p *DryRunPlan) record(method, url string, payload []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, PlannedRequest{Method: method, URL: url, Payload: append([]byte(nil), payload...)})
+}
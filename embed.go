@@ -0,0 +1,109 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+var ErrViewPathUnknown = errors.New("View Has No Content Url")
+
+// ViewURLOptions adds URL filters and parameters to a view URL. Filters map a
+// field name to the values to keep; Parameters set parameter values. The
+// remaining options only apply to embed URLs.
+type ViewURLOptions struct {
+	Filters     map[string][]string
+	Parameters  map[string]string
+	HideToolbar bool
+	HideTabs    bool
+}
+
+// ViewEmbedURL returns the URL to load view in an iframe or the Embedding
+// API. site is the site the view is on; on Tableau Server the default site
+// has no segment in the URL, while on Tableau Cloud every site has a
+// contentUrl and so always gets one.
+func (api *API) ViewEmbedURL(site Site, view View, opts ViewURLOptions) (string, error) {
+	viewPath, err := viewPath(view)
+	if err != nil {
+		return "", err
+	}
+	query := []string{":embed=y", ":showVizHome=no"}
+	if opts.HideToolbar {
+		query = append(query, ":toolbar=no")
+	}
+	if opts.HideTabs {
+		query = append(query, ":tabs=no")
+	}
+	query = append(query, opts.query()...)
+	return fmt.Sprintf("%s%s/views/%s?%s", api.Server, api.sitePath(site.ContentUrl), viewPath, strings.Join(query, "&")), nil
+}
+
+// ViewShareURL returns the URL users open in a browser to see view in the
+// Tableau web client, as produced by its Share button.
+func (api *API) ViewShareURL(site Site, view View, opts ViewURLOptions) (string, error) {
+	viewPath, err := viewPath(view)
+	if err != nil {
+		return "", err
+	}
+	siteFragment := ""
+	if len(site.ContentUrl) > 0 && !api.isDefaultSite(site.ContentUrl) {
+		siteFragment = "/site/" + url.PathEscape(site.ContentUrl)
+	}
+	shareUrl := fmt.Sprintf("%s/#%s/views/%s", api.Server, siteFragment, viewPath)
+	if query := opts.query(); len(query) > 0 {
+		shareUrl += "?" + strings.Join(query, "&")
+	}
+	return shareUrl, nil
+}
+
+// sitePath is the /t/<contentUrl> prefix of non-default sites
+func (api *API) sitePath(contentUrl string) string {
+	if len(contentUrl) == 0 || api.isDefaultSite(contentUrl) {
+		return ""
+	}
+	return "/t/" + url.PathEscape(contentUrl)
+}
+
+// viewPath turns a view into the "Workbook/Sheet" path used in URLs. The REST
+// API reports view content urls as "Workbook/sheets/Sheet".
+func viewPath(view View) (string, error) {
+	if len(view.ContentUrl) > 0 {
+		return strings.Replace(view.ContentUrl, "/sheets/", "/", 1), nil
+	}
+	if view.Workbook != nil && len(view.Workbook.ContentUrl) > 0 && len(view.ViewUrlName) > 0 {
+		return view.Workbook.ContentUrl + "/" + view.ViewUrlName, nil
+	}
+	return "", ErrViewPathUnknown
+}
+
+func (opts ViewURLOptions) query() []string {
+	query := []string{}
+	fields := make([]string, 0, len(opts.Filters))
+	for field := range opts.Filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		values := make([]string, 0, len(opts.Filters[field]))
+		for _, value := range opts.Filters[field] {
+			values = append(values, escapeFilterValue(value))
+		}
+		query = append(query, escapeViewQuery(field)+"="+strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(opts.Parameters))
+	for name := range opts.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		query = append(query, escapeViewQuery(name)+"="+escapeViewQuery(opts.Parameters[name]))
+	}
+	return query
+}
+
+// Tableau expects spaces in URL filters as %20 rather than +
+func escapeViewQuery(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,81 @@
+package tableau4go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnsureProjectOptions describes the project EnsureProject creates when it is
+// missing. ParentProjectID is where the project is looked for and created;
+// empty means the top level. An existing project is returned as it is; the
+// description and permissions are not applied to it.
+type EnsureProjectOptions struct {
+	Description        string
+	ContentPermissions ContentPermissions
+	ParentProjectID    ProjectID
+}
+
+// EnsureProject returns the project with the given name under
+// opts.ParentProjectID, creating it if there is none. It is safe to run
+// repeatedly and alongside other callers: if the project is created by
+// someone else in between, the create's 409 is answered with their project.
+func (api *API) EnsureProject(siteId SiteID, name string, opts EnsureProjectOptions) (Project, error) {
+	projects, err := api.queryAllProjects(siteId, ListOptions{})
+	if err != nil {
+		return Project{}, err
+	}
+	return api.ensureProject(siteId, projects, name, opts)
+}
+
+// EnsureProjectPath is EnsureProject for a nested path such as
+// "Finance/Reports/Monthly", creating each missing project along it under
+// opts.ParentProjectID. The description and permissions in opts only apply to
+// the last project; the ones above it are created with the defaults. Project
+// names containing "/" can't be reached this way.
+func (api *API) EnsureProjectPath(siteId SiteID, path string, opts EnsureProjectOptions) (Project, error) {
+	names := strings.Split(strings.Trim(path, "/"), "/")
+	for _, name := range names {
+		if len(name) == 0 {
+			return Project{}, fmt.Errorf("Invalid Project Path '%s'", path)
+		}
+	}
+	projects, err := api.queryAllProjects(siteId, ListOptions{})
+	if err != nil {
+		return Project{}, err
+	}
+	parent := opts.ParentProjectID
+	project := Project{}
+	for i, name := range names {
+		step := EnsureProjectOptions{ParentProjectID: parent}
+		if i == len(names)-1 {
+			step.Description, step.ContentPermissions = opts.Description, opts.ContentPermissions
+		}
+		if project, err = api.ensureProject(siteId, projects, name, step); err != nil {
+			return project, err
+		}
+		parent = project.ID
+	}
+	return project, nil
+}
+
+func (api *API) ensureProject(siteId SiteID, projects []Project, name string, opts EnsureProjectOptions) (Project, error) {
+	if existing, ok := findProject(projects, opts.ParentProjectID, name); ok {
+		return existing, nil
+	}
+	// someone else may have created it since the projects were listed
+	project := Project{Name: name, Description: opts.Description, ContentPermissions: opts.ContentPermissions, ParentProjectID: opts.ParentProjectID}
+	created, err := api.CreateProjectWithOptions(siteId, project, CreateOptions{IfExists: IfExistsReturn})
+	if err != nil {
+		return Project{}, err
+	}
+	return *created, nil
+}
+
+func findProject(projects []Project, parentId ProjectID, name string) (Project, bool) {
+	for _, project := range projects {
+		if project.Name == name && project.ParentProjectID == parentId {
+			return project, true
+		}
+	}
+	return Project{}, false
+}
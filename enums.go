@@ -0,0 +1,276 @@
+package tableau4go
+
+import "fmt"
+
+type SiteRole string
+
+const (
+	SiteRoleCreator                   SiteRole = "Creator"
+	SiteRoleExplorer                  SiteRole = "Explorer"
+	SiteRoleExplorerCanPublish        SiteRole = "ExplorerCanPublish"
+	SiteRoleSiteAdministratorCreator  SiteRole = "SiteAdministratorCreator"
+	SiteRoleSiteAdministratorExplorer SiteRole = "SiteAdministratorExplorer"
+	SiteRoleServerAdministrator       SiteRole = "ServerAdministrator"
+	SiteRoleViewer                    SiteRole = "Viewer"
+	SiteRoleUnlicensed                SiteRole = "Unlicensed"
+	SiteRoleReadOnly                  SiteRole = "ReadOnly"
+	// roles from before user-based licensing, still returned by older servers
+	SiteRoleInteractor            SiteRole = "Interactor"
+	SiteRolePublisher             SiteRole = "Publisher"
+	SiteRoleSiteAdministrator     SiteRole = "SiteAdministrator"
+	SiteRoleViewerWithPublish     SiteRole = "ViewerWithPublish"
+	SiteRoleUnlicensedWithPublish SiteRole = "UnlicensedWithPublish"
+	SiteRoleGuest                 SiteRole = "Guest"
+)
+
+var siteRoles = map[SiteRole]bool{
+	SiteRoleCreator:                   true,
+	SiteRoleExplorer:                  true,
+	SiteRoleExplorerCanPublish:        true,
+	SiteRoleSiteAdministratorCreator:  true,
+	SiteRoleSiteAdministratorExplorer: true,
+	SiteRoleServerAdministrator:       true,
+	SiteRoleViewer:                    true,
+	SiteRoleUnlicensed:                true,
+	SiteRoleReadOnly:                  true,
+	SiteRoleInteractor:                true,
+	SiteRolePublisher:                 true,
+	SiteRoleSiteAdministrator:         true,
+	SiteRoleViewerWithPublish:         true,
+	SiteRoleUnlicensedWithPublish:     true,
+	SiteRoleGuest:                     true,
+}
+
+func (r SiteRole) Validate() error {
+	if !siteRoles[r] {
+		return fmt.Errorf("Invalid Site Role '%s'", r)
+	}
+	return nil
+}
+
+// License is the kind of user license a site role consumes.
+type License string
+
+const (
+	LicenseCreator    License = "Creator"
+	LicenseExplorer   License = "Explorer"
+	LicenseViewer     License = "Viewer"
+	LicenseUnlicensed License = "Unlicensed"
+)
+
+var siteRoleLicenses = map[SiteRole]License{
+	SiteRoleCreator:                   LicenseCreator,
+	SiteRoleSiteAdministratorCreator:  LicenseCreator,
+	SiteRoleServerAdministrator:       LicenseCreator,
+	SiteRoleExplorer:                  LicenseExplorer,
+	SiteRoleExplorerCanPublish:        LicenseExplorer,
+	SiteRoleSiteAdministratorExplorer: LicenseExplorer,
+	SiteRoleInteractor:                LicenseExplorer,
+	SiteRolePublisher:                 LicenseExplorer,
+	SiteRoleSiteAdministrator:         LicenseExplorer,
+	SiteRoleViewerWithPublish:         LicenseExplorer,
+	SiteRoleViewer:                    LicenseViewer,
+	SiteRoleReadOnly:                  LicenseViewer,
+}
+
+// License returns the license the role consumes. Unlicensed, guest and
+// unknown roles consume none.
+func (r SiteRole) License() License {
+	if license, ok := siteRoleLicenses[r]; ok {
+		return license
+	}
+	return LicenseUnlicensed
+}
+
+// licenseRank orders licenses, so a user on several sites is counted once at
+// their highest
+var licenseRank = map[License]int{
+	LicenseUnlicensed: 0,
+	LicenseViewer:     1,
+	LicenseExplorer:   2,
+	LicenseCreator:    3,
+}
+
+type ContentPermissions string
+
+const (
+	ContentPermissionsLockedToProject              ContentPermissions = "LockedToProject"
+	ContentPermissionsLockedToProjectWithoutNested ContentPermissions = "LockedToProjectWithoutNested"
+	ContentPermissionsManagedByOwner               ContentPermissions = "ManagedByOwner"
+)
+
+func (p ContentPermissions) Validate() error {
+	switch p {
+	case ContentPermissionsLockedToProject, ContentPermissionsLockedToProjectWithoutNested, ContentPermissionsManagedByOwner:
+		return nil
+	}
+	return fmt.Errorf("Invalid Content Permissions '%s'", p)
+}
+
+type CapabilityMode string
+
+const (
+	CapabilityModeAllow CapabilityMode = "Allow"
+	CapabilityModeDeny  CapabilityMode = "Deny"
+)
+
+func (m CapabilityMode) Validate() error {
+	switch m {
+	case CapabilityModeAllow, CapabilityModeDeny:
+		return nil
+	}
+	return fmt.Errorf("Invalid Capability Mode '%s'", m)
+}
+
+// PublishMode says what publishing does when content with the same name
+// already exists in the project.
+type PublishMode string
+
+const (
+	PublishModeCreateNew PublishMode = "CreateNew"
+	PublishModeOverwrite PublishMode = "Overwrite"
+	PublishModeAppend    PublishMode = "Append"
+)
+
+func (m PublishMode) Validate() error {
+	switch m {
+	case PublishModeCreateNew, PublishModeOverwrite, PublishModeAppend:
+		return nil
+	}
+	return fmt.Errorf("Invalid Publish Mode '%s'", m)
+}
+
+func overwriteMode(overwrite bool) PublishMode {
+	if overwrite {
+		return PublishModeOverwrite
+	}
+	return PublishModeCreateNew
+}
+
+// query returns the publish query string parameters for the mode
+func (m PublishMode) query() string {
+	switch m {
+	case PublishModeOverwrite:
+		return "overwrite=true"
+	case PublishModeAppend:
+		return "append=true"
+	}
+	return "overwrite=false"
+}
+
+type ScheduleType string
+
+const (
+	ScheduleTypeExtract          ScheduleType = "Extract"
+	ScheduleTypeSubscription     ScheduleType = "Subscription"
+	ScheduleTypeFlow             ScheduleType = "Flow"
+	ScheduleTypeDataAcceleration ScheduleType = "DataAcceleration"
+)
+
+func (t ScheduleType) Validate() error {
+	switch t {
+	case ScheduleTypeExtract, ScheduleTypeSubscription, ScheduleTypeFlow, ScheduleTypeDataAcceleration:
+		return nil
+	}
+	return fmt.Errorf("Invalid Schedule Type '%s'", t)
+}
+
+type ScheduleFrequency string
+
+const (
+	ScheduleFrequencyHourly  ScheduleFrequency = "Hourly"
+	ScheduleFrequencyDaily   ScheduleFrequency = "Daily"
+	ScheduleFrequencyWeekly  ScheduleFrequency = "Weekly"
+	ScheduleFrequencyMonthly ScheduleFrequency = "Monthly"
+)
+
+func (f ScheduleFrequency) Validate() error {
+	switch f {
+	case ScheduleFrequencyHourly, ScheduleFrequencyDaily, ScheduleFrequencyWeekly, ScheduleFrequencyMonthly:
+		return nil
+	}
+	return fmt.Errorf("Invalid Schedule Frequency '%s'", f)
+}
+
+type ExecutionOrder string
+
+const (
+	ExecutionOrderParallel ExecutionOrder = "Parallel"
+	ExecutionOrderSerial   ExecutionOrder = "Serial"
+)
+
+func (o ExecutionOrder) Validate() error {
+	switch o {
+	case ExecutionOrderParallel, ExecutionOrderSerial:
+		return nil
+	}
+	return fmt.Errorf("Invalid Execution Order '%s'", o)
+}
+
+type ScheduleState string
+
+const (
+	ScheduleStateActive    ScheduleState = "Active"
+	ScheduleStateSuspended ScheduleState = "Suspended"
+)
+
+func (s ScheduleState) Validate() error {
+	switch s {
+	case ScheduleStateActive, ScheduleStateSuspended:
+		return nil
+	}
+	return fmt.Errorf("Invalid Schedule State '%s'", s)
+}
+
+// RedirectPolicy says whether calls follow redirects. The zero value follows
+// them like RedirectFollow. Whatever the policy, a redirect to another host
+// never carries the auth token.
+type RedirectPolicy string
+
+const (
+	RedirectFollow   RedirectPolicy = "Follow"
+	RedirectSameHost RedirectPolicy = "SameHost"
+	RedirectNever    RedirectPolicy = "Never"
+)
+
+func (p RedirectPolicy) Validate() error {
+	switch p {
+	case "", RedirectFollow, RedirectSameHost, RedirectNever:
+		return nil
+	}
+	return fmt.Errorf("Invalid Redirect Policy '%s'", p)
+}
+
+// Deployment is the kind of server the client talks to. The zero value is
+// treated as DeploymentServer.
+type Deployment string
+
+const (
+	DeploymentServer Deployment = "Server"
+	DeploymentCloud  Deployment = "Cloud"
+)
+
+func (d Deployment) Validate() error {
+	switch d {
+	case "", DeploymentServer, DeploymentCloud:
+		return nil
+	}
+	return fmt.Errorf("Invalid Deployment '%s'", d)
+}
+
+// IfExists says what a create call does when the name it is given is already
+// taken. The zero value fails with the server's error like IfExistsFail.
+type IfExists string
+
+const (
+	IfExistsFail   IfExists = "Fail"
+	IfExistsReturn IfExists = "Return"
+)
+
+func (i IfExists) Validate() error {
+	switch i {
+	case "", IfExistsFail, IfExistsReturn:
+		return nil
+	}
+	return fmt.Errorf("Invalid If Exists '%s'", i)
+}
@@ -0,0 +1,203 @@
+package tableau4go
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// EventType names what happened. The names match the Tableau webhook events
+// where there is one, so polled and webhook events can be handled alike.
+type EventType string
+
+const (
+	EventWorkbookCreated         EventType = "WorkbookCreated"
+	EventWorkbookUpdated         EventType = "WorkbookUpdated"
+	EventDatasourceCreated       EventType = "DatasourceCreated"
+	EventDatasourceUpdated       EventType = "DatasourceUpdated"
+	EventWorkbookRefreshFailed   EventType = "WorkbookRefreshFailed"
+	EventDatasourceRefreshFailed EventType = "DatasourceRefreshFailed"
+	EventUserAdded               EventType = "UserAdded"
+)
+
+// Event is something that happened on a site. ResourceID and ResourceName
+// identify the workbook, datasource or user it happened to.
+type Event struct {
+	Type         EventType `json:"type"`
+	SiteID       SiteID    `json:"siteId"`
+	ResourceID   string    `json:"resourceId"`
+	ResourceName string    `json:"resourceName,omitempty"`
+	OccurredAt   time.Time `json:"occurredAt"`
+}
+
+// key identifies the event across overlapping polls
+func (e Event) key() string {
+	return string(e.Type) + ":" + e.ResourceID + ":" + e.OccurredAt.Format(job_time_format)
+}
+
+// EventPoller turns polling into events for servers without webhooks. Each
+// poll looks for workbooks and datasources created or updated, extract
+// refreshes that failed and users added since the previous one. Refresh
+// failures need API 3.1 and a site administrator; they are skipped on older
+// servers. Users are found by comparing the full user list between polls, so
+// the first poll only records who is there.
+//
+// Since, when set, makes Run report changes from then rather than from when
+// it starts, to catch up after downtime.
+type EventPoller struct {
+	Interval time.Duration
+	Since    time.Time
+	OnError  func(error)
+
+	api      *API
+	siteId   SiteID
+	since    time.Time
+	reported map[string]bool
+	users    map[UserID]bool
+}
+
+func (api *API) NewEventPoller(siteId SiteID, interval time.Duration) *EventPoller {
+	return &EventPoller{Interval: interval, api: api, siteId: siteId, reported: map[string]bool{}}
+}
+
+// Run polls until ctx is done, sending each event to events. Only events after
+// Run starts, or after Since, are sent.
+func (p *EventPoller) Run(ctx context.Context, events chan<- Event) error {
+	if p.Interval <= 0 {
+		p.Interval = time.Minute
+	}
+	if p.Since.IsZero() {
+		p.since = p.api.now().UTC()
+	}
+	for {
+		found, err := p.Poll(ctx)
+		if err != nil && p.OnError != nil && ctx.Err() == nil {
+			p.OnError(err)
+		}
+		for _, event := range found {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err := p.api.sleep(ctx, p.Interval); err != nil {
+			return err
+		}
+	}
+}
+
+// Poll returns the events since the previous poll, oldest first. Run calls it
+// on every tick; it can also be called directly from another scheduler.
+func (p *EventPoller) Poll(ctx context.Context) ([]Event, error) {
+	api := p.api.WithContext(ctx)
+	polledAt := api.now().UTC()
+	if p.since.IsZero() {
+		p.since = polledAt
+		if !p.Since.IsZero() {
+			p.since = p.Since.UTC()
+		}
+	}
+	since := p.since.Format(job_time_format)
+	found := []Event{}
+
+	workbooks, err := api.queryAllWorkbooks(p.siteId, ListOptions{Filter: "updatedAt:gte:" + since})
+	if err != nil {
+		return nil, err
+	}
+	for _, workbook := range workbooks {
+		found = append(found, p.contentEvent(EventWorkbookCreated, EventWorkbookUpdated, string(workbook.ID), workbook.Name, workbook.CreatedAt, workbook.UpdatedAt))
+	}
+	datasources, err := api.queryAllDatasources(p.siteId, ListOptions{Filter: "updatedAt:gte:" + since})
+	if err != nil {
+		return nil, err
+	}
+	for _, datasource := range datasources {
+		found = append(found, p.contentEvent(EventDatasourceCreated, EventDatasourceUpdated, string(datasource.ID), datasource.Name, datasource.CreatedAt, datasource.UpdatedAt))
+	}
+	if api.Supports("QueryJobs") {
+		failures, err := p.refreshFailures(api, since)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, failures...)
+	}
+	added, err := p.usersAdded(api, polledAt)
+	if err != nil {
+		return nil, err
+	}
+	found = append(found, added...)
+
+	// only events still inside the overlapping window can come up again
+	reported := map[string]bool{}
+	fresh := []Event{}
+	for _, event := range found {
+		if !p.reported[event.key()] {
+			fresh = append(fresh, event)
+		}
+		reported[event.key()] = true
+	}
+	p.reported = reported
+	p.since = polledAt.Add(-p.Interval)
+	sortEvents(fresh)
+	return fresh, nil
+}
+
+func (p *EventPoller) contentEvent(created, updated EventType, id, name, createdAt, updatedAt string) Event {
+	event := Event{Type: updated, SiteID: p.siteId, ResourceID: id, ResourceName: name}
+	event.OccurredAt, _ = time.Parse(job_time_format, updatedAt)
+	if createdAt == updatedAt {
+		event.Type = created
+	}
+	return event
+}
+
+func (p *EventPoller) refreshFailures(api *API, since string) ([]Event, error) {
+	filter := "jobType:eq:" + JOB_TYPE_REFRESH_EXTRACTS + ",status:eq:" + JOB_STATUS_FAILED + ",endedAt:gte:" + since
+	jobs, err := api.queryAllJobs(p.siteId, ListOptions{Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	events := []Event{}
+	for _, background := range jobs {
+		event := Event{Type: EventDatasourceRefreshFailed, SiteID: p.siteId, ResourceName: background.Title}
+		event.OccurredAt, _ = time.Parse(job_time_format, background.EndedAt)
+		// the listing doesn't say what was refreshed
+		job, err := api.QueryJob(p.siteId, background.ID)
+		if err != nil {
+			return nil, err
+		}
+		if refresh := job.ExtractRefreshJob; refresh != nil {
+			if refresh.Workbook != nil {
+				event.Type, event.ResourceID, event.ResourceName = EventWorkbookRefreshFailed, string(refresh.Workbook.ID), refresh.Workbook.Name
+			} else if refresh.Datasource != nil {
+				event.ResourceID, event.ResourceName = string(refresh.Datasource.ID), refresh.Datasource.Name
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (p *EventPoller) usersAdded(api *API, polledAt time.Time) ([]Event, error) {
+	siteUsers, err := api.queryAllUsers(p.siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	users := map[UserID]bool{}
+	events := []Event{}
+	for _, user := range siteUsers {
+		users[user.ID] = true
+		if p.users != nil && !p.users[user.ID] {
+			events = append(events, Event{Type: EventUserAdded, SiteID: p.siteId, ResourceID: string(user.ID), ResourceName: user.Name, OccurredAt: polledAt})
+		}
+	}
+	p.users = users
+	return events, nil
+}
+
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].OccurredAt.Before(events[j].OccurredAt)
+	})
+}
@@ -0,0 +1,272 @@
+package tableau4go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventCheckpoint stores how far a consumer has got through an event stream,
+// so a restarted stream picks up where the last one stopped. Load returns the
+// zero time when nothing has been saved.
+type EventCheckpoint interface {
+	Load() (time.Time, error)
+	Save(time.Time) error
+}
+
+// FileEventCheckpoint keeps the checkpoint in a file.
+type FileEventCheckpoint struct {
+	Path string
+}
+
+func (c FileEventCheckpoint) Load() (time.Time, error) {
+	data, err := os.ReadFile(c.Path)
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+}
+
+func (c FileEventCheckpoint) Save(t time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(c.Path), filepath.Base(c.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(t.UTC().Format(time.RFC3339Nano) + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.Path)
+}
+
+// EventStreamOptions configure Events. WebhookURL is the address at which the
+// server can reach the stream's Handler; webhooks are only used when it is set
+// and the server supports them. Interval is the polling interval otherwise.
+type EventStreamOptions struct {
+	Interval   time.Duration
+	Checkpoint EventCheckpoint
+	WebhookURL string
+	OnError    func(error)
+}
+
+// EventStream delivers a site's events whether they arrive by webhook or are
+// found by polling. Delivery is at least once: after a restart with the same
+// checkpoint, events since the last one acknowledged are delivered again, so
+// consumers should acknowledge events in order once they are handled and
+// tolerate duplicates.
+//
+// UserAdded events are the exception. Users are found by comparing user lists
+// between polls and the checkpoint only holds a time, so users added while
+// the stream was stopped are not reported, and a user whose event wasn't
+// acknowledged before a restart is not delivered again. Compare
+// QueryUsersOnSite with a list of your own to catch up on users after a
+// restart.
+type EventStream struct {
+	events     chan Event
+	handler    http.Handler
+	checkpoint EventCheckpoint
+	ackMu      sync.Mutex
+	acked      time.Time
+	sendMu     sync.RWMutex
+	closed     bool
+}
+
+// the events the server can send by webhook; users added are only found by
+// polling
+var webhookEvents = []EventType{
+	EventWorkbookCreated,
+	EventWorkbookUpdated,
+	EventDatasourceCreated,
+	EventDatasourceUpdated,
+	EventWorkbookRefreshFailed,
+	EventDatasourceRefreshFailed,
+}
+
+// name given to the webhooks Events creates
+const webhook_name_prefix = "tableau4go "
+
+// Events starts an event stream for the site that runs until ctx is done.
+//
+// With webhooks the stream creates a webhook per event type pointing at
+// opts.WebhookURL, unless one already does, and the caller must serve
+// Handler at that address. Events missed while the consumer was down are
+// caught up with a single poll from the checkpoint. Without webhooks the site
+// is polled with an EventPoller.
+func (api *API) Events(ctx context.Context, siteId SiteID, opts EventStreamOptions) (*EventStream, error) {
+	stream := &EventStream{events: make(chan Event), checkpoint: opts.Checkpoint}
+	if opts.Checkpoint != nil {
+		acked, err := opts.Checkpoint.Load()
+		if err != nil {
+			return nil, err
+		}
+		stream.acked = acked
+	}
+	poller := api.NewEventPoller(siteId, opts.Interval)
+	poller.Since = stream.acked
+	poller.OnError = opts.OnError
+	if len(opts.WebhookURL) == 0 || !api.Supports("CreateWebhook") {
+		go func() {
+			poller.Run(ctx, stream.events)
+			stream.close()
+		}()
+		return stream, nil
+	}
+	if err := api.ensureWebhooks(siteId, opts.WebhookURL); err != nil {
+		return nil, err
+	}
+	stream.handler = webhookHandler{ctx: ctx, stream: stream}
+	go func() {
+		if !stream.acked.IsZero() {
+			missed, err := poller.Poll(ctx)
+			if err != nil && opts.OnError != nil {
+				opts.OnError(err)
+			}
+			for _, event := range missed {
+				if !stream.send(ctx, event) {
+					break
+				}
+			}
+		}
+		<-ctx.Done()
+		stream.close()
+	}()
+	return stream, nil
+}
+
+func (api *API) ensureWebhooks(siteId SiteID, url string) error {
+	existing, err := api.QueryWebhooks(siteId)
+	if err != nil {
+		return err
+	}
+	registered := map[string]bool{}
+	for _, webhook := range existing {
+		if webhook.Destination != nil && webhook.Destination.HTTP != nil && webhook.Destination.HTTP.URL == url {
+			registered[webhook.Event] = true
+		}
+	}
+	for _, event := range webhookEvents {
+		if registered[string(event)] {
+			continue
+		}
+		webhook := Webhook{
+			Name:        webhook_name_prefix + string(event),
+			Event:       string(event),
+			Destination: &WebhookDestination{HTTP: &WebhookDestinationHTTP{Method: POST, URL: url}},
+		}
+		if _, err := api.CreateWebhook(siteId, webhook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Events returns the channel events are delivered on. It is closed once the
+// stream's context is done.
+func (s *EventStream) Events() <-chan Event {
+	return s.events
+}
+
+// Handler receives the server's webhook posts. It is nil when the stream
+// polls.
+func (s *EventStream) Handler() http.Handler {
+	return s.handler
+}
+
+func (s *EventStream) UsesWebhooks() bool {
+	return s.handler != nil
+}
+
+// Ack records that event has been handled, saving it to the checkpoint.
+func (s *EventStream) Ack(event Event) error {
+	s.ackMu.Lock()
+	defer s.ackMu.Unlock()
+	if !event.OccurredAt.After(s.acked) {
+		return nil
+	}
+	s.acked = event.OccurredAt
+	if s.checkpoint == nil {
+		return nil
+	}
+	return s.checkpoint.Save(s.acked)
+}
+
+// send delivers event and reports whether it was taken before ctx was done.
+func (s *EventStream) send(ctx context.Context, event Event) bool {
+	s.sendMu.RLock()
+	defer s.sendMu.RUnlock()
+	if s.closed {
+		return false
+	}
+	select {
+	case s.events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *EventStream) close() {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+}
+
+// webhookPayload is the JSON body Tableau posts to a webhook
+type webhookPayload struct {
+	Resource     string `json:"resource"`
+	EventType    string `json:"event_type"`
+	ResourceName string `json:"resource_name"`
+	SiteID       string `json:"site_luid"`
+	ResourceID   string `json:"resource_luid"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type webhookHandler struct {
+	ctx    context.Context
+	stream *EventStream
+}
+
+// ServeHTTP only answers once the event has been handed to the consumer, so
+// the server sees a failure if the stream has stopped.
+func (h webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	payload := webhookPayload{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	event := Event{
+		Type:         EventType(payload.EventType),
+		SiteID:       SiteID(payload.SiteID),
+		ResourceID:   payload.ResourceID,
+		ResourceName: payload.ResourceName,
+	}
+	if occurredAt, err := time.Parse(time.RFC3339, payload.CreatedAt); err == nil {
+		event.OccurredAt = occurredAt
+	} else {
+		event.OccurredAt = time.Now().UTC()
+	}
+	if !h.stream.send(h.ctx, event) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
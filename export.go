@@ -0,0 +1,125 @@
+package tableau4go
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+var ErrNotASlice = errors.New("Not A Slice")
+
+// WriteNDJSON writes every element of items, which must be a slice, to w as
+// one JSON document per line.
+func WriteNDJSON(w io.Writer, items interface{}) error {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return ErrNotASlice
+	}
+	encoder := json.NewEncoder(w)
+	for i := 0; i < v.Len(); i++ {
+		if err := encoder.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func WriteSitesCSV(w io.Writer, sites []Site) error {
+	return writeCSV(w, []string{"id", "name", "contentUrl", "adminMode", "state", "userQuota", "storageQuota"}, len(sites), func(i int) []string {
+		s := sites[i]
+		return []string{string(s.ID), s.Name, s.ContentUrl, s.AdminMode, s.State, s.UserQuota, strconv.Itoa(s.StorageQuota)}
+	})
+}
+
+func WriteUsersCSV(w io.Writer, users []User) error {
+	return writeCSV(w, []string{"id", "name", "fullName", "siteRole"}, len(users), func(i int) []string {
+		u := users[i]
+		return []string{string(u.ID), u.Name, u.FullName, string(u.SiteRole)}
+	})
+}
+
+func WriteWorkbooksCSV(w io.Writer, workbooks []Workbook) error {
+	return writeCSV(w, []string{"id", "name", "contentUrl", "projectId", "projectName", "ownerId", "size", "createdAt", "updatedAt"}, len(workbooks), func(i int) []string {
+		wb := workbooks[i]
+		projectId, projectName, ownerId := "", "", ""
+		if wb.Project != nil {
+			projectId, projectName = string(wb.Project.ID), wb.Project.Name
+		}
+		if wb.Owner != nil {
+			ownerId = string(wb.Owner.ID)
+		}
+		return []string{string(wb.ID), wb.Name, wb.ContentUrl, projectId, projectName, ownerId, strconv.Itoa(wb.Size), wb.CreatedAt, wb.UpdatedAt}
+	})
+}
+
+// WritePermissionsCSV writes one row per capability granted in perms, so each
+// grantee appears once for every capability it holds.
+func WritePermissionsCSV(w io.Writer, perms []Permissions) error {
+	header := []string{"contentType", "contentId", "granteeType", "granteeId", "capability", "mode"}
+	rows := [][]string{}
+	for _, p := range perms {
+		contentType, contentId := "", ""
+		switch {
+		case p.Project != nil:
+			contentType, contentId = "project", string(p.Project.ID)
+		case p.Workbook != nil:
+			contentType, contentId = "workbook", string(p.Workbook.ID)
+		case p.Datasource != nil:
+			contentType, contentId = "datasource", string(p.Datasource.ID)
+		}
+		for _, grantee := range p.GranteeCapabilities {
+			granteeType, granteeId := "", ""
+			if grantee.Group != nil {
+				granteeType, granteeId = "group", string(grantee.Group.ID)
+			} else if grantee.User != nil {
+				granteeType, granteeId = "user", string(grantee.User.ID)
+			}
+			for _, c := range grantee.Capabilities.Capabilities {
+				rows = append(rows, []string{contentType, contentId, granteeType, granteeId, c.Name, string(c.Mode)})
+			}
+		}
+	}
+	return writeCSV(w, header, len(rows), func(i int) []string { return rows[i] })
+}
+
+// WriteAccessSnapshotJSON writes snapshot to w as indented JSON.
+func WriteAccessSnapshotJSON(w io.Writer, snapshot AccessSnapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// WriteAccessSnapshotCSV writes one row per user and group the user is in, in
+// the snapshot's order; users in no group get a single row with an empty
+// group.
+func WriteAccessSnapshotCSV(w io.Writer, snapshot AccessSnapshot) error {
+	header := []string{"userId", "name", "fullName", "email", "siteRole", "license", "authSetting", "group"}
+	rows := [][]string{}
+	for _, u := range snapshot.Users {
+		row := []string{string(u.ID), u.Name, u.FullName, u.Email, string(u.SiteRole), string(u.License), u.AuthSetting}
+		if len(u.Groups) == 0 {
+			rows = append(rows, append(row, ""))
+		}
+		for _, group := range u.Groups {
+			rows = append(rows, append(row[:len(row):len(row)], group))
+		}
+	}
+	return writeCSV(w, header, len(rows), func(i int) []string { return rows[i] })
+}
+
+func writeCSV(w io.Writer, header []string, n int, row func(i int) []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := writer.Write(row(i)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
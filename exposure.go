@@ -0,0 +1,122 @@
+package tableau4go
+
+// ExposureKind is why an ExposureFinding was raised.
+type ExposureKind string
+
+const (
+	// granted to the All Users group, so to everyone on the site
+	ExposureAllUsers ExposureKind = "AllUsers"
+	// granted to the guest user, so to anyone who can reach the server
+	ExposureGuest ExposureKind = "Guest"
+	// granted to a user without a license, who may be someone who has left
+	ExposureUnlicensed ExposureKind = "Unlicensed"
+)
+
+// ExposureFinding is a grant that ExposureReport flagged. Capabilities holds
+// the capabilities allowed by it; grants that only deny are not findings.
+type ExposureFinding struct {
+	Kind         ExposureKind `json:"kind"`
+	ContentType  string       `json:"contentType"`
+	ContentID    string       `json:"contentId"`
+	ContentName  string       `json:"contentName"`
+	GroupID      GroupID      `json:"groupId,omitempty"`
+	UserID       UserID       `json:"userId,omitempty"`
+	GranteeName  string       `json:"granteeName"`
+	Capabilities []string     `json:"capabilities"`
+}
+
+// ExposureReport reads the permissions of every project, workbook and
+// datasource on the site and returns the grants to All Users, to the guest
+// user and to unlicensed users. Only explicit permissions are read, so
+// content that inherits from a locked project is reported on the project.
+//
+// Content whose permissions can't be read is skipped and reported in a
+// *MultiError keyed by "<type>:<id>", alongside the findings for the rest.
+func (api *API) ExposureReport(siteId SiteID) ([]ExposureFinding, error) {
+	siteUsers, err := api.queryAllUsers(siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	users := map[UserID]User{}
+	for _, user := range siteUsers {
+		users[user.ID] = user
+	}
+	siteGroups, err := api.queryAllGroups(siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	groups := map[GroupID]string{}
+	for _, group := range siteGroups {
+		groups[group.ID] = group.Name
+	}
+
+	findings := []ExposureFinding{}
+	result := &MultiError{}
+	check := func(contentType, id, name string, query func() (Permissions, error)) {
+		permissions, err := query()
+		result.record(contentType+":"+id, err)
+		if err != nil {
+			return
+		}
+		for _, grant := range permissions.GranteeCapabilities {
+			finding := ExposureFinding{ContentType: contentType, ContentID: id, ContentName: name}
+			switch {
+			case grant.Group != nil && groups[grant.Group.ID] == all_users_group:
+				finding.Kind, finding.GroupID, finding.GranteeName = ExposureAllUsers, grant.Group.ID, all_users_group
+			case grant.User != nil:
+				user := users[grant.User.ID]
+				switch user.SiteRole {
+				case SiteRoleGuest:
+					finding.Kind = ExposureGuest
+				case SiteRoleUnlicensed, SiteRoleUnlicensedWithPublish:
+					finding.Kind = ExposureUnlicensed
+				default:
+					continue
+				}
+				finding.UserID, finding.GranteeName = grant.User.ID, user.Name
+			default:
+				continue
+			}
+			for _, capability := range grant.Capabilities.Capabilities {
+				if capability.Mode == CapabilityModeAllow {
+					finding.Capabilities = append(finding.Capabilities, capability.Name)
+				}
+			}
+			if len(finding.Capabilities) > 0 {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	projects, err := api.queryAllProjects(siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		id := project.ID
+		check(CONTENT_TYPE_PROJECT, string(id), project.Name, func() (Permissions, error) {
+			return api.QueryProjectPermissions(siteId, id)
+		})
+	}
+	workbooks, err := api.queryAllWorkbooks(siteId, ListOptions{})
+	if err != nil {
+		return findings, err
+	}
+	for _, workbook := range workbooks {
+		id := workbook.ID
+		check(CONTENT_TYPE_WORKBOOK, string(id), workbook.Name, func() (Permissions, error) {
+			return api.QueryWorkbookPermissions(siteId, id)
+		})
+	}
+	datasources, err := api.queryAllDatasources(siteId, ListOptions{})
+	if err != nil {
+		return findings, err
+	}
+	for _, datasource := range datasources {
+		id := datasource.ID
+		check(CONTENT_TYPE_DATASOURCE, string(id), datasource.Name, func() (Permissions, error) {
+			return api.QueryDatasourcePermissions(siteId, id)
+		})
+	}
+	return findings, result.err()
+}
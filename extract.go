@@ -0,0 +1,70 @@
+package tableau4go
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// LiveToExtractOptions configure ConvertToExtract. BackupDir, if set, gets a
+// copy of the live datasource before it is converted, laid out as for
+// DeleteProjectOptions.ArchiveDir. ScheduleID, if set, is the extract refresh
+// schedule to add the datasource to. Interval is how often jobs are polled.
+type LiveToExtractOptions struct {
+	BackupDir  string
+	Encrypt    bool
+	ScheduleID ScheduleID
+	Interval   time.Duration
+	Progress   ProgressReporter
+}
+
+// LiveToExtractResult reports what ConvertToExtract did. Task is only set
+// when the datasource was added to a schedule.
+type LiveToExtractResult struct {
+	BackupPath string
+	ExtractJob Job
+	Task       *ExtractRefreshTask
+	RefreshJob Job
+}
+
+// ConvertToExtract turns a live datasource into an extract: it backs the
+// datasource up, creates the extract, adds it to the refresh schedule and then
+// runs a first refresh, through the schedule's task when there is one, and
+// waits for it to succeed. It stops at the first step that fails and returns
+// what was done so far; the backup can be republished to undo the conversion.
+func (api *API) ConvertToExtract(ctx context.Context, siteId SiteID, datasourceId DatasourceID, opts LiveToExtractOptions) (LiveToExtractResult, error) {
+	result := LiveToExtractResult{}
+	client := api.WithContext(ctx)
+	if len(opts.BackupDir) > 0 {
+		path, err := archive(opts.BackupDir, CONTENT_TYPE_DATASOURCE, string(datasourceId), func(f *os.File) (string, error) {
+			return client.DownloadDatasource(siteId, datasourceId, false, f)
+		})
+		if err != nil {
+			return result, err
+		}
+		result.BackupPath = path
+	}
+	job, err := client.CreateExtractForDatasource(siteId, datasourceId, opts.Encrypt)
+	if err != nil {
+		return result, err
+	}
+	result.ExtractJob, err = client.WaitForJob(ctx, siteId, job.ID, opts.Interval, opts.Progress)
+	if err != nil {
+		return result, err
+	}
+	if len(opts.ScheduleID) > 0 {
+		task, err := client.AddDatasourceToSchedule(siteId, opts.ScheduleID, datasourceId)
+		if err != nil {
+			return result, err
+		}
+		result.Task = &task
+		job, err = client.RunExtractRefreshTask(siteId, task.ID)
+	} else {
+		job, err = client.UpdateDatasourceNow(siteId, datasourceId)
+	}
+	if err != nil {
+		return result, err
+	}
+	result.RefreshJob, err = client.WaitForJob(ctx, siteId, job.ID, opts.Interval, opts.Progress)
+	return result, err
+}
@@ -0,0 +1,69 @@
+package tableau4go
+
+import (
+	"context"
+	"sync"
+)
+
+// ForEachSite runs fn once for every site on the server, with at most
+// concurrency calls running at a time. Each call gets a SiteClient with its
+// own session on that site, signed in through this client's
+// CredentialProvider, which must supply server administrator credentials; the
+// session is signed out when fn returns. Cancelling ctx stops new sites from
+// being started and cancels the calls made by running ones.
+//
+// A failure on one site does not stop the others. They are returned together
+// as a *MultiError keyed by site contentUrl; if ctx was cancelled, sites that
+// were never started are reported as failed with ctx's error.
+func (api *API) ForEachSite(ctx context.Context, fn func(SiteClient) error, concurrency int) error {
+	if api.CredentialProvider == nil {
+		return ErrNoCredentialProvider
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sites, err := api.WithContext(ctx).QuerySites(ListOptions{})
+	if err != nil {
+		return err
+	}
+	var mu sync.Mutex
+	result := &MultiError{}
+	record := func(site Site, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.record(site.ContentUrl, err)
+	}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for _, site := range sites {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			record(site, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(site Site) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			record(site, api.runOnSite(ctx, site, fn))
+		}(site)
+	}
+	wg.Wait()
+	return result.err()
+}
+
+func (api *API) runOnSite(ctx context.Context, site Site, fn func(SiteClient) error) error {
+	provider := api.CredentialProvider
+	client := api.detached(ctx)
+	client.CredentialProvider = deriveProvider(provider, func(credentials *Credentials) {
+		credentials.Site = &Site{ContentUrl: site.ContentUrl}
+	})
+	if err := client.SigninWithProvider(); err != nil {
+		return err
+	}
+	defer client.Shutdown(context.Background())
+	return fn(client.Site(client.SiteID))
+}
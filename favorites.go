@@ -0,0 +1,102 @@
+package tableau4go
+
+import "fmt"
+
+// FavoriteRef names one item of a favorites template. Type is one of the
+// CONTENT_TYPE constants; Label is what the user sees and defaults to the ID.
+type FavoriteRef struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+func (ref FavoriteRef) favorite() (Favorite, error) {
+	favorite := Favorite{Label: ref.Label}
+	if len(favorite.Label) == 0 {
+		favorite.Label = ref.ID
+	}
+	switch ref.Type {
+	case CONTENT_TYPE_VIEW:
+		favorite.View = &View{ID: ViewID(ref.ID)}
+	case CONTENT_TYPE_WORKBOOK:
+		favorite.Workbook = &Workbook{ID: WorkbookID(ref.ID)}
+	case CONTENT_TYPE_DATASOURCE:
+		favorite.Datasource = &Datasource{ID: DatasourceID(ref.ID)}
+	case CONTENT_TYPE_PROJECT:
+		favorite.Project = &Project{ID: ProjectID(ref.ID)}
+	case CONTENT_TYPE_FLOW:
+		favorite.Flow = &Flow{ID: FlowID(ref.ID)}
+	default:
+		return favorite, fmt.Errorf("Invalid Favorite Type '%s'", ref.Type)
+	}
+	return favorite, nil
+}
+
+// ref returns the type and ID of the content a favorite points at
+func (f Favorite) ref() (string, string) {
+	switch {
+	case f.View != nil:
+		return CONTENT_TYPE_VIEW, string(f.View.ID)
+	case f.Workbook != nil:
+		return CONTENT_TYPE_WORKBOOK, string(f.Workbook.ID)
+	case f.Datasource != nil:
+		return CONTENT_TYPE_DATASOURCE, string(f.Datasource.ID)
+	case f.Project != nil:
+		return CONTENT_TYPE_PROJECT, string(f.Project.ID)
+	case f.Flow != nil:
+		return CONTENT_TYPE_FLOW, string(f.Flow.ID)
+	}
+	return "", ""
+}
+
+// ApplyFavoritesTemplate adds the template's items to the user's favorites,
+// skipping any the user already has, then orders them as in the template.
+// Favorites the user added themselves are left alone. Ordering needs API 3.8
+// and is skipped on older servers.
+//
+// Items that can't be added don't stop the rest and are returned as a
+// *MultiError keyed by "<type>:<id>".
+func (api *API) ApplyFavoritesTemplate(siteId SiteID, userId UserID, template []FavoriteRef) error {
+	existing, err := api.QueryFavorites(siteId, userId)
+	if err != nil {
+		return err
+	}
+	have := map[string]bool{}
+	for _, favorite := range existing {
+		kind, id := favorite.ref()
+		have[kind+":"+id] = true
+	}
+	result := &MultiError{}
+	added := []FavoriteRef{}
+	for _, ref := range template {
+		key := ref.Type + ":" + ref.ID
+		if have[key] {
+			added = append(added, ref)
+			continue
+		}
+		favorite, err := ref.favorite()
+		if err == nil {
+			_, err = api.AddFavorite(siteId, userId, favorite)
+		}
+		result.record(key, err)
+		if err == nil {
+			have[key] = true
+			added = append(added, ref)
+		}
+	}
+	if len(added) > 1 && api.Supports("OrderFavorites") {
+		orders := make([]FavoriteOrder, 0, len(added)-1)
+		for i := 1; i < len(added); i++ {
+			orders = append(orders, FavoriteOrder{
+				FavoriteID:            added[i].ID,
+				FavoriteType:          added[i].Type,
+				FavoriteIDMoveAfter:   added[i-1].ID,
+				FavoriteTypeMoveAfter: added[i-1].Type,
+			})
+		}
+		if err := api.OrderFavorites(siteId, userId, orders); err != nil {
+			return err
+		}
+	}
+	return result.err()
+}
@@ -0,0 +1,42 @@
+package tableau4go
+
+// SyncGroupMembership adds and removes members of a group so that it contains
+// exactly the desired users, and returns the users it added and removed. It
+// carries on past individual failures and reports them together at the end as
+// a *MultiError keyed by user ID.
+func (api *API) SyncGroupMembership(siteId SiteID, groupId GroupID, desired []UserID) (added []UserID, removed []UserID, err error) {
+	members, err := api.queryAllUsersInGroup(siteId, groupId)
+	if err != nil {
+		return nil, nil, err
+	}
+	current := map[UserID]bool{}
+	for _, user := range members {
+		current[user.ID] = true
+	}
+	want := map[UserID]bool{}
+	result := &MultiError{}
+	for _, userId := range desired {
+		want[userId] = true
+		if current[userId] {
+			continue
+		}
+		if _, err := api.AddUserToGroup(siteId, groupId, userId); err != nil {
+			result.fail(string(userId), err)
+			continue
+		}
+		result.succeed(string(userId))
+		added = append(added, userId)
+	}
+	for userId := range current {
+		if want[userId] {
+			continue
+		}
+		if err := api.RemoveUserFromGroup(siteId, groupId, userId); err != nil {
+			result.fail(string(userId), err)
+			continue
+		}
+		result.succeed(string(userId))
+		removed = append(removed, userId)
+	}
+	return added, removed, result.err()
+}
@@ -0,0 +1,108 @@
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// how long ServerInfo is cached when API.ServerInfoTTL is 0
+const default_server_info_ttl = 10 * time.Minute
+
+func (api *API) serverInfoTTL() time.Duration {
+	if api.ServerInfoTTL == 0 {
+		return default_server_info_ttl
+	}
+	return api.ServerInfoTTL
+}
+
+func (s *clientState) cachedServerInfo(now time.Time, ttl time.Duration) (ServerInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.serverInfo == nil || ttl < 0 || now.Sub(s.serverInfoAt) >= ttl {
+		return ServerInfo{}, false
+	}
+	return *s.serverInfo, true
+}
+
+func (s *clientState) cacheServerInfo(info ServerInfo, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.serverInfo = &info
+	s.serverInfoAt = now
+}
+
+// HealthCheckOptions says what HealthCheck verifies beyond reaching the
+// server. MinVersion is the oldest REST API version the server may offer;
+// the client's own Version is always required. CheckSession also checks the
+// signed in session is still accepted.
+type HealthCheckOptions struct {
+	MinVersion   string
+	CheckSession bool
+}
+
+// HealthStatus is the result of HealthCheck. Err says what failed, nil when
+// the client is healthy. SessionValid is only set when the session was
+// checked.
+type HealthStatus struct {
+	Reachable    bool
+	ServerInfo   ServerInfo
+	VersionOK    bool
+	SessionValid bool
+	Latency      time.Duration
+	Err          error
+}
+
+func (s HealthStatus) Healthy() bool {
+	return s.Err == nil
+}
+
+// HealthCheck verifies the server can be reached and offers a recent enough
+// REST API, and optionally that the session is still valid, for readiness
+// probes of services embedding the client. ServerInfo is always asked afresh,
+// refreshing its cache, so a probe can't pass on a cached answer, and it
+// doesn't wait out maintenance. Latency is how long that request took. An
+// expired session is renewed through the CredentialProvider, if there is
+// one, as any other call would.
+func (api *API) HealthCheck(ctx context.Context, opts HealthCheckOptions) HealthStatus {
+	status := HealthStatus{}
+	client := api.WithContext(ctx)
+	client.Maintenance = nil
+	started := api.now()
+	info, err := client.queryServerInfo()
+	status.Latency = api.now().Sub(started)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	status.Reachable = true
+	status.ServerInfo = info
+	for _, required := range []string{api.Version, opts.MinVersion} {
+		if len(required) > 0 && len(info.RestApiVersion) > 0 && compareVersions(info.RestApiVersion, required) < 0 {
+			status.Err = fmt.Errorf("Server Offers API %s; %s Is Required", info.RestApiVersion, required)
+			return status
+		}
+	}
+	status.VersionOK = true
+	if !opts.CheckSession {
+		return status
+	}
+	session, _ := api.currentSession()
+	if len(session.AuthToken) == 0 {
+		status.Err = ErrSigninRequired
+		return status
+	}
+	resp, err := api.Do(ctx, GET, fmt.Sprintf("sites/%s", session.SiteID), nil)
+	if err == nil {
+		err = resp.Err()
+	}
+	if isUnauthorized(err) {
+		err = ErrSigninRequired
+	}
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	status.SessionValid = true
+	return status
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"os"
 	"time"
 )
@@ -67,3 +68,56 @@ func NewTimeoutClient(cTimeout time.Duration, rwTimeout time.Duration, useClient
 func DefaultTimeoutClient() *http.Client {
 	return NewTimeoutClient(connectTimeOut, readWriteTimeout, false)
 }
+
+// the cookie Tableau Server also accepts the auth token in
+const session_cookie = "workgroup_session_id"
+
+// most redirects followed for one call, as net/http does by default
+const max_redirects = 10
+
+// httpClient returns the client for one call, applying the redirect policy
+// and, with SessionCookie set, the cookie jar shared by every copy of api.
+func (api *API) httpClient() (*http.Client, error) {
+	if err := api.Redirects.Validate(); err != nil {
+		return nil, err
+	}
+	client := DefaultTimeoutClient()
+	policy := api.Redirects
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		switch {
+		case policy == RedirectNever:
+			return http.ErrUseLastResponse
+		case policy == RedirectSameHost && req.URL.Host != via[0].URL.Host:
+			return fmt.Errorf("Redirect To Another Host '%s' Refused", req.URL.Host)
+		case len(via) >= max_redirects:
+			return fmt.Errorf("Stopped After %d Redirects", max_redirects)
+		}
+		// the token only ever goes back to the host it was sent to; net/http
+		// would forward a custom header like it to any host
+		if req.URL.Host != via[0].URL.Host {
+			req.Header.Del(auth_header)
+			return nil
+		}
+		// put the token back in case a front end rewrote the request
+		if token := via[0].Header.Get(auth_header); len(token) > 0 && len(req.Header.Get(auth_header)) == 0 {
+			req.Header.Set(auth_header, token)
+		}
+		return nil
+	}
+	if api.SessionCookie {
+		client.Jar = api.lifecycle().cookies()
+	}
+	return client, nil
+}
+
+// cookies returns the jar holding cookies set by the server or a front end
+// during the session, creating it on first use.
+func (s *clientState) cookies() http.CookieJar {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jar == nil {
+		// cookiejar.New only fails for invalid options
+		s.jar, _ = cookiejar.New(nil)
+	}
+	return s.jar
+}
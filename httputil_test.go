@@ -0,0 +1,77 @@
+package tableau4go
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirects(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    RedirectPolicy
+		otherHost bool
+		status    int
+		token     string
+		followed  bool
+		failed    bool
+	}{
+		{name: "same host keeps the token", status: http.StatusOK, token: "secret", followed: true},
+		{name: "other host drops the token", otherHost: true, status: http.StatusOK, token: "", followed: true},
+		{name: "SameHost follows on the same host", policy: RedirectSameHost, status: http.StatusOK, token: "secret", followed: true},
+		{name: "SameHost refuses another host", policy: RedirectSameHost, otherHost: true, failed: true},
+		{name: "Never returns the redirect", policy: RedirectNever, status: http.StatusFound},
+	}
+	for _, test := range tests {
+		followed := false
+		token := ""
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			followed = true
+			token = r.Header.Get(auth_header)
+		}))
+		var origin *httptest.Server
+		origin = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/moved" {
+				followed = true
+				token = r.Header.Get(auth_header)
+				return
+			}
+			location := origin.URL + "/moved"
+			if test.otherHost {
+				location = target.URL + "/moved"
+			}
+			http.Redirect(w, r, location, http.StatusFound)
+		}))
+		api := NewAPI(origin.URL, API_VERSION, BOUNDARY_STRING, "", true)
+		api.AuthToken = "secret"
+		api.Redirects = test.policy
+		resp, err := api.transmit(context.Background(), GET, origin.URL+"/api/"+API_VERSION+"/sites", nil, map[string]string{})
+		origin.Close()
+		target.Close()
+		if (err != nil) != test.failed {
+			t.Errorf("%s: got error %v", test.name, err)
+			continue
+		}
+		if test.failed {
+			continue
+		}
+		if resp.StatusCode != test.status {
+			t.Errorf("%s: got status %d, expected %d", test.name, resp.StatusCode, test.status)
+		}
+		if followed != test.followed {
+			t.Errorf("%s: followed %v, expected %v", test.name, followed, test.followed)
+		}
+		if token != test.token {
+			t.Errorf("%s: redirect carried token %q, expected %q", test.name, token, test.token)
+		}
+	}
+}
+
+func TestInvalidRedirectPolicy(t *testing.T) {
+	api := NewAPI(DEFAULT_SERVER, API_VERSION, BOUNDARY_STRING, "", true)
+	api.Redirects = "Sometimes"
+	if _, err := api.transmit(context.Background(), GET, DEFAULT_SERVER+"/api/"+API_VERSION+"/sites", nil, map[string]string{}); err == nil {
+		t.Error("expected an invalid redirect policy to fail the call")
+	}
+}
@@ -0,0 +1,161 @@
+package tableau4go
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/groundfoundation/tableau4go/tabdoc"
+)
+
+// HyperPublishOptions tunes PublishHyperAsDatasource.
+//
+// Mode defaults to PublishModeCreateNew. PublishModeAppend adds the rows of
+// the .hyper file to an existing extract; Tableau only allows that for
+// extracts with a single table.
+//
+// TDS, if set, is published together with the .hyper file as a .tdsx, so the
+// datasource keeps the calculations, aliases and folders defined in it. The
+// .hyper file is packaged under tabdoc.EXTRACTS_DIR with its own file name,
+// which is where the extract connection in TDS must point.
+type HyperPublishOptions struct {
+	Mode        PublishMode
+	Description string
+	TDS         []byte
+	Upload      UploadOptions
+}
+
+// PublishHyperAsDatasource publishes a .hyper file, such as one written with
+// the Hyper API, as a datasource named name in the project, sending it in
+// chunks so files of any size can be published.
+func (api *API) PublishHyperAsDatasource(siteId SiteID, projectId ProjectID, name string, hyperPath string, opts HyperPublishOptions) (*Datasource, error) {
+	mode := opts.Mode
+	if len(mode) == 0 {
+		mode = PublishModeCreateNew
+	}
+	if err := mode.Validate(); err != nil {
+		return nil, err
+	}
+	uploadPath, datasourceType := hyperPath, "hyper"
+	if len(opts.TDS) > 0 {
+		packaged, err := packageHyper(name, hyperPath, opts.TDS)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(packaged)
+		uploadPath, datasourceType = packaged, "tdsx"
+	}
+	if len(siteId) == 0 {
+		siteId = api.CurrentSite().ID
+	}
+	session, err := api.UploadFile(siteId, uploadPath, opts.Upload)
+	if err != nil {
+		return nil, err
+	}
+	metadata := Datasource{Name: name, Description: opts.Description, Project: &Project{ID: projectId}}
+	return api.publishDatasourceFromUpload(siteId, metadata, session.UploadSessionID, datasourceType, mode)
+}
+
+// packageHyper writes a temporary .tdsx holding tds and the .hyper file and
+// returns its path
+func packageHyper(name string, hyperPath string, tds []byte) (string, error) {
+	f, err := os.CreateTemp("", "tableau4go-*.tdsx")
+	if err != nil {
+		return "", err
+	}
+	extract := tabdoc.PackageFile{Name: path.Join(tabdoc.EXTRACTS_DIR, filepath.Base(hyperPath)), Path: hyperPath}
+	documentName := strings.NewReplacer("/", "_", "\\", "_").Replace(name) + ".tds"
+	err = tabdoc.WritePackage(f, documentName, tds, extract)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// HyperAction is one step of an UpdateHyperData request. Source tables are
+// read from the uploaded .hyper file, target tables are in the published
+// extract. Condition is a Tableau condition expression, e.g.
+// {"op":"eq","target-col":"id","source-col":"id"}, required for update,
+// upsert and delete.
+type HyperAction struct {
+	Action       string          `json:"action"`
+	SourceSchema string          `json:"source-schema,omitempty"`
+	SourceTable  string          `json:"source-table,omitempty"`
+	TargetSchema string          `json:"target-schema,omitempty"`
+	TargetTable  string          `json:"target-table,omitempty"`
+	Condition    json.RawMessage `json:"condition,omitempty"`
+}
+
+const (
+	HYPER_ACTION_INSERT  = "insert"
+	HYPER_ACTION_UPDATE  = "update"
+	HYPER_ACTION_UPSERT  = "upsert"
+	HYPER_ACTION_REPLACE = "replace"
+	HYPER_ACTION_DELETE  = "delete"
+)
+
+const request_id_header = "RequestID"
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#update_data_in_hyper_data_source
+//requires API 3.12; uploadSessionId may be empty when the actions need no source data.
+//requestId makes the call idempotent, so retrying with the same id never applies the actions twice;
+//an empty requestId gets a random one
+func (api *API) UpdateHyperData(siteId SiteID, datasourceId DatasourceID, requestId string, uploadSessionId string, actions []HyperAction) (Job, error) {
+	if err := api.requireVersion("UpdateHyperData"); err != nil {
+		return Job{}, err
+	}
+	url := fmt.Sprintf("%s/datasources/%s/data", api.siteUrl(siteId), datasourceId)
+	if len(uploadSessionId) > 0 {
+		url += "?uploadSessionId=" + uploadSessionId
+	}
+	payload, err := json.Marshal(struct {
+		Actions []HyperAction `json:"actions"`
+	}{actions})
+	if err != nil {
+		return Job{}, err
+	}
+	if len(requestId) == 0 {
+		requestId, err = api.newRequestID()
+		if err != nil {
+			return Job{}, err
+		}
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = application_json_content_type
+	headers[request_id_header] = requestId
+	retval := JobResponse{}
+	err = api.makeRequest(url, PATCH, bytes.NewReader(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Job, err
+}
+
+// UpdateHyperDataFromFile uploads the .hyper file at hyperPath in chunks and
+// applies actions to the published datasource with it, which is much cheaper
+// than republishing a large extract.
+func (api *API) UpdateHyperDataFromFile(siteId SiteID, datasourceId DatasourceID, hyperPath string, actions []HyperAction, opts UploadOptions) (Job, error) {
+	if len(siteId) == 0 {
+		siteId = api.CurrentSite().ID
+	}
+	session, err := api.UploadFile(siteId, hyperPath, opts)
+	if err != nil {
+		return Job{}, err
+	}
+	return api.UpdateHyperData(siteId, datasourceId, "", session.UploadSessionID, actions)
+}
+
+func (api *API) newRequestID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := io.ReadFull(api.random(), id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,46 @@
+package tableau4go
+
+// CreateOptions control create calls whose name can already be taken.
+type CreateOptions struct {
+	IfExists IfExists
+}
+
+// existingProject finds the project a create conflicted with: the one of the
+// same name under the same parent. conflict is returned if there is none,
+// e.g. when the 409 was about something else.
+func (api *API) existingProject(siteId SiteID, project Project, conflict error) (*Project, error) {
+	projects, err := api.queryAllProjects(siteId, ListOptions{Filter: "name:eq:" + FilterValue(project.Name)})
+	if err != nil {
+		return nil, err
+	}
+	if existing, ok := findProject(projects, project.ParentProjectID, project.Name); ok {
+		return &existing, nil
+	}
+	return nil, conflict
+}
+
+func (api *API) existingGroup(siteId SiteID, name string, conflict error) (*Group, error) {
+	groups, _, err := api.QueryGroups(siteId, ListOptions{Filter: "name:eq:" + FilterValue(name)})
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		if group.Name == name {
+			return &group, nil
+		}
+	}
+	return nil, conflict
+}
+
+func (api *API) existingUser(siteId SiteID, name string, conflict error) (User, error) {
+	users, _, err := api.QueryUsersOnSite(siteId, ListOptions{Filter: "name:eq:" + FilterValue(name)})
+	if err != nil {
+		return User{}, err
+	}
+	for _, user := range users {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return User{}, conflict
+}
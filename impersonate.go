@@ -0,0 +1,67 @@
+package tableau4go
+
+import "context"
+
+// AsUser signs in as userId through impersonation and returns an independent
+// client carrying that user's auth token, leaving this client's session
+// untouched. It signs in with this client's CredentialProvider, which must
+// supply server administrator credentials; impersonation is not available
+// with personal access tokens. The returned client stays on the site this
+// client is signed in to unless the provider names another.
+//
+// The impersonated session lasts until ctx is done, when the returned client
+// is shut down and signed out. Its calls also use ctx.
+func (api *API) AsUser(ctx context.Context, userId UserID) (*API, error) {
+	if api.CredentialProvider == nil {
+		return nil, ErrNoCredentialProvider
+	}
+	contentUrl := api.CurrentSite().ContentUrl
+	provider := api.CredentialProvider
+	user := api.detached(ctx)
+	// re-signins after the token expires must keep impersonating
+	user.CredentialProvider = deriveProvider(provider, func(credentials *Credentials) {
+		if credentials.Site == nil || len(credentials.Site.ContentUrl) == 0 {
+			credentials.Site = &Site{ContentUrl: contentUrl}
+		}
+		credentials.Impersonate = &User{ID: userId}
+	})
+	if err := user.SigninWithProvider(); err != nil {
+		return nil, err
+	}
+	context.AfterFunc(ctx, func() {
+		user.Shutdown(context.Background())
+	})
+	return user, nil
+}
+
+// detached returns a signed out client with this client's configuration and
+// its own session and shutdown state, whose calls use ctx.
+func (api *API) detached(ctx context.Context) *API {
+	return &API{
+		Server:              api.Server,
+		Version:             api.Version,
+		Boundary:            api.Boundary,
+		OmitDefaultSiteName: api.OmitDefaultSiteName,
+		DefaultSiteName:     api.DefaultSiteName,
+		DryRun:              api.DryRun,
+		Auditor:             api.Auditor,
+		Codec:               api.Codec,
+		Redirects:           api.Redirects,
+		SessionCookie:       api.SessionCookie,
+		Deployment:          api.Deployment,
+		MaxRequestBody:      api.MaxRequestBody,
+		MaxResponseBody:     api.MaxResponseBody,
+		UseDeploymentLimits: api.UseDeploymentLimits,
+		SessionIdleTimeout:  api.SessionIdleTimeout,
+		Clock:               api.Clock,
+		Random:              api.Random,
+		Maintenance:         api.Maintenance,
+		Observer:            api.Observer,
+		Budget:              api.Budget,
+		Breaker:             api.Breaker,
+		ServerInfoTTL:       api.ServerInfoTTL,
+		ctx:                 ctx,
+		state:               newClientState(),
+		session:             &sessionGuard{},
+	}
+}
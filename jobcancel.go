@@ -0,0 +1,67 @@
+package tableau4go
+
+import (
+	"strings"
+	"sync"
+)
+
+// JobFilter selects jobs to cancel. JobType is a jobType from job listings,
+// e.g. JOB_TYPE_REFRESH_EXTRACTS; empty matches every type. Statuses defaults
+// to pending and running jobs, the only ones that can be cancelled.
+type JobFilter struct {
+	JobType  string
+	Statuses []string
+}
+
+func (f JobFilter) expression(status string) string {
+	terms := []string{"status:eq:" + status}
+	if len(f.JobType) > 0 {
+		terms = append(terms, "jobType:eq:"+f.JobType)
+	}
+	return strings.Join(terms, ",")
+}
+
+// CancelJobs cancels every job matching filter, at most concurrency at a
+// time, and returns the jobs it cancelled. Jobs that finish before they are
+// cancelled fail to cancel, so when clearing a busy queue expect some
+// failures. Failures are returned as a *MultiError keyed by job ID.
+func (api *API) CancelJobs(siteId SiteID, filter JobFilter, concurrency int) ([]JobID, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{JOB_STATUS_PENDING, JOB_STATUS_IN_PROGRESS}
+	}
+	jobs := []BackgroundJob{}
+	for _, status := range statuses {
+		found, err := api.queryAllJobs(siteId, ListOptions{Filter: filter.expression(status)})
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, found...)
+	}
+
+	var mu sync.Mutex
+	cancelled := []JobID{}
+	result := &MultiError{}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for _, job := range jobs {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(id JobID) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			err := api.CancelJob(siteId, id)
+			mu.Lock()
+			defer mu.Unlock()
+			result.record(string(id), err)
+			if err == nil {
+				cancelled = append(cancelled, id)
+			}
+		}(job.ID)
+	}
+	wg.Wait()
+	return cancelled, result.err()
+}
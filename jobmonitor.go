@@ -0,0 +1,155 @@
+package tableau4go
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	JOB_STATUS_PENDING     = "Pending"
+	JOB_STATUS_IN_PROGRESS = "InProgress"
+	JOB_STATUS_SUCCESS     = "Success"
+	JOB_STATUS_FAILED      = "Failed"
+	JOB_STATUS_CANCELLED   = "Cancelled"
+)
+
+// the timestamp format of job filters and job attributes
+const job_time_format = "2006-01-02T15:04:05Z"
+
+// JobMonitor polls a site's background job queue. It keeps the pending and
+// running jobs in memory and calls OnFailure for each job that fails and
+// OnLongRunning once for each job that has been running longer than
+// LongRunning. Callbacks are called from Run's goroutine.
+//
+// Filter is added to every query, e.g. "jobType:eq:refresh_extracts" to watch
+// only extract refreshes. Set the fields before calling Run.
+type JobMonitor struct {
+	Interval      time.Duration
+	Filter        string
+	LongRunning   time.Duration
+	OnFailure     func(BackgroundJob)
+	OnLongRunning func(BackgroundJob)
+	OnError       func(error)
+
+	api      *API
+	siteId   SiteID
+	mu       sync.Mutex
+	active   map[JobID]BackgroundJob
+	reported map[JobID]bool
+	since    time.Time
+}
+
+// NewJobMonitor returns a monitor for the site's jobs that polls every
+// interval. QueryJobs needs API 3.1 and a site administrator.
+func (api *API) NewJobMonitor(siteId SiteID, interval time.Duration) *JobMonitor {
+	return &JobMonitor{
+		Interval: interval,
+		api:      api,
+		siteId:   siteId,
+		active:   map[JobID]BackgroundJob{},
+		reported: map[JobID]bool{},
+	}
+}
+
+// Run polls until ctx is done. Only failures after Run starts are reported.
+func (m *JobMonitor) Run(ctx context.Context) error {
+	if m.Interval <= 0 {
+		m.Interval = time.Minute
+	}
+	m.since = m.api.now().UTC()
+	for {
+		if err := m.Poll(ctx); err != nil && m.OnError != nil && ctx.Err() == nil {
+			m.OnError(err)
+		}
+		if err := m.api.sleep(ctx, m.Interval); err != nil {
+			return err
+		}
+	}
+}
+
+// Poll refreshes the monitor's view of the queue once. Run calls it on every
+// tick; it can also be called directly to drive the monitor from another
+// scheduler.
+func (m *JobMonitor) Poll(ctx context.Context) error {
+	api := m.api.WithContext(ctx)
+	polledAt := api.now().UTC()
+	if m.since.IsZero() {
+		m.since = polledAt
+	}
+	active, err := m.queryAll(api, "status:in:["+JOB_STATUS_PENDING+","+JOB_STATUS_IN_PROGRESS+"]")
+	if err != nil {
+		return err
+	}
+	failed, err := m.queryAll(api, "status:eq:"+JOB_STATUS_FAILED+",endedAt:gte:"+m.since.Format(job_time_format))
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.active = map[JobID]BackgroundJob{}
+	for _, job := range active {
+		m.active[job.ID] = job
+	}
+	// only jobs still in this poll's results can come up again
+	reported := map[JobID]bool{}
+	var newFailures, newLongRunning []BackgroundJob
+	for _, job := range failed {
+		if !m.reported[job.ID] {
+			newFailures = append(newFailures, job)
+		}
+		reported[job.ID] = true
+	}
+	if m.LongRunning > 0 {
+		for _, job := range active {
+			started, err := time.Parse(job_time_format, job.StartedAt)
+			if err != nil || job.Status != JOB_STATUS_IN_PROGRESS || polledAt.Sub(started) < m.LongRunning {
+				continue
+			}
+			if !m.reported["long:"+job.ID] {
+				newLongRunning = append(newLongRunning, job)
+			}
+			reported["long:"+job.ID] = true
+		}
+	}
+	m.reported = reported
+	// keep the failure window overlapping so jobs ending mid poll aren't
+	// missed; reported stops them being raised twice
+	m.since = polledAt.Add(-m.Interval)
+	m.mu.Unlock()
+
+	for _, job := range newFailures {
+		if m.OnFailure != nil {
+			m.OnFailure(job)
+		}
+	}
+	for _, job := range newLongRunning {
+		if m.OnLongRunning != nil {
+			m.OnLongRunning(job)
+		}
+	}
+	return nil
+}
+
+// Jobs returns the pending and running jobs seen by the last poll, oldest
+// first.
+func (m *JobMonitor) Jobs() []BackgroundJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]BackgroundJob, 0, len(m.active))
+	for _, job := range m.active {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt < jobs[j].CreatedAt
+	})
+	return jobs
+}
+
+func (m *JobMonitor) queryAll(api *API, filter string) ([]BackgroundJob, error) {
+	if len(m.Filter) > 0 {
+		filter += "," + m.Filter
+	}
+	return api.queryAllJobs(m.siteId, ListOptions{Filter: filter})
+}
@@ -0,0 +1,106 @@
+package tableau4go
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sessionClock tracks when the current auth token was issued and when it will
+// expire. Tableau sessions expire after a period of inactivity, so every
+// authenticated request pushes the expiry back. It is shared by copies of the
+// client made with WithContext, which use the same token.
+type sessionClock struct {
+	mu        sync.Mutex
+	issuedAt  time.Time
+	expiresAt time.Time
+	idle      time.Duration
+}
+
+func newSessionClock(issuedAt time.Time, idle time.Duration) *sessionClock {
+	return &sessionClock{issuedAt: issuedAt, expiresAt: issuedAt.Add(idle), idle: idle}
+}
+
+func (c *sessionClock) touch(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if expiresAt := now.Add(c.idle); expiresAt.After(c.expiresAt) {
+		c.expiresAt = expiresAt
+	}
+}
+
+func (c *sessionClock) expiry() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.expiresAt
+}
+
+// sessionIdleTimeout is the idle timeout for a new session: the configured
+// SessionIdleTimeout, else the server's estimate, else DEFAULT_SESSION_TIMEOUT
+func (api *API) sessionIdleTimeout(estimate string) time.Duration {
+	if api.SessionIdleTimeout > 0 {
+		return api.SessionIdleTimeout
+	}
+	return sessionLifetime(estimate)
+}
+
+// TokenIssuedAt returns when the current auth token was obtained, or the zero
+// time if the client is not signed in or restored a session that did not
+// record it.
+func (api *API) TokenIssuedAt() time.Time {
+	_, clock := api.currentSession()
+	if clock == nil {
+		return time.Time{}
+	}
+	return clock.issuedAt
+}
+
+// TokenExpiresAt returns when the current auth token will expire if the client
+// stays idle, or the zero time if it is not signed in. The server may still
+// end the session earlier, for example when it is signed out elsewhere.
+func (api *API) TokenExpiresAt() time.Time {
+	_, clock := api.currentSession()
+	if clock == nil {
+		return time.Time{}
+	}
+	return clock.expiry()
+}
+
+// touchSession records server activity that keeps the session alive
+func (api *API) touchSession(resp *Response) {
+	if _, clock := api.currentSession(); clock != nil && resp != nil && resp.StatusCode != 401 {
+		clock.touch(api.now())
+	}
+}
+
+// KeepAlive stops the session from idling out during long pauses in a
+// daemon's work by sending a cheap authenticated request every interval,
+// until ctx is done. An interval of zero pings at half the idle timeout.
+// Failed pings are passed to onError, if not nil; an expired token is
+// replaced transparently when a CredentialProvider is configured. KeepAlive
+// blocks, so run it in its own goroutine.
+func (api *API) KeepAlive(ctx context.Context, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		interval = DEFAULT_SESSION_TIMEOUT / 2
+		if _, clock := api.currentSession(); clock != nil && clock.idle > 0 {
+			interval = clock.idle / 2
+		}
+	}
+	for {
+		if err := api.sleep(ctx, interval); err != nil {
+			return err
+		}
+		session, _ := api.currentSession()
+		if len(session.AuthToken) == 0 {
+			continue
+		}
+		resp, err := api.Do(ctx, GET, fmt.Sprintf("sites/%s", session.SiteID), nil)
+		if err == nil {
+			err = resp.Err()
+		}
+		if err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package tableau4go
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// SiteLicenseUsage counts one site's users by site role and by the license
+// those roles consume.
+type SiteLicenseUsage struct {
+	Roles    map[SiteRole]int `json:"roles"`
+	Licenses map[License]int  `json:"licenses"`
+}
+
+// LicenseReport is the license consumption of a whole server at TakenAt.
+// Sites is keyed by site contentUrl. Licenses counts each user once, at the
+// highest license any of their site roles consumes, which is how user-based
+// licenses are consumed; summing the sites would count users on several
+// sites more than once.
+type LicenseReport struct {
+	TakenAt  time.Time                   `json:"takenAt"`
+	Sites    map[string]SiteLicenseUsage `json:"sites"`
+	Licenses map[License]int             `json:"licenses"`
+}
+
+// LicenseUsage counts the users of every site on the server, visiting at
+// most concurrency sites at a time with ForEachSite, so it needs a
+// CredentialProvider with server administrator credentials. Sites that
+// can't be counted are left out of the report and returned in a *MultiError
+// keyed by site contentUrl.
+func (api *API) LicenseUsage(ctx context.Context, concurrency int) (LicenseReport, error) {
+	report := LicenseReport{
+		TakenAt:  api.now().UTC(),
+		Sites:    map[string]SiteLicenseUsage{},
+		Licenses: map[License]int{},
+	}
+	var mu sync.Mutex
+	highest := map[string]License{}
+	err := api.ForEachSite(ctx, func(site SiteClient) error {
+		current, err := site.Query(false)
+		if err != nil {
+			return err
+		}
+		usage := SiteLicenseUsage{Roles: map[SiteRole]int{}, Licenses: map[License]int{}}
+		users := map[string]License{}
+		siteUsers, err := site.api.queryAllUsers(site.ID, ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, user := range siteUsers {
+			usage.Roles[user.SiteRole]++
+			usage.Licenses[user.SiteRole.License()]++
+			users[user.Name] = user.SiteRole.License()
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		report.Sites[current.ContentUrl] = usage
+		for name, license := range users {
+			if previous, ok := highest[name]; !ok || licenseRank[license] > licenseRank[previous] {
+				highest[name] = license
+			}
+		}
+		return nil
+	}, concurrency)
+	for _, license := range highest {
+		report.Licenses[license]++
+	}
+	return report, err
+}
+
+// LicenseReportStore keeps license reports so consumption can be followed
+// over time. History returns them oldest first.
+type LicenseReportStore interface {
+	Save(LicenseReport) error
+	History() ([]LicenseReport, error)
+}
+
+// FileLicenseReportStore appends reports to a file as JSON lines.
+type FileLicenseReportStore struct {
+	Path string
+}
+
+func (s FileLicenseReportStore) Save(report LicenseReport) error {
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s FileLicenseReportStore) History() ([]LicenseReport, error) {
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	reports := []LicenseReport{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		report := LicenseReport{}
+		if err := json.Unmarshal(scanner.Bytes(), &report); err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, scanner.Err()
+}
+
+// RecordLicenseUsage takes a LicenseReport, saves it to store and returns
+// the store's history ending with it. A report is saved even when some sites
+// failed, so the gap shows in the trend; the failures are still returned.
+func (api *API) RecordLicenseUsage(ctx context.Context, store LicenseReportStore, concurrency int) ([]LicenseReport, error) {
+	report, err := api.LicenseUsage(ctx, concurrency)
+	var partial *MultiError
+	if err != nil && !errors.As(err, &partial) {
+		return nil, err
+	}
+	if saveErr := store.Save(report); saveErr != nil {
+		return nil, saveErr
+	}
+	history, historyErr := store.History()
+	if historyErr != nil {
+		return nil, historyErr
+	}
+	return history, err
+}
@@ -0,0 +1,130 @@
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrClientShutdown = errors.New("Client Is Shut Down")
+
+// clientState tracks the requests in flight across every copy of an API, so
+// Shutdown can drain and cancel them.
+type clientState struct {
+	mu       sync.Mutex
+	closed   bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	inflight sync.WaitGroup
+	jar      http.CookieJar
+	// renamedSites maps old site content URLs to new ones
+	renamedSites map[string]string
+	// siteIds caches GetSiteID by site name
+	siteIds map[string]SiteID
+	// serverInfo caches ServerInfo as of serverInfoAt
+	serverInfo   *ServerInfo
+	serverInfoAt time.Time
+}
+
+func newClientState() *clientState {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &clientState{ctx: ctx, cancel: cancel}
+}
+
+// lifecycle returns the shared state, creating it for clients that were not
+// built with NewAPI. That lazy creation is not safe for concurrent use; build
+// clients that are shared between goroutines with NewAPI.
+func (api *API) lifecycle() *clientState {
+	if api.state == nil {
+		api.state = newClientState()
+	}
+	return api.state
+}
+
+// begin registers a request. The returned context is cancelled when either ctx
+// is done or the client is shut down; done must be called once the request
+// has finished.
+func (s *clientState) begin(ctx context.Context) (context.Context, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil, ErrClientShutdown
+	}
+	s.inflight.Add(1)
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		s.inflight.Done()
+	}, nil
+}
+
+// WithContext returns a copy of the client whose calls use ctx, so they are
+// abandoned when ctx is cancelled or its deadline passes. The copy shares the
+// original's shutdown state but has its own auth token, so a sign in made
+// through the copy does not update the original.
+func (api *API) WithContext(ctx context.Context) *API {
+	api.lifecycle()
+	g := api.guard()
+	g.mu.RLock()
+	clone := *api
+	g.mu.RUnlock()
+	clone.ctx = ctx
+	clone.session = &sessionGuard{}
+	return &clone
+}
+
+func (api *API) context() context.Context {
+	if api.ctx == nil {
+		return context.Background()
+	}
+	return api.ctx
+}
+
+// Shutdown stops the client from accepting new requests, waits for requests
+// in flight to finish until ctx is done, cancels any that are still running
+// and finally signs out so the server side session is not left behind. After
+// Shutdown every call returns ErrClientShutdown.
+func (api *API) Shutdown(ctx context.Context) error {
+	state := api.lifecycle()
+	state.mu.Lock()
+	if state.closed {
+		state.mu.Unlock()
+		return ErrClientShutdown
+	}
+	state.closed = true
+	state.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		state.inflight.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	state.cancel()
+	<-drained
+
+	if len(api.authToken()) > 0 {
+		// sign out even if ctx ran out while draining
+		url := fmt.Sprintf("%s/api/%s/auth/signout", api.Server, api.Version)
+		resp, signoutErr := api.transmit(context.WithoutCancel(ctx), POST, url, nil, map[string]string{content_type_header: application_xml_content_type})
+		if signoutErr == nil {
+			signoutErr = resp.Err()
+		}
+		if signoutErr == nil {
+			api.clearSession()
+		} else if err == nil {
+			err = signoutErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrBodyTooLarge = errors.New("Body Too Large")
+
+// BodyTooLargeError is returned when a request or response body is bigger
+// than API.MaxRequestBody or API.MaxResponseBody. Size is -1 when the body
+// was cut off as it streamed, before its full size was known. It matches
+// ErrBodyTooLarge.
+type BodyTooLargeError struct {
+	Response bool
+	Limit    int64
+	Size     int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	direction := "Request"
+	if e.Response {
+		direction = "Response"
+	}
+	if e.Size < 0 {
+		return fmt.Sprintf("%s Body Exceeds Limit Of %d Bytes", direction, e.Limit)
+	}
+	return fmt.Sprintf("%s Body Of %d Bytes Exceeds Limit Of %d Bytes", direction, e.Size, e.Limit)
+}
+
+func (e *BodyTooLargeError) Unwrap() error {
+	return ErrBodyTooLarge
+}
+
+type bodyLimitsKey struct{}
+
+// WithoutBodyLimits returns a context whose calls ignore MaxRequestBody and
+// MaxResponseBody, for transfers that are meant to be large, such as
+// downloading a packaged workbook with its extract:
+//
+//	_, err := api.WithContext(tableau4go.WithoutBodyLimits(ctx)).DownloadWorkbook(siteId, id, true, f)
+func WithoutBodyLimits(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bodyLimitsKey{}, true)
+}
+
+// bodyLimits returns the request and response limits that apply to calls
+// made with ctx; 0 means no limit
+func (api *API) bodyLimits(ctx context.Context) (int64, int64) {
+	if disabled, _ := ctx.Value(bodyLimitsKey{}).(bool); disabled {
+		return 0, 0
+	}
+	maxRequest := api.MaxRequestBody
+	if maxRequest == 0 && api.UseDeploymentLimits {
+		maxRequest = api.Deployment.Limits().MaxRequestSize
+	}
+	return maxRequest, api.MaxResponseBody
+}
+
+// limitedBody fails a streamed request body once it passes limit, for
+// bodies whose length isn't known up front
+type limitedBody struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n, &BodyTooLargeError{Limit: b.limit, Size: -1}
+	}
+	return n, err
+}
+
+// readLimited reads a response body, failing once it passes limit
+func readLimited(r io.Reader, declared int64, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(r)
+	}
+	if declared > limit {
+		return nil, &BodyTooLargeError{Response: true, Limit: limit, Size: declared}
+	}
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err == nil && int64(len(body)) > limit {
+		return nil, &BodyTooLargeError{Response: true, Limit: limit, Size: -1}
+	}
+	return body, err
+}
+
+// copyLimited streams a response body to w, failing once it passes limit
+func copyLimited(w io.Writer, r io.Reader, declared int64, limit int64) (int64, error) {
+	if limit <= 0 {
+		return io.Copy(w, r)
+	}
+	if declared > limit {
+		return 0, &BodyTooLargeError{Response: true, Limit: limit, Size: declared}
+	}
+	written, err := io.Copy(w, io.LimitReader(r, limit))
+	if err == nil && written == limit {
+		if more, _ := io.CopyN(io.Discard, r, 1); more > 0 {
+			return written, &BodyTooLargeError{Response: true, Limit: limit, Size: -1}
+		}
+	}
+	return written, err
+}
@@ -0,0 +1,128 @@
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(api *API)
+		ctx      context.Context
+		body     io.Reader
+		response string
+		expected *BodyTooLargeError
+		sent     bool
+	}{
+		{
+			name:     "request over MaxRequestBody",
+			setup:    func(api *API) { api.MaxRequestBody = 10 },
+			body:     strings.NewReader("01234567890"),
+			expected: &BodyTooLargeError{Limit: 10, Size: 11},
+		},
+		{
+			name:     "request over the deployment limit",
+			setup:    func(api *API) { api.UseDeploymentLimits = true },
+			body:     sizedReader{Reader: strings.NewReader(""), size: max_request_size + 1},
+			expected: &BodyTooLargeError{Limit: max_request_size, Size: max_request_size + 1},
+		},
+		{
+			name:     "MaxRequestBody overrides the deployment limit",
+			setup:    func(api *API) { api.UseDeploymentLimits = true; api.MaxRequestBody = 5 },
+			body:     strings.NewReader("012345"),
+			expected: &BodyTooLargeError{Limit: 5, Size: 6},
+		},
+		{
+			name:     "response over MaxResponseBody",
+			setup:    func(api *API) { api.MaxResponseBody = 4 },
+			response: "0123456789",
+			expected: &BodyTooLargeError{Response: true, Limit: 4, Size: 10},
+			sent:     true,
+		},
+		{
+			name:  "WithoutBodyLimits",
+			setup: func(api *API) { api.MaxRequestBody = 1; api.MaxResponseBody = 1 },
+			ctx:   WithoutBodyLimits(context.Background()),
+			body:  strings.NewReader("0123"),
+			sent:  true,
+		},
+		{
+			name: "no limits by default",
+			body: strings.NewReader("0123"),
+			sent: true,
+		},
+	}
+	for _, test := range tests {
+		sent := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sent = true
+			fmt.Fprint(w, test.response)
+		}))
+		api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+		if test.setup != nil {
+			test.setup(&api)
+		}
+		ctx := test.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		_, err := api.transmit(ctx, POST, server.URL+"/api/"+API_VERSION+"/sites", test.body, map[string]string{})
+		server.Close()
+		var tooLarge *BodyTooLargeError
+		switch {
+		case test.expected == nil && err != nil:
+			t.Errorf("%s: got error %v", test.name, err)
+		case test.expected != nil && !errors.As(err, &tooLarge):
+			t.Errorf("%s: got error %v, expected %v", test.name, err, test.expected)
+		case test.expected != nil && *tooLarge != *test.expected:
+			t.Errorf("%s: got %+v, expected %+v", test.name, *tooLarge, *test.expected)
+		case test.expected != nil && !errors.Is(err, ErrBodyTooLarge):
+			t.Errorf("%s: %v does not match ErrBodyTooLarge", test.name, err)
+		}
+		if sent != test.sent {
+			t.Errorf("%s: request sent %v, expected %v", test.name, sent, test.sent)
+		}
+	}
+}
+
+func TestUploadFileDeploymentLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.hyper")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sparse, so the file takes no space
+	if err := f.Truncate(max_cloud_publish_size + 1); err != nil {
+		f.Close()
+		t.Skip("can't create a sparse file:", err)
+	}
+	f.Close()
+
+	for _, optIn := range []bool{true, false} {
+		sent := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sent = true
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+		api.Deployment = DeploymentCloud
+		api.UseDeploymentLimits = optIn
+		_, err := api.UploadFile("s", path, UploadOptions{})
+		server.Close()
+		if optIn && (!errors.Is(err, ErrBodyTooLarge) || sent) {
+			t.Errorf("with UseDeploymentLimits: got error %v and sent %v, expected a BodyTooLargeError before sending", err, sent)
+		}
+		if !optIn && !sent {
+			t.Errorf("without UseDeploymentLimits: got error %v before sending", err)
+		}
+	}
+}
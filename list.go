@@ -0,0 +1,118 @@
+package tableau4go
+
+import "fmt"
+
+// the largest page Tableau will return
+const MAX_PAGE_SIZE = 1000
+
+// the ListOptions Fields value that returns every field a listing has
+const FIELDS_ALL = "_all_"
+
+// ListResponse is implemented by the responses of paged listings, e.g.
+// QueryViewsResponse, handing List and ListPage the items and pagination each
+// page carries.
+type ListResponse[T any] interface {
+	ListPage() ([]T, Pagination)
+}
+
+// ListPage fetches one page of the listing at path under the site, e.g.
+// "views" or "workbooks/<id>/revisions", decoding it as a response of type
+// R. A new listing endpoint only needs its response type and a ListPage
+// method on it:
+//
+//	func (r QueryViewsResponse) ListPage() ([]View, Pagination) {
+//		return r.Views.Views, r.Pagination
+//	}
+//
+//	views, pagination, err := tableau4go.ListPage[View, QueryViewsResponse](api, siteId, "views", opts)
+func ListPage[T any, R ListResponse[T]](api *API, siteId SiteID, path string, opts ListOptions) ([]T, Pagination, error) {
+	return listPageAt[T, R](api, fmt.Sprintf("%s/%s", api.siteUrl(siteId), path), opts)
+}
+
+// listPageAt is ListPage for the listing at url, which may be outside any
+// site, such as the server's schedules.
+func listPageAt[T any, R ListResponse[T]](api *API, url string, opts ListOptions) ([]T, Pagination, error) {
+	url += opts.query()
+	headers := make(map[string]string)
+	var retval R
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	items, pagination := retval.ListPage()
+	return items, pagination, err
+}
+
+// List is ListPage fetching every page, as many as needed at the largest
+// page size. opts's filter, sort and fields apply; its paging is ignored.
+// On an error the items of the pages fetched so far are returned with it.
+func List[T any, R ListResponse[T]](api *API, siteId SiteID, path string, opts ListOptions) ([]T, error) {
+	return listAt[T, R](api, fmt.Sprintf("%s/%s", api.siteUrl(siteId), path), opts)
+}
+
+// listAt is List for the listing at url.
+func listAt[T any, R ListResponse[T]](api *API, url string, opts ListOptions) ([]T, error) {
+	opts.PageSize, opts.PageNumber = MAX_PAGE_SIZE, 1
+	all := []T{}
+	for {
+		page, pagination, err := listPageAt[T, R](api, url, opts)
+		all = append(all, page...)
+		if err != nil || !pagination.More() {
+			return all, err
+		}
+		opts.PageNumber++
+	}
+}
+
+func (r QueryProjectsResponse) ListPage() ([]Project, Pagination) {
+	return r.Projects.Projects, r.Pagination
+}
+
+func (r QueryDatasourcesResponse) ListPage() ([]Datasource, Pagination) {
+	return r.Datasources.Datasources, r.Pagination
+}
+
+func (r QueryWorkbooksResponse) ListPage() ([]Workbook, Pagination) {
+	return r.Workbooks.Workbooks, r.Pagination
+}
+
+func (r QueryViewsResponse) ListPage() ([]View, Pagination) {
+	return r.Views.Views, r.Pagination
+}
+
+func (r QueryFlowsResponse) ListPage() ([]Flow, Pagination) {
+	return r.Flows.Flows, r.Pagination
+}
+
+func (r QueryRevisionsResponse) ListPage() ([]Revision, Pagination) {
+	return r.Revisions.Revisions, r.Pagination
+}
+
+func (r QuerySitesResponse) ListPage() ([]Site, Pagination) {
+	return r.Sites.Sites, r.Pagination
+}
+
+func (r QueryUsersResponse) ListPage() ([]User, Pagination) {
+	return r.Users.Users, r.Pagination
+}
+
+func (r GetUsersInGroupResponse) ListPage() ([]User, Pagination) {
+	return r.Users.Users, r.Pagination
+}
+
+func (r QueryGroupsResponse) ListPage() ([]Group, Pagination) {
+	return r.Groups.Groups, r.Pagination
+}
+
+func (r QueryJobsResponse) ListPage() ([]BackgroundJob, Pagination) {
+	return r.BackgroundJobs.BackgroundJobs, r.Pagination
+}
+
+func (r QuerySchedulesResponse) ListPage() ([]Schedule, Pagination) {
+	return r.Schedules.Schedules, r.Pagination
+}
+
+func (r QuerySubscriptionsResponse) ListPage() ([]Subscription, Pagination) {
+	return r.Subscriptions.Subscriptions, r.Pagination
+}
+
+func (r QueryDataAlertsResponse) ListPage() ([]DataAlert, Pagination) {
+	return r.DataAlerts.DataAlerts, r.Pagination
+}
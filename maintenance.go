@@ -0,0 +1,99 @@
+package tableau4go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var ErrServerMaintenance = errors.New("Server In Maintenance")
+
+// MaintenanceError is returned when the server answers 503 with Tableau's
+// maintenance page or a Retry-After header, as it does while TSM runs a
+// backup or an upgrade. It matches ErrServerMaintenance and, since the page is
+// not an API response, ErrNonAPIResponse.
+type MaintenanceError struct {
+	*NonAPIResponseError
+	// RetryAfter is the wait the server asked for, or 0 if it didn't say
+	RetryAfter time.Duration
+}
+
+func (e *MaintenanceError) Error() string {
+	return fmt.Sprintf("Server In Maintenance: %s", e.NonAPIResponseError.Error())
+}
+
+func (e *MaintenanceError) Unwrap() []error {
+	return []error{ErrServerMaintenance, e.NonAPIResponseError}
+}
+
+// MaintenanceWait makes calls that find the server in maintenance wait for it
+// to come back instead of failing, so a nightly batch that runs into a backup
+// window carries on afterwards. The client polls ServerInfo every Interval,
+// one minute by default, and retries the call once the server answers. It
+// gives up with the MaintenanceError after MaxWait, or when the call's
+// context is done if MaxWait is 0. OnWait, if set, is called as each wait
+// begins. Calls whose body can't be rewound are not retried.
+type MaintenanceWait struct {
+	Interval time.Duration
+	MaxWait  time.Duration
+	OnWait   func(err *MaintenanceError)
+}
+
+// isMaintenance reports whether resp is the server saying it is down for
+// maintenance, rather than a proxy failing in front of it.
+func isMaintenance(resp *Response) bool {
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+	return len(resp.Header.Get("Retry-After")) > 0 || bytes.Contains(bytes.ToLower(resp.Body), []byte("maintenance"))
+}
+
+func newMaintenanceError(resp *Response) *MaintenanceError {
+	maintenance := &MaintenanceError{NonAPIResponseError: newNonAPIResponseError(resp)}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		maintenance.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return maintenance
+}
+
+// waitForServer polls ServerInfo until the server answers, following
+// api.Maintenance. It returns maintenance if the wait runs out.
+func (api *API) waitForServer(maintenance *MaintenanceError) error {
+	policy := api.Maintenance
+	if policy.OnWait != nil {
+		policy.OnWait(maintenance)
+	}
+	interval := policy.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	var deadline time.Time
+	if policy.MaxWait > 0 {
+		deadline = api.now().Add(policy.MaxWait)
+	}
+	wait := maintenance.RetryAfter
+	if wait <= 0 {
+		wait = interval
+	}
+	url := api.serverInfoURL()
+	for {
+		if !deadline.IsZero() && api.now().Add(wait).After(deadline) {
+			return maintenance
+		}
+		if err := api.sleep(api.context(), wait); err != nil {
+			return err
+		}
+		// sendRequest rather than ServerInfo, which would wait by itself
+		err := api.sendRequest(url, GET, nil, &ServerInfoResponse{}, map[string]string{}, connectTimeOut, readWriteTimeout)
+		if err == nil {
+			return nil
+		}
+		if api.context().Err() != nil {
+			return api.context().Err()
+		}
+		wait = interval
+	}
+}
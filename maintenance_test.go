@@ -0,0 +1,140 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// an answer from the test server: a status and body, with a Retry-After
+// header when retryAfter isn't empty
+type answer struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+var (
+	answerOK          = answer{status: http.StatusOK, body: `<tsResponse><site id="s"/><serverInfo/></tsResponse>`}
+	answerBackup      = answer{status: http.StatusServiceUnavailable, retryAfter: "120", body: "<html>Down</html>"}
+	answerMaintenance = answer{status: http.StatusServiceUnavailable, body: "<html>Tableau Server is in maintenance mode</html>"}
+	answerProxy       = answer{status: http.StatusServiceUnavailable, body: "<html>Bad gateway</html>"}
+)
+
+func TestMaintenanceWait(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      *MaintenanceWait
+		calls       []answer
+		serverInfos []answer
+		waits       []time.Duration
+		onWait      int
+		err         error
+	}{
+		{
+			name:        "waits as long as Retry-After says",
+			policy:      &MaintenanceWait{},
+			calls:       []answer{answerBackup, answerOK},
+			serverInfos: []answer{answerOK},
+			waits:       []time.Duration{120 * time.Second},
+			onWait:      1,
+		},
+		{
+			name:        "polls every Interval until the server is back",
+			policy:      &MaintenanceWait{Interval: 10 * time.Second},
+			calls:       []answer{answerMaintenance, answerOK},
+			serverInfos: []answer{answerMaintenance, answerMaintenance, answerOK},
+			waits:       []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second},
+			onWait:      1,
+		},
+		{
+			name:        "polls every minute by default",
+			policy:      &MaintenanceWait{},
+			calls:       []answer{answerMaintenance, answerOK},
+			serverInfos: []answer{answerOK},
+			waits:       []time.Duration{time.Minute},
+			onWait:      1,
+		},
+		{
+			name:        "gives up after MaxWait",
+			policy:      &MaintenanceWait{Interval: 10 * time.Second, MaxWait: 25 * time.Second},
+			calls:       []answer{answerMaintenance},
+			serverInfos: []answer{answerMaintenance, answerMaintenance},
+			waits:       []time.Duration{10 * time.Second, 10 * time.Second},
+			onWait:      1,
+			err:         ErrServerMaintenance,
+		},
+		{
+			name:   "doesn't wait without a policy",
+			calls:  []answer{answerBackup},
+			waits:  []time.Duration{},
+			err:    ErrServerMaintenance,
+			onWait: 0,
+		},
+		{
+			name:   "doesn't wait for a failing proxy",
+			policy: &MaintenanceWait{},
+			calls:  []answer{answerProxy},
+			waits:  []time.Duration{},
+			err:    ErrNonAPIResponse,
+		},
+	}
+	for _, test := range tests {
+		calls, serverInfos := test.calls, test.serverInfos
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queue := &calls
+			if strings.HasSuffix(r.URL.Path, "/serverinfo") {
+				queue = &serverInfos
+			}
+			if len(*queue) == 0 {
+				t.Errorf("%s: unexpected request to %s", test.name, r.URL.Path)
+				w.WriteHeader(http.StatusTeapot)
+				return
+			}
+			a := (*queue)[0]
+			*queue = (*queue)[1:]
+			if len(a.retryAfter) > 0 {
+				w.Header().Set("Retry-After", a.retryAfter)
+			}
+			w.WriteHeader(a.status)
+			fmt.Fprint(w, a.body)
+		}))
+		api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+		clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+		api.Clock = clock
+		onWait := 0
+		if test.policy != nil {
+			policy := *test.policy
+			policy.OnWait = func(err *MaintenanceError) { onWait++ }
+			api.Maintenance = &policy
+		}
+		_, err := api.QuerySite("s", false)
+		server.Close()
+		if test.err == nil && err != nil {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, expected %v", test.name, err, test.err)
+		}
+		if test.err != ErrServerMaintenance && errors.Is(err, ErrServerMaintenance) {
+			t.Errorf("%s: %v should not be maintenance", test.name, err)
+		}
+		if clock.waits == nil {
+			clock.waits = []time.Duration{}
+		}
+		if !reflect.DeepEqual(clock.waits, test.waits) {
+			t.Errorf("%s: got waits %v, expected %v", test.name, clock.waits, test.waits)
+		}
+		if onWait != test.onWait {
+			t.Errorf("%s: OnWait called %d times, expected %d", test.name, onWait, test.onWait)
+		}
+		if len(calls) > 0 || len(serverInfos) > 0 {
+			t.Errorf("%s: %d call and %d ServerInfo answers not asked for", test.name, len(calls), len(serverInfos))
+		}
+	}
+}
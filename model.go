@@ -12,9 +12,14 @@
 package tableau4go
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const API_VERSION = "2.0"
@@ -22,13 +27,90 @@ const DEFAULT_SERVER = "http://localhost:8000"
 const BOUNDARY_STRING = "813e3160-3c95-11e5-a151-feff819cdc9f"
 const CRLF = "\r\n"
 
+// Typed LUIDs, so that a site ID can't be passed where a project ID is
+// expected without an explicit conversion.
+type SiteID string
+type ProjectID string
+type WorkbookID string
+type ViewID string
+type DatasourceID string
+type FlowID string
+type UserID string
+type GroupID string
+type GroupSetID string
+type JobID string
+type SubscriptionID string
+type ScheduleID string
+type DataAlertID string
+type WebhookID string
+type ConnectionID string
+
 type API struct {
-	Server              string
-	Version             string
+	Server  string
+	Version string
+	// Deprecated: multipart requests now get a random boundary each, checked
+	// not to occur in the content; Boundary is ignored.
 	Boundary            string
 	AuthToken           string
 	OmitDefaultSiteName bool
 	DefaultSiteName     string
+	CredentialProvider  CredentialProvider
+	DryRun              *DryRunPlan
+	Auditor             AuditSink
+	// Codec encodes requests and decodes responses; XMLCodec when nil
+	Codec Codec
+	// Redirects controls whether calls follow redirects. SessionCookie also
+	// sends the auth token as the workgroup_session_id cookie and keeps
+	// cookies set during the session, which some SSO front ends require.
+	Redirects     RedirectPolicy
+	SessionCookie bool
+	// MaxRequestBody and MaxResponseBody, when not 0, fail calls whose bodies
+	// are bigger with a BodyTooLargeError, so a service embedding the client
+	// can't be made to hold a huge download in memory by accident. Use
+	// WithoutBodyLimits for calls that are meant to be large.
+	MaxRequestBody  int64
+	MaxResponseBody int64
+	// UseDeploymentLimits applies the Deployment's limits where no explicit
+	// one is set: MaxRequestSize when MaxRequestBody is 0, and
+	// MaxPublishSize to UploadFile, so content the server would refuse fails
+	// before it is sent
+	UseDeploymentLimits bool
+	// Deployment is Cloud or Server; endpoints Cloud doesn't offer fail
+	// before a request is sent. NewAPI sets it with DetectDeployment.
+	Deployment Deployment
+	// Clock and Random replace the system clock and crypto/rand, so tests can
+	// make session expiry, retry backoff and multipart boundaries deterministic
+	Clock  Clock
+	Random io.Reader
+	// Maintenance, when set, makes calls wait out server maintenance windows
+	// instead of failing
+	Maintenance *MaintenanceWait
+	// Observer is told about every request, with the caller's context
+	Observer RequestObserver
+	// Budget, when set, counts requests per site and can hold calls to keep
+	// each site within a request budget
+	Budget *UsageBudget
+	// Breaker, when set, makes calls fail fast while the server is down
+	Breaker *CircuitBreaker
+	// ServerInfoTTL is how long ServerInfo answers from its cache, ten
+	// minutes when 0; negative turns the cache off
+	ServerInfoTTL time.Duration
+	// SessionIdleTimeout overrides the session lifetime reported at sign in;
+	// set it to the server's configured idle timeout when that is not reported
+	SessionIdleTimeout time.Duration
+	// SiteID, SiteContentUrl and UserID describe the site and user the current
+	// AuthToken is scoped to; they are set by Signin. A client may sign in
+	// again by itself when its token expires, so once it is shared between
+	// goroutines read them through CurrentSite and CurrentUser rather than
+	// directly
+	SiteID         SiteID
+	SiteContentUrl string
+	UserID         UserID
+	clock          *sessionClock
+	session        *sessionGuard
+	// ctx is set by WithContext; state is shared by every copy of the client
+	ctx   context.Context
+	state *clientState
 }
 
 func DefaultApi() API {
@@ -41,20 +123,115 @@ func NewAPI(server string, version string, boundary string, defaultSiteName stri
 	if strings.HasSuffix(server, "/") {
 		fixedUpServer = server[0 : len(server)-1]
 	}
-	return API{Server: fixedUpServer, Version: version, Boundary: boundary, DefaultSiteName: defaultSiteName, OmitDefaultSiteName: omitDefaultSiteName}
+	return API{Server: fixedUpServer, Version: version, Boundary: boundary, DefaultSiteName: defaultSiteName, OmitDefaultSiteName: omitDefaultSiteName, Deployment: DetectDeployment(fixedUpServer), state: newClientState(), session: &sessionGuard{}}
+}
+
+// CurrentSite returns the site the client is signed in to. Only ID and
+// ContentUrl are populated; use QuerySite for the rest.
+func (api *API) CurrentSite() Site {
+	session, _ := api.currentSession()
+	return Site{ID: session.SiteID, ContentUrl: session.SiteContentUrl}
+}
+
+// CurrentUser returns the signed in user. Only ID is populated; use
+// QueryUserOnSite for the rest.
+func (api *API) CurrentUser() User {
+	session, _ := api.currentSession()
+	return User{ID: session.UserID}
+}
+
+type Pagination struct {
+	PageNumber     int `json:"pageNumber,string,omitempty" xml:"pageNumber,attr,omitempty"`
+	PageSize       int `json:"pageSize,string,omitempty" xml:"pageSize,attr,omitempty"`
+	TotalAvailable int `json:"totalAvailable,string,omitempty" xml:"totalAvailable,attr,omitempty"`
 }
 
+// More reports whether pages after this one are available.
+func (p Pagination) More() bool {
+	return p.PageNumber*p.PageSize < p.TotalAvailable
+}
+
+// ListOptions controls paging, filtering and sorting of list calls. Zero
+// values leave the server defaults (page 1 of 100) in place. Filter and Sort
+// use Tableau's expression syntax, e.g. "name:eq:Finance" or "createdAt:desc".
+type ListOptions struct {
+	PageSize   int
+	PageNumber int
+	Filter     string
+	Sort       string
+	Fields     string
+	// only supported when querying views
+	IncludeUsageStatistics bool
+}
+
+func (o ListOptions) query() string {
+	params := url.Values{}
+	if o.PageSize > 0 {
+		params.Set("pageSize", strconv.Itoa(o.PageSize))
+	}
+	if o.PageNumber > 0 {
+		params.Set("pageNumber", strconv.Itoa(o.PageNumber))
+	}
+	if len(o.Filter) > 0 {
+		params.Set("filter", o.Filter)
+	}
+	if len(o.Sort) > 0 {
+		params.Set("sort", o.Sort)
+	}
+	if len(o.Fields) > 0 {
+		params.Set("fields", o.Fields)
+	}
+	if o.IncludeUsageStatistics {
+		params.Set("includeUsageStatistics", "true")
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + params.Encode()
+}
+
+// FilterValue escapes a value for a Filter expression, so a name holding the
+// commas that separate terms matches as it is:
+//
+//	opts := ListOptions{Filter: "name:eq:" + tableau4go.FilterValue(name)}
+func FilterValue(value string) string {
+	return filterValueEscaper.Replace(value)
+}
+
+var filterValueEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`)
+
 type Project struct {
-	ID          string `json:"id,omitempty" xml:"id,attr,omitempty"`
-	Name        string `json:"name,omitempty" xml:"name,attr,omitempty"`
-	Description string `json:"description,omitempty" xml:"description,attr,omitempty"`
+	ID                 ProjectID          `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name               string             `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Description        string             `json:"description,omitempty" xml:"description,attr,omitempty"`
+	ContentPermissions ContentPermissions `json:"contentPermissions,omitempty" xml:"contentPermissions,attr,omitempty"`
+	ParentProjectID    ProjectID          `json:"parentProjectId,omitempty" xml:"parentProjectId,attr,omitempty"`
+	Owner              *User              `json:"owner,omitempty" xml:"owner,omitempty"`
+	// reported by the server, and not sent when creating or updating; the
+	// owner's name and the controlling project are only filled in when
+	// queried with Fields FIELDS_ALL
+	CreatedAt                       string    `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt                       string    `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	TopLevelProject                 bool      `json:"topLevelProject,string,omitempty" xml:"topLevelProject,attr,omitempty"`
+	Writeable                       bool      `json:"writeable,omitempty" xml:"writeable,attr,omitempty"`
+	ControllingPermissionsProjectID ProjectID `json:"controllingPermissionsProjectId,omitempty" xml:"controllingPermissionsProjectId,attr,omitempty"`
+}
+
+// request returns the project without the fields the server reports but
+// doesn't accept.
+func (p Project) request() Project {
+	p.ID = ""
+	p.CreatedAt, p.UpdatedAt = "", ""
+	p.TopLevelProject, p.Writeable = false, false
+	p.ControllingPermissionsProjectID = ""
+	return p
 }
 
 type Projects struct {
 	Projects []Project `json:"project,omitempty" xml:"project,omitempty"`
 }
 
-func NewProject(id string, name string, description string) Project {
+func NewProject(id ProjectID, name string, description string) Project {
 	return Project{ID: id, Name: name, Description: description}
 }
 
@@ -91,12 +268,37 @@ func (req DatasourceCreateRequest) XML() ([]byte, error) {
 }
 
 type Datasource struct {
-	ID                    string                 `json:"id,omitempty" xml:"id,attr,omitempty"`
+	ID                    DatasourceID           `json:"id,omitempty" xml:"id,attr,omitempty"`
 	Name                  string                 `json:"name,omitempty" xml:"name,attr,omitempty"`
+	ContentUrl            string                 `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
 	Type                  string                 `json:"type,omitempty" xml:"type,attr,omitempty"`
+	Description           string                 `json:"description,omitempty" xml:"description,attr,omitempty"`
+	IsCertified           bool                   `json:"isCertified,string,omitempty" xml:"isCertified,attr,omitempty"`
+	CertificationNote     string                 `json:"certificationNote,omitempty" xml:"certificationNote,attr,omitempty"`
+	CreatedAt             string                 `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt             string                 `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
 	ConnectionCredentials *ConnectionCredentials `json:"connectionCredentials,omitempty" xml:"connectionCredentials,omitempty"`
 	Project               *Project               `json:"project,omitempty" xml:"project,omitempty"`
 	Owner                 *User                  `json:"owner,omitempty" xml:"owner,omitempty"`
+	Tags                  *Tags                  `json:"tags,omitempty" xml:"tags,omitempty"`
+	Usage                 *Usage                 `json:"usage,omitempty" xml:"usage,omitempty"`
+}
+
+// CertifyDatasourceRequest always sends isCertified, since uncertifying
+// needs an explicit false that Datasource would omit.
+type CertifyDatasourceRequest struct {
+	Request struct {
+		IsCertified       bool   `xml:"isCertified,attr"`
+		CertificationNote string `xml:"certificationNote,attr,omitempty"`
+	} `xml:"datasource"`
+}
+
+func (req CertifyDatasourceRequest) XML() ([]byte, error) {
+	tmp := struct {
+		CertifyDatasourceRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{CertifyDatasourceRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
 }
 
 type Datasources struct {
@@ -104,6 +306,7 @@ type Datasources struct {
 }
 
 type QueryDatasourcesResponse struct {
+	Pagination  Pagination  `json:"pagination,omitempty" xml:"pagination,omitempty"`
 	Datasources Datasources `json:"datasources,omitempty" xml:"datasources,omitempty"`
 }
 
@@ -111,6 +314,483 @@ func (ds *Datasource) XML() ([]byte, error) {
 	return xml.MarshalIndent(ds, "", "   ")
 }
 
+type DatasourceResponse struct {
+	Datasource Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+}
+
+// Connection is a published datasource's connection to its database. Password
+// is only sent, never returned.
+type Connection struct {
+	ID            ConnectionID `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Type          string       `json:"type,omitempty" xml:"type,attr,omitempty"`
+	ServerAddress string       `json:"serverAddress,omitempty" xml:"serverAddress,attr,omitempty"`
+	ServerPort    string       `json:"serverPort,omitempty" xml:"serverPort,attr,omitempty"`
+	UserName      string       `json:"userName,omitempty" xml:"userName,attr,omitempty"`
+	Password      string       `json:"password,omitempty" xml:"password,attr,omitempty"`
+	EmbedPassword bool         `json:"embedPassword" xml:"embedPassword,attr"`
+	// set in workbook connection listings: the datasource in the workbook the
+	// connection belongs to
+	Datasource *Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+}
+
+type Connections struct {
+	Connections []Connection `json:"connection,omitempty" xml:"connection,omitempty"`
+}
+
+type QueryConnectionsResponse struct {
+	Connections Connections `json:"connections,omitempty" xml:"connections,omitempty"`
+}
+
+type ConnectionResponse struct {
+	Connection Connection `json:"connection,omitempty" xml:"connection,omitempty"`
+}
+
+type UpdateConnectionRequest struct {
+	Request Connection `json:"connection,omitempty" xml:"connection,omitempty"`
+}
+
+func (req UpdateConnectionRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateConnectionRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateConnectionRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type FileUpload struct {
+	UploadSessionID string `json:"uploadSessionId,omitempty" xml:"uploadSessionId,attr,omitempty"`
+	FileSize        string `json:"fileSize,omitempty" xml:"fileSize,attr,omitempty"`
+}
+
+type FileUploadResponse struct {
+	FileUpload FileUpload `json:"fileUpload,omitempty" xml:"fileUpload,omitempty"`
+}
+
+type Workbook struct {
+	ID         WorkbookID `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name       string     `json:"name,omitempty" xml:"name,attr,omitempty"`
+	ContentUrl string     `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
+	ShowTabs   bool       `json:"showTabs,string,omitempty" xml:"showTabs,attr,omitempty"`
+	Size       int        `json:"size,string,omitempty" xml:"size,attr,omitempty"`
+	CreatedAt  string     `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt  string     `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Project    *Project   `json:"project,omitempty" xml:"project,omitempty"`
+	Owner      *User      `json:"owner,omitempty" xml:"owner,omitempty"`
+	Tags       *Tags      `json:"tags,omitempty" xml:"tags,omitempty"`
+	// only sent when publishing: the user whose permissions thumbnails are
+	// rendered with, and which views to hide
+	ThumbnailsUserID UserID `json:"thumbnailsUserId,omitempty" xml:"thumbnailsUserId,attr,omitempty"`
+	Views            *Views `json:"views,omitempty" xml:"views,omitempty"`
+	Usage            *Usage `json:"usage,omitempty" xml:"usage,omitempty"`
+}
+
+type View struct {
+	ID          ViewID    `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string    `json:"name,omitempty" xml:"name,attr,omitempty"`
+	ContentUrl  string    `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
+	ViewUrlName string    `json:"viewUrlName,omitempty" xml:"viewUrlName,attr,omitempty"`
+	CreatedAt   string    `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt   string    `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Workbook    *Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+	Project     *Project  `json:"project,omitempty" xml:"project,omitempty"`
+	Owner       *User     `json:"owner,omitempty" xml:"owner,omitempty"`
+	Tags        *Tags     `json:"tags,omitempty" xml:"tags,omitempty"`
+	Hidden      bool      `json:"hidden,string,omitempty" xml:"hidden,attr,omitempty"`
+	Usage       *Usage    `json:"usage,omitempty" xml:"usage,omitempty"`
+}
+
+// Usage is only returned when asked for with
+// ListOptions.IncludeUsageStatistics.
+type Usage struct {
+	TotalViewCount int `json:"totalViewCount,string,omitempty" xml:"totalViewCount,attr,omitempty"`
+	HitsTotal      int `json:"hitsTotal,string,omitempty" xml:"hitsTotal,attr,omitempty"`
+}
+
+type Views struct {
+	Views []View `json:"view,omitempty" xml:"view,omitempty"`
+}
+
+type QueryViewsResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Views      Views      `json:"views,omitempty" xml:"views,omitempty"`
+}
+
+// Revision is one published version of a workbook or datasource.
+type Revision struct {
+	RevisionNumber string `json:"revisionNumber,omitempty" xml:"revisionNumber,attr,omitempty"`
+	PublishedAt    string `json:"publishedAt,omitempty" xml:"publishedAt,attr,omitempty"`
+	Deleted        bool   `json:"deleted,string,omitempty" xml:"deleted,attr,omitempty"`
+	Current        bool   `json:"current,string,omitempty" xml:"current,attr,omitempty"`
+	SizeInBytes    int    `json:"sizeInBytes,string,omitempty" xml:"sizeInBytes,attr,omitempty"`
+	Publisher      *User  `json:"publisher,omitempty" xml:"publisher,omitempty"`
+}
+
+type Revisions struct {
+	Revisions []Revision `json:"revision,omitempty" xml:"revision,omitempty"`
+}
+
+type QueryRevisionsResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Revisions  Revisions  `json:"revisions,omitempty" xml:"revisions,omitempty"`
+}
+
+type Workbooks struct {
+	Workbooks []Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+type QueryWorkbooksResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Workbooks  Workbooks  `json:"workbooks,omitempty" xml:"workbooks,omitempty"`
+}
+
+type WorkbookResponse struct {
+	Workbook Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+type Release struct {
+	Name    string `json:"releaseName,omitempty" xml:"releaseName,attr,omitempty"`
+	Version string `json:"version,omitempty" xml:"version,attr,omitempty"`
+}
+
+type Releases struct {
+	Releases []Release `json:"release,omitempty" xml:"release,omitempty"`
+}
+
+type LostFeature struct {
+	Name   string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Impact string `json:"impact,omitempty" xml:"impact,attr,omitempty"`
+}
+
+type LostFeatures struct {
+	Features []LostFeature `json:"lostFeature,omitempty" xml:"lostFeature,omitempty"`
+}
+
+// DowngradeInfo lists what is lost when a workbook is downloaded for an
+// older version of Tableau Desktop. A workbook with no lost features opens as
+// is.
+type DowngradeInfo struct {
+	AssociatedReleases *Releases     `json:"associatedReleases,omitempty" xml:"associatedReleases,omitempty"`
+	LostFeatures       *LostFeatures `json:"lostFeatures,omitempty" xml:"lostFeatures,omitempty"`
+}
+
+func (info DowngradeInfo) Compatible() bool {
+	return info.LostFeatures == nil || len(info.LostFeatures.Features) == 0
+}
+
+type DowngradeInfoResponse struct {
+	DowngradeInfo DowngradeInfo `json:"downgradeInfo,omitempty" xml:"downgradeInfo,omitempty"`
+}
+
+// PublishWorkbookResponse holds a workbook, or a job when the workbook was
+// published asynchronously.
+type PublishWorkbookResponse struct {
+	Workbook *Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+	Job      *Job      `json:"job,omitempty" xml:"job,omitempty"`
+}
+
+type WorkbookCreateRequest struct {
+	Request Workbook `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+func (req WorkbookCreateRequest) XML() ([]byte, error) {
+	tmp := struct {
+		WorkbookCreateRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{WorkbookCreateRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type Job struct {
+	ID          JobID  `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Mode        string `json:"mode,omitempty" xml:"mode,attr,omitempty"`
+	Type        string `json:"type,omitempty" xml:"type,attr,omitempty"`
+	Progress    int    `json:"progress,string,omitempty" xml:"progress,attr,omitempty"`
+	FinishCode  int    `json:"finishCode,string,omitempty" xml:"finishCode,attr,omitempty"`
+	CreatedAt   string `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	StartedAt   string `json:"startedAt,omitempty" xml:"startedAt,attr,omitempty"`
+	CompletedAt string `json:"completedAt,omitempty" xml:"completedAt,attr,omitempty"`
+	// set on extract refresh jobs returned by QueryJob
+	ExtractRefreshJob *ExtractRefreshJob `json:"extractRefreshJob,omitempty" xml:"extractRefreshJob,omitempty"`
+}
+
+type ExtractRefreshJob struct {
+	Notes      string      `json:"notes,omitempty" xml:"notes,attr,omitempty"`
+	Datasource *Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+	Workbook   *Workbook   `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+// BackgroundJob is a job as listed by QueryJobs, which reports less than
+// QueryJob does for a single job.
+type BackgroundJob struct {
+	ID        JobID  `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Status    string `json:"status,omitempty" xml:"status,attr,omitempty"`
+	JobType   string `json:"jobType,omitempty" xml:"jobType,attr,omitempty"`
+	Priority  int    `json:"priority,string,omitempty" xml:"priority,attr,omitempty"`
+	Title     string `json:"title,omitempty" xml:"title,attr,omitempty"`
+	Subtitle  string `json:"subtitle,omitempty" xml:"subtitle,attr,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	StartedAt string `json:"startedAt,omitempty" xml:"startedAt,attr,omitempty"`
+	EndedAt   string `json:"endedAt,omitempty" xml:"endedAt,attr,omitempty"`
+}
+
+type BackgroundJobs struct {
+	BackgroundJobs []BackgroundJob `json:"backgroundJob,omitempty" xml:"backgroundJob,omitempty"`
+}
+
+type QueryJobsResponse struct {
+	Pagination     Pagination     `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	BackgroundJobs BackgroundJobs `json:"backgroundJobs,omitempty" xml:"backgroundJobs,omitempty"`
+}
+
+type JobResponse struct {
+	Job Job `json:"job,omitempty" xml:"job,omitempty"`
+}
+
+type ExtractDatasource struct {
+	ID DatasourceID `json:"id,omitempty" xml:"id,attr,omitempty"`
+}
+
+type ExtractDatasources struct {
+	IncludeAll  bool                `json:"includeAll,omitempty" xml:"includeAll,attr,omitempty"`
+	Datasources []ExtractDatasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+}
+
+type ExtractDatasourcesRequest struct {
+	Request ExtractDatasources `json:"datasources,omitempty" xml:"datasources,omitempty"`
+}
+
+func newExtractDatasourcesRequest(datasourceIds []DatasourceID) ExtractDatasourcesRequest {
+	if len(datasourceIds) == 0 {
+		return ExtractDatasourcesRequest{Request: ExtractDatasources{IncludeAll: true}}
+	}
+	request := ExtractDatasourcesRequest{}
+	for _, id := range datasourceIds {
+		request.Request.Datasources = append(request.Request.Datasources, ExtractDatasource{ID: id})
+	}
+	return request
+}
+
+func (req ExtractDatasourcesRequest) XML() ([]byte, error) {
+	tmp := struct {
+		ExtractDatasourcesRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{ExtractDatasourcesRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type Flow struct {
+	ID          FlowID   `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string   `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Description string   `json:"description,omitempty" xml:"description,attr,omitempty"`
+	WebpageUrl  string   `json:"webpageUrl,omitempty" xml:"webpageUrl,attr,omitempty"`
+	FileType    string   `json:"fileType,omitempty" xml:"fileType,attr,omitempty"`
+	CreatedAt   string   `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt   string   `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Project     *Project `json:"project,omitempty" xml:"project,omitempty"`
+	Owner       *User    `json:"owner,omitempty" xml:"owner,omitempty"`
+	Tags        *Tags    `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+type Flows struct {
+	Flows []Flow `json:"flow,omitempty" xml:"flow,omitempty"`
+}
+
+type QueryFlowsResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Flows      Flows      `json:"flows,omitempty" xml:"flows,omitempty"`
+}
+
+type FlowResponse struct {
+	Flow Flow `json:"flow,omitempty" xml:"flow,omitempty"`
+}
+
+type FlowRequest struct {
+	Request Flow `json:"flow,omitempty" xml:"flow,omitempty"`
+}
+
+func (req FlowRequest) XML() ([]byte, error) {
+	tmp := struct {
+		FlowRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{FlowRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type Group struct {
+	ID   GroupID `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name string  `json:"name,omitempty" xml:"name,attr,omitempty"`
+}
+
+type Groups struct {
+	Groups []Group `json:"group,omitempty" xml:"group,omitempty"`
+}
+
+type QueryGroupsResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Groups     Groups     `json:"groups,omitempty" xml:"groups,omitempty"`
+}
+
+type GroupResponse struct {
+	Group Group `json:"group,omitempty" xml:"group,omitempty"`
+}
+
+type CreateGroupRequest struct {
+	Request Group `json:"group,omitempty" xml:"group,omitempty"`
+}
+
+func (req CreateGroupRequest) XML() ([]byte, error) {
+	tmp := struct {
+		CreateGroupRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{CreateGroupRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type AddUserToGroupRequest struct {
+	Request User `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+func (req AddUserToGroupRequest) XML() ([]byte, error) {
+	tmp := struct {
+		AddUserToGroupRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{AddUserToGroupRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type Users struct {
+	Users []User `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+type QueryUsersResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Users      Users      `json:"users,omitempty" xml:"users,omitempty"`
+}
+
+type GetUsersInGroupResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Users      Users      `json:"users,omitempty" xml:"users,omitempty"`
+}
+
+// GroupSet bundles groups so licenses can be granted to their members as a
+// whole. GrantLicenseMode is "onLogin" or "onSync", and SiteRole is the minimum
+// role members are granted.
+type GroupSet struct {
+	ID               GroupSetID `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name             string     `json:"name,omitempty" xml:"name,attr,omitempty"`
+	GrantLicenseMode string     `json:"grantLicenseMode,omitempty" xml:"grantLicenseMode,attr,omitempty"`
+	SiteRole         SiteRole   `json:"siteRole,omitempty" xml:"siteRole,attr,omitempty"`
+	GroupCount       int        `json:"groupCount,string,omitempty" xml:"groupCount,attr,omitempty"`
+	Groups           []Group    `json:"group,omitempty" xml:"group,omitempty"`
+}
+
+type GroupSets struct {
+	GroupSets []GroupSet `json:"groupSet,omitempty" xml:"groupSet,omitempty"`
+}
+
+type QueryGroupSetsResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	GroupSets  GroupSets  `json:"groupSets,omitempty" xml:"groupSets,omitempty"`
+}
+
+type GroupSetResponse struct {
+	GroupSet GroupSet `json:"groupSet,omitempty" xml:"groupSet,omitempty"`
+}
+
+type GroupSetRequest struct {
+	Request GroupSet `json:"groupSet,omitempty" xml:"groupSet,omitempty"`
+}
+
+func (req GroupSetRequest) XML() ([]byte, error) {
+	tmp := struct {
+		GroupSetRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{GroupSetRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+// GroupUsersOptions pages through a group's members, optionally keeping only
+// users with the given site role.
+type GroupUsersOptions struct {
+	ListOptions
+	SiteRole SiteRole
+}
+
+type Capability struct {
+	Name string         `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Mode CapabilityMode `json:"mode,omitempty" xml:"mode,attr,omitempty"`
+}
+
+type Capabilities struct {
+	Capabilities []Capability `json:"capability,omitempty" xml:"capability,omitempty"`
+}
+
+// GranteeCapabilities holds the capabilities granted to either a group or a user.
+type GranteeCapabilities struct {
+	Group        *Group       `json:"group,omitempty" xml:"group,omitempty"`
+	User         *User        `json:"user,omitempty" xml:"user,omitempty"`
+	Capabilities Capabilities `json:"capabilities,omitempty" xml:"capabilities,omitempty"`
+}
+
+type Permissions struct {
+	Project             *Project              `json:"project,omitempty" xml:"project,omitempty"`
+	Workbook            *Workbook             `json:"workbook,omitempty" xml:"workbook,omitempty"`
+	Datasource          *Datasource           `json:"datasource,omitempty" xml:"datasource,omitempty"`
+	GranteeCapabilities []GranteeCapabilities `json:"granteeCapabilities,omitempty" xml:"granteeCapabilities,omitempty"`
+}
+
+type PermissionsResponse struct {
+	Permissions Permissions `json:"permissions,omitempty" xml:"permissions,omitempty"`
+}
+
+type PermissionsRequest struct {
+	Request Permissions `json:"permissions,omitempty" xml:"permissions,omitempty"`
+}
+
+func (req PermissionsRequest) XML() ([]byte, error) {
+	tmp := struct {
+		PermissionsRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{PermissionsRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type Tag struct {
+	Label string `json:"label,omitempty" xml:"label,attr,omitempty"`
+}
+
+type Tags struct {
+	Tags []Tag `json:"tag,omitempty" xml:"tag,omitempty"`
+}
+
+// Labels returns the tag labels, or nil if t is nil.
+func (t *Tags) Labels() []string {
+	if t == nil {
+		return nil
+	}
+	labels := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		labels = append(labels, tag.Label)
+	}
+	return labels
+}
+
+type TagsResponse struct {
+	Tags Tags `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+type TagsRequest struct {
+	Request Tags `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+func (req TagsRequest) XML() ([]byte, error) {
+	tmp := struct {
+		TagsRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{TagsRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
 type SigninRequest struct {
 	Request Credentials `json:"credentials,omitempty" xml:"credentials,omitempty"`
 }
@@ -137,26 +817,354 @@ type ServerInfo struct {
 }
 
 type QueryProjectsResponse struct {
-	Projects Projects `json:"projects,omitempty" xml:"projects,omitempty"`
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Projects   Projects   `json:"projects,omitempty" xml:"projects,omitempty"`
 }
 
 type Credentials struct {
-	Name        string `json:"name,omitempty" xml:"name,attr,omitempty"`
-	Password    string `json:"password,omitempty" xml:"password,attr,omitempty"`
-	Token       string `json:"token,omitempty" xml:"token,attr,omitempty"`
-	Site        *Site  `json:"site,omitempty" xml:"site,omitempty"`
-	Impersonate *User  `json:"user,omitempty" xml:"user,omitempty"`
+	Name                      string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Password                  string `json:"password,omitempty" xml:"password,attr,omitempty"`
+	PersonalAccessTokenName   string `json:"personalAccessTokenName,omitempty" xml:"personalAccessTokenName,attr,omitempty"`
+	PersonalAccessTokenSecret string `json:"personalAccessTokenSecret,omitempty" xml:"personalAccessTokenSecret,attr,omitempty"`
+	JWT                       string `json:"jwt,omitempty" xml:"jwt,attr,omitempty"`
+	Token                     string `json:"token,omitempty" xml:"token,attr,omitempty"`
+	EstimatedTimeToExpiration string `json:"estimatedTimeToExpiration,omitempty" xml:"estimatedTimeToExpiration,attr,omitempty"`
+	Site                      *Site  `json:"site,omitempty" xml:"site,omitempty"`
+	Impersonate               *User  `json:"user,omitempty" xml:"user,omitempty"`
 }
 
 type User struct {
-	ID string `json:"id,omitempty" xml:"id,attr,omitempty"`
-	Name string `json:"name,omitempty" xml:"name,attr,omitempty"`
-	SiteRole string `json:"siteRole,omitempty" xml:"siteRole,attr,omitempty"`
-	FullName string `json:"fullName,omitempty" xml:"fullName,attr,omitempty"`
+	ID          UserID   `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string   `json:"name,omitempty" xml:"name,attr,omitempty"`
+	SiteRole    SiteRole `json:"siteRole,omitempty" xml:"siteRole,attr,omitempty"`
+	FullName    string   `json:"fullName,omitempty" xml:"fullName,attr,omitempty"`
+	Email       string   `json:"email,omitempty" xml:"email,attr,omitempty"`
+	AuthSetting string   `json:"authSetting,omitempty" xml:"authSetting,attr,omitempty"`
+	// the language and locale the user's views, exports and subscription
+	// emails are rendered in; reported by the server but not accepted when
+	// adding or updating users
+	Language string `json:"language,omitempty" xml:"language,attr,omitempty"`
+	Locale   string `json:"locale,omitempty" xml:"locale,attr,omitempty"`
+}
+
+// AddUserOptions control what a new user is sent. The server emails invites
+// and getting started notifications by default; they are suppressed unless
+// SendInvite is set, so provisioning users in bulk doesn't email each of them
+// by accident. IfExists says what happens when a user of the same name is
+// already on the site.
+type AddUserOptions struct {
+	SendInvite bool
+	IfExists   IfExists
+}
+
+func (o AddUserOptions) query() string {
+	if o.SendInvite {
+		return ""
+	}
+	return "?suppressGettingStartedNotifications=true"
+}
+
+type AddUserToSiteRequest struct {
+	Request User `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+func (req AddUserToSiteRequest) XML() ([]byte, error) {
+	tmp := struct {
+		AddUserToSiteRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{AddUserToSiteRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type UpdateUserRequest struct {
+	Request User `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+func (req UpdateUserRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateUserRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateUserRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type SubscriptionContent struct {
+	ID   string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Type string `json:"type,omitempty" xml:"type,attr,omitempty"`
+}
+
+type Schedule struct {
+	ID               ScheduleID        `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name             string            `json:"name,omitempty" xml:"name,attr,omitempty"`
+	State            ScheduleState     `json:"state,omitempty" xml:"state,attr,omitempty"`
+	Priority         int               `json:"priority,string,omitempty" xml:"priority,attr,omitempty"`
+	Type             ScheduleType      `json:"type,omitempty" xml:"type,attr,omitempty"`
+	Frequency        ScheduleFrequency `json:"frequency,omitempty" xml:"frequency,attr,omitempty"`
+	ExecutionOrder   ExecutionOrder    `json:"executionOrder,omitempty" xml:"executionOrder,attr,omitempty"`
+	CreatedAt        string            `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt        string            `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	NextRunAt        string            `json:"nextRunAt,omitempty" xml:"nextRunAt,attr,omitempty"`
+	FrequencyDetails *FrequencyDetails `json:"frequencyDetails,omitempty" xml:"frequencyDetails,omitempty"`
+}
+
+// FrequencyDetails says when a schedule runs. Start and End are wall clock
+// times, "15:04:05"; End bounds hourly schedules and is empty otherwise.
+type FrequencyDetails struct {
+	Start     string     `json:"start,omitempty" xml:"start,attr,omitempty"`
+	End       string     `json:"end,omitempty" xml:"end,attr,omitempty"`
+	Intervals *Intervals `json:"intervals,omitempty" xml:"intervals,omitempty"`
+}
+
+type Intervals struct {
+	Intervals []Interval `json:"interval,omitempty" xml:"interval,omitempty"`
+}
+
+// Interval is one part of a schedule's frequency: Hours or Minutes between
+// runs of an hourly schedule, a WeekDay such as "Monday" a schedule runs on,
+// or a MonthDay, "1" to "31" or "LastDay".
+type Interval struct {
+	Hours    string `json:"hours,omitempty" xml:"hours,attr,omitempty"`
+	Minutes  string `json:"minutes,omitempty" xml:"minutes,attr,omitempty"`
+	WeekDay  string `json:"weekDay,omitempty" xml:"weekDay,attr,omitempty"`
+	MonthDay string `json:"monthDay,omitempty" xml:"monthDay,attr,omitempty"`
+}
+
+type Schedules struct {
+	Schedules []Schedule `json:"schedule,omitempty" xml:"schedule,omitempty"`
+}
+
+type QuerySchedulesResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Schedules  Schedules  `json:"schedules,omitempty" xml:"schedules,omitempty"`
+}
+
+type ScheduleResponse struct {
+	Schedule Schedule `json:"schedule,omitempty" xml:"schedule,omitempty"`
+}
+
+// ExtractRefreshTask refreshes a datasource or workbook extract on a schedule.
+type ExtractRefreshTask struct {
+	ID         string      `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Priority   int         `json:"priority,string,omitempty" xml:"priority,attr,omitempty"`
+	Type       string      `json:"type,omitempty" xml:"type,attr,omitempty"`
+	Schedule   *Schedule   `json:"schedule,omitempty" xml:"schedule,omitempty"`
+	Datasource *Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+	Workbook   *Workbook   `json:"workbook,omitempty" xml:"workbook,omitempty"`
+}
+
+type Task struct {
+	ExtractRefresh *ExtractRefreshTask `json:"extractRefresh,omitempty" xml:"extractRefresh,omitempty"`
+}
+
+type Tasks struct {
+	Tasks []Task `json:"task,omitempty" xml:"task,omitempty"`
+}
+
+type QueryTasksResponse struct {
+	Tasks Tasks `json:"tasks,omitempty" xml:"tasks,omitempty"`
+}
+
+type TaskResponse struct {
+	Task Task `json:"task,omitempty" xml:"task,omitempty"`
+}
+
+type AddToScheduleRequest struct {
+	Request Task `json:"task,omitempty" xml:"task,omitempty"`
+}
+
+func (req AddToScheduleRequest) XML() ([]byte, error) {
+	tmp := struct {
+		AddToScheduleRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{AddToScheduleRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type UpdateScheduleRequest struct {
+	Request Schedule `json:"schedule,omitempty" xml:"schedule,omitempty"`
+}
+
+func (req UpdateScheduleRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateScheduleRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateScheduleRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type CreateScheduleRequest struct {
+	Request Schedule `json:"schedule,omitempty" xml:"schedule,omitempty"`
+}
+
+func (req CreateScheduleRequest) XML() ([]byte, error) {
+	tmp := struct {
+		CreateScheduleRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{CreateScheduleRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+// Subscription attachment options are kept as the "true"/"false" strings the
+// server sends, so copying a subscription preserves them exactly.
+type Subscription struct {
+	ID              SubscriptionID       `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Subject         string               `json:"subject,omitempty" xml:"subject,attr,omitempty"`
+	Message         string               `json:"message,omitempty" xml:"message,attr,omitempty"`
+	AttachImage     string               `json:"attachImage,omitempty" xml:"attachImage,attr,omitempty"`
+	AttachPdf       string               `json:"attachPdf,omitempty" xml:"attachPdf,attr,omitempty"`
+	PageOrientation string               `json:"pageOrientation,omitempty" xml:"pageOrientation,attr,omitempty"`
+	PageSizeOption  string               `json:"pageSizeOption,omitempty" xml:"pageSizeOption,attr,omitempty"`
+	SendIfViewEmpty string               `json:"sendIfViewEmpty,omitempty" xml:"sendIfViewEmpty,attr,omitempty"`
+	Suspended       string               `json:"suspended,omitempty" xml:"suspended,attr,omitempty"`
+	Content         *SubscriptionContent `json:"content,omitempty" xml:"content,omitempty"`
+	Schedule        *Schedule            `json:"schedule,omitempty" xml:"schedule,omitempty"`
+	User            *User                `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+type SubscriptionResponse struct {
+	Subscription Subscription `json:"subscription,omitempty" xml:"subscription,omitempty"`
+}
+
+type SubscriptionRequest struct {
+	Request Subscription `json:"subscription,omitempty" xml:"subscription,omitempty"`
+}
+
+func (req SubscriptionRequest) XML() ([]byte, error) {
+	tmp := struct {
+		SubscriptionRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{SubscriptionRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type Subscriptions struct {
+	Subscriptions []Subscription `json:"subscription,omitempty" xml:"subscription,omitempty"`
+}
+
+type QuerySubscriptionsResponse struct {
+	Pagination    Pagination    `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Subscriptions Subscriptions `json:"subscriptions,omitempty" xml:"subscriptions,omitempty"`
+}
+
+type DataAlert struct {
+	ID        DataAlertID `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Subject   string      `json:"subject,omitempty" xml:"subject,attr,omitempty"`
+	Frequency string      `json:"frequency,omitempty" xml:"frequency,attr,omitempty"`
+	Public    bool        `json:"public,omitempty" xml:"public,attr,omitempty"`
+	CreatedAt string      `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt string      `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Owner     *User       `json:"owner,omitempty" xml:"owner,omitempty"`
+	View      *View       `json:"view,omitempty" xml:"view,omitempty"`
+}
+
+// Webhook posts Event to the destination url whenever it happens on the
+// site. Event is one of the EventType names.
+type Webhook struct {
+	ID          WebhookID           `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string              `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Event       string              `json:"event,omitempty" xml:"event,attr,omitempty"`
+	Destination *WebhookDestination `json:"webhook-destination,omitempty" xml:"webhook-destination,omitempty"`
+	Owner       *User               `json:"owner,omitempty" xml:"owner,omitempty"`
+}
+
+type WebhookDestination struct {
+	HTTP *WebhookDestinationHTTP `json:"webhook-destination-http,omitempty" xml:"webhook-destination-http,omitempty"`
+}
+
+type WebhookDestinationHTTP struct {
+	Method string `json:"method,omitempty" xml:"method,attr,omitempty"`
+	URL    string `json:"url,omitempty" xml:"url,attr,omitempty"`
+}
+
+type Webhooks struct {
+	Webhooks []Webhook `json:"webhook,omitempty" xml:"webhook,omitempty"`
+}
+
+type QueryWebhooksResponse struct {
+	Webhooks Webhooks `json:"webhooks,omitempty" xml:"webhooks,omitempty"`
+}
+
+type WebhookResponse struct {
+	Webhook Webhook `json:"webhook,omitempty" xml:"webhook,omitempty"`
+}
+
+type WebhookRequest struct {
+	Request Webhook `json:"webhook,omitempty" xml:"webhook,omitempty"`
+}
+
+func (req WebhookRequest) XML() ([]byte, error) {
+	tmp := struct {
+		WebhookRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{WebhookRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type DataAlerts struct {
+	DataAlerts []DataAlert `json:"dataAlert,omitempty" xml:"dataAlert,omitempty"`
+}
+
+type QueryDataAlertsResponse struct {
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	DataAlerts DataAlerts `json:"dataAlerts,omitempty" xml:"dataAlerts,omitempty"`
+}
+
+type Favorite struct {
+	Label      string      `json:"label,omitempty" xml:"label,attr,omitempty"`
+	Position   int         `json:"position,omitempty" xml:"position,attr,omitempty"`
+	View       *View       `json:"view,omitempty" xml:"view,omitempty"`
+	Workbook   *Workbook   `json:"workbook,omitempty" xml:"workbook,omitempty"`
+	Datasource *Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+	Project    *Project    `json:"project,omitempty" xml:"project,omitempty"`
+	Flow       *Flow       `json:"flow,omitempty" xml:"flow,omitempty"`
+}
+
+type Favorites struct {
+	Favorites []Favorite `json:"favorite,omitempty" xml:"favorite,omitempty"`
+}
+
+type FavoritesResponse struct {
+	Favorites Favorites `json:"favorites,omitempty" xml:"favorites,omitempty"`
+}
+
+type AddFavoriteRequest struct {
+	Request Favorite `json:"favorite,omitempty" xml:"favorite,omitempty"`
+}
+
+func (req AddFavoriteRequest) XML() ([]byte, error) {
+	tmp := struct {
+		AddFavoriteRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{AddFavoriteRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type FavoriteOrder struct {
+	FavoriteID            string `json:"favoriteId,omitempty" xml:"favoriteId,attr,omitempty"`
+	FavoriteType          string `json:"favoriteType,omitempty" xml:"favoriteType,attr,omitempty"`
+	FavoriteIDMoveAfter   string `json:"favoriteIdMoveAfter,omitempty" xml:"favoriteIdMoveAfter,attr,omitempty"`
+	FavoriteTypeMoveAfter string `json:"favoriteTypeMoveAfter,omitempty" xml:"favoriteTypeMoveAfter,attr,omitempty"`
+}
+
+type FavoriteOrderings struct {
+	FavoriteOrders []FavoriteOrder `json:"favoriteOrder,omitempty" xml:"favoriteOrder,omitempty"`
+}
+
+type OrderFavoritesRequest struct {
+	Request FavoriteOrderings `json:"favoriteOrderings,omitempty" xml:"favoriteOrderings,omitempty"`
+}
+
+func (req OrderFavoritesRequest) XML() ([]byte, error) {
+	tmp := struct {
+		OrderFavoritesRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{OrderFavoritesRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
 }
 
 type QuerySitesResponse struct {
-	Sites Sites `json:"sites,omitempty" xml:"sites,omitempty"`
+	Pagination Pagination `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Sites      Sites      `json:"sites,omitempty" xml:"sites,omitempty"`
 }
 
 func (req QuerySitesResponse) XML() ([]byte, error) {
@@ -168,7 +1176,7 @@ func (req QuerySitesResponse) XML() ([]byte, error) {
 }
 
 type Sites struct {
-	Sites []Site `json:"sites" xml:"sites,attr"`
+	Sites []Site `json:"site,omitempty" xml:"site,omitempty"`
 }
 
 type QuerySiteResponse struct {
@@ -196,15 +1204,42 @@ func (req QueryUserOnSiteResponse) XML() ([]byte, error) {
 }
 
 type Site struct {
-	ID           string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	ID           SiteID     `json:"id,omitempty" xml:"id,attr,omitempty"`
 	Name         string     `json:"name,omitempty" xml:"name,attr,omitempty"`
 	ContentUrl   string     `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
 	AdminMode    string     `json:"adminMode,omitempty" xml:"adminMode,attr,omitempty"`
 	UserQuota    string     `json:"userQuota,omitempty" xml:"userQuota,attr,omitempty"`
-	StorageQuota int        `json:"storageQuota,omitempty" xml:"storageQuota,attr,omitempty"`
+	StorageQuota int        `json:"storageQuota,string,omitempty" xml:"storageQuota,attr,omitempty"`
 	State        string     `json:"state,omitempty" xml:"state,attr,omitempty"`
 	StatusReason string     `json:"statusReason,omitempty" xml:"statusReason,attr,omitempty"`
 	Usage        *SiteUsage `json:"usage,omitempty" xml:"usage,omitempty"`
+	// SuppressGettingStartedNotifications stops the getting started and
+	// welcome emails new users are sent; nil leaves the setting alone on update
+	SuppressGettingStartedNotifications *bool `json:"suppressGettingStartedNotifications,omitempty" xml:"suppressGettingStartedNotifications,attr,omitempty"`
+}
+
+type UpdateSiteRequest struct {
+	Request Site `json:"site,omitempty" xml:"site,omitempty"`
+}
+
+func (req UpdateSiteRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateSiteRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateSiteRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type CreateSiteRequest struct {
+	Request Site `json:"site,omitempty" xml:"site,omitempty"`
+}
+
+func (req CreateSiteRequest) XML() ([]byte, error) {
+	tmp := struct {
+		CreateSiteRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{CreateSiteRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
 }
 
 type SiteUsage struct {
@@ -212,6 +1247,133 @@ type SiteUsage struct {
 	Storage       int `json:"storage" xml:"storage,attr"`
 }
 
+// Domain is an Active Directory domain known to the server. Name is the full
+// domain name and Nickname the short NetBIOS style name.
+type Domain struct {
+	ID       int    `json:"id,string,omitempty" xml:"id,attr,omitempty"`
+	Name     string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Nickname string `json:"nickname,omitempty" xml:"nickname,attr,omitempty"`
+}
+
+type DomainResponse struct {
+	Domain Domain `json:"domain,omitempty" xml:"domain,omitempty"`
+}
+
+type DomainRequest struct {
+	Request Domain `json:"domain,omitempty" xml:"domain,omitempty"`
+}
+
+func (req DomainRequest) XML() ([]byte, error) {
+	tmp := struct {
+		DomainRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{DomainRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type MobileSecuritySetting struct {
+	Name    string `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Enabled bool   `json:"enabled" xml:"enabled,attr"`
+	Value   string `json:"value,omitempty" xml:"value,attr,omitempty"`
+}
+
+type MobileSecuritySettings struct {
+	Settings []MobileSecuritySetting `json:"mobileSecuritySetting,omitempty" xml:"mobileSecuritySetting,omitempty"`
+}
+
+type MobileSecuritySettingsResponse struct {
+	Settings MobileSecuritySettings `json:"mobileSecuritySettingsList,omitempty" xml:"mobileSecuritySettingsList,omitempty"`
+}
+
+type MobileSecuritySettingsRequest struct {
+	Request MobileSecuritySettings `json:"mobileSecuritySettingsList,omitempty" xml:"mobileSecuritySettingsList,omitempty"`
+}
+
+func (req MobileSecuritySettingsRequest) XML() ([]byte, error) {
+	tmp := struct {
+		MobileSecuritySettingsRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{MobileSecuritySettingsRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+// EmbeddingSettings controls where a site's views may be embedded. When
+// UnrestrictedEmbedding is false, AllowList holds the space separated domains
+// that may embed content.
+type EmbeddingSettings struct {
+	UnrestrictedEmbedding bool   `json:"unrestrictedEmbedding" xml:"unrestrictedEmbedding,attr"`
+	AllowList             string `json:"allowList,omitempty" xml:"allowList,attr,omitempty"`
+}
+
+type EmbeddingSettingsResponse struct {
+	Settings EmbeddingSettings `json:"siteEmbeddingSettings,omitempty" xml:"siteEmbeddingSettings,omitempty"`
+}
+
+type EmbeddingSettingsRequest struct {
+	Request EmbeddingSettings `json:"siteEmbeddingSettings,omitempty" xml:"siteEmbeddingSettings,omitempty"`
+}
+
+func (req EmbeddingSettingsRequest) XML() ([]byte, error) {
+	tmp := struct {
+		EmbeddingSettingsRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{EmbeddingSettingsRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+// ExtensionsServerSettings turns dashboard extensions on or off for the whole
+// server; BlockList holds extension URLs that no site may use.
+type ExtensionsServerSettings struct {
+	ExtensionsGloballyEnabled bool     `json:"extensionsGloballyEnabled" xml:"extensionsGloballyEnabled,attr"`
+	BlockList                 []string `json:"blockList,omitempty" xml:"blockList,omitempty"`
+}
+
+type ExtensionsServerSettingsResponse struct {
+	Settings ExtensionsServerSettings `json:"extensionsServerSettings,omitempty" xml:"extensionsServerSettings,omitempty"`
+}
+
+type ExtensionsServerSettingsRequest struct {
+	Request ExtensionsServerSettings `json:"extensionsServerSettings,omitempty" xml:"extensionsServerSettings,omitempty"`
+}
+
+func (req ExtensionsServerSettingsRequest) XML() ([]byte, error) {
+	tmp := struct {
+		ExtensionsServerSettingsRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{ExtensionsServerSettingsRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+// SafeExtension is a safe list entry: an extension URL and what it may do
+// without asking the user.
+type SafeExtension struct {
+	URL             string `json:"url,omitempty" xml:"url,attr,omitempty"`
+	FullDataAllowed bool   `json:"fullDataAllowed" xml:"fullDataAllowed,attr"`
+	PromptNeeded    bool   `json:"promptNeeded" xml:"promptNeeded,attr"`
+}
+
+type ExtensionsSiteSettings struct {
+	ExtensionsEnabled bool            `json:"extensionsEnabled" xml:"extensionsEnabled,attr"`
+	UseDefaultSetting bool            `json:"useDefaultSetting" xml:"useDefaultSetting,attr"`
+	SafeList          []SafeExtension `json:"safeList,omitempty" xml:"safeList,omitempty"`
+}
+
+type ExtensionsSiteSettingsResponse struct {
+	Settings ExtensionsSiteSettings `json:"extensionsSiteSettings,omitempty" xml:"extensionsSiteSettings,omitempty"`
+}
+
+type ExtensionsSiteSettingsRequest struct {
+	Request ExtensionsSiteSettings `json:"extensionsSiteSettings,omitempty" xml:"extensionsSiteSettings,omitempty"`
+}
+
+func (req ExtensionsSiteSettingsRequest) XML() ([]byte, error) {
+	tmp := struct {
+		ExtensionsSiteSettingsRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{ExtensionsSiteSettingsRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
 type ConnectionCredentials struct {
 	Name     string `json:"name,omitempty" xml:"name,attr,omitempty"`
 	Password string `json:"password,omitempty" xml:"password,attr,omitempty"`
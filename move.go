@@ -0,0 +1,76 @@
+package tableau4go
+
+import "errors"
+
+var ErrMoveIntoItself = errors.New("Project Cannot Be Moved Into Itself")
+
+func (api *API) MoveWorkbookToProject(siteId SiteID, workbookId WorkbookID, projectId ProjectID) (*Workbook, error) {
+	return api.UpdateWorkbook(siteId, Workbook{ID: workbookId, Project: &Project{ID: projectId}})
+}
+
+func (api *API) MoveDatasourceToProject(siteId SiteID, datasourceId DatasourceID, projectId ProjectID) (*Datasource, error) {
+	return api.UpdateDatasource(siteId, Datasource{ID: datasourceId, Project: &Project{ID: projectId}})
+}
+
+func (api *API) MoveFlowToProject(siteId SiteID, flowId FlowID, projectId ProjectID) (*Flow, error) {
+	return api.UpdateFlow(siteId, Flow{ID: flowId, Project: &Project{ID: projectId}})
+}
+
+// MoveProjectContents moves everything directly in the source project to the
+// destination: its workbooks, datasources and flows, and its child projects
+// with everything below them. The source project itself is left empty rather
+// than deleted. A child project the destination sits in can't move under it,
+// so it is reported as failed with ErrMoveIntoItself and stays put. Flows are
+// skipped on servers older than API 3.3.
+//
+// Items that fail don't stop the rest; the failures are returned as a
+// *MultiError keyed by "<type>:<id>".
+func (api *API) MoveProjectContents(siteId SiteID, srcProjectId, dstProjectId ProjectID) error {
+	if srcProjectId == dstProjectId {
+		return ErrMoveIntoItself
+	}
+	content, err := api.ListProjectContent(siteId, srcProjectId, false)
+	if err != nil {
+		return err
+	}
+	projects, err := api.queryAllProjects(siteId, ListOptions{})
+	if err != nil {
+		return err
+	}
+	result := &MultiError{}
+	for _, workbook := range content.Workbooks {
+		_, err := api.MoveWorkbookToProject(siteId, workbook.ID, dstProjectId)
+		result.record(CONTENT_TYPE_WORKBOOK+":"+string(workbook.ID), err)
+	}
+	for _, datasource := range content.Datasources {
+		_, err := api.MoveDatasourceToProject(siteId, datasource.ID, dstProjectId)
+		result.record(CONTENT_TYPE_DATASOURCE+":"+string(datasource.ID), err)
+	}
+	for _, flow := range content.Flows {
+		_, err := api.MoveFlowToProject(siteId, flow.ID, dstProjectId)
+		result.record(CONTENT_TYPE_FLOW+":"+string(flow.ID), err)
+	}
+	for _, child := range projects {
+		if child.ParentProjectID != srcProjectId {
+			continue
+		}
+		key := CONTENT_TYPE_PROJECT + ":" + string(child.ID)
+		if projectContains(projects, child.ID, dstProjectId) {
+			result.fail(key, ErrMoveIntoItself)
+			continue
+		}
+		_, err := api.UpdateProject(siteId, Project{ID: child.ID, ParentProjectID: dstProjectId})
+		result.record(key, err)
+	}
+	return result.err()
+}
+
+// projectContains reports whether projectId is rootId or below it.
+func projectContains(projects []Project, rootId, projectId ProjectID) bool {
+	for _, project := range projectTree(projects, rootId, true) {
+		if project.ID == projectId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package tableau4go
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+var ErrBoundaryCollision = errors.New("Multipart Boundary Found In Payload")
+
+// how many boundaries newBoundary tries before giving up; with 128 random
+// bits a second attempt is already vanishingly unlikely
+const boundary_attempts = 3
+
+// newBoundary returns a random multipart boundary that occurs in none of
+// parts, so a part can never be mistaken for the end of itself.
+func (api *API) newBoundary(parts ...[]byte) (string, error) {
+	for attempt := 0; attempt < boundary_attempts; attempt++ {
+		random := make([]byte, 16)
+		if _, err := io.ReadFull(api.random(), random); err != nil {
+			return "", err
+		}
+		boundary := "tableau4go-" + hex.EncodeToString(random)
+		if !boundaryCollides(boundary, parts) {
+			return boundary, nil
+		}
+	}
+	return "", ErrBoundaryCollision
+}
+
+func boundaryCollides(boundary string, parts [][]byte) bool {
+	delimiter := []byte("--" + boundary)
+	for _, part := range parts {
+		if bytes.Contains(part, delimiter) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package tableau4go
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewBoundary(t *testing.T) {
+	first := bytes.Repeat([]byte{0x11}, 16)
+	second := bytes.Repeat([]byte{0x22}, 16)
+	third := bytes.Repeat([]byte{0x33}, 16)
+	firstBoundary := "tableau4go-11111111111111111111111111111111"
+	secondBoundary := "tableau4go-22222222222222222222222222222222"
+	tests := []struct {
+		name     string
+		random   []byte
+		parts    [][]byte
+		expected string
+		err      error
+	}{
+		{
+			name:     "taken from Random",
+			random:   first,
+			parts:    [][]byte{[]byte("<tsRequest/>")},
+			expected: firstBoundary,
+		},
+		{
+			name:     "drawn again when it occurs in a part",
+			random:   append(append([]byte{}, first...), second...),
+			parts:    [][]byte{[]byte("<tsRequest/>"), []byte("--" + firstBoundary)},
+			expected: secondBoundary,
+		},
+		{
+			name:   "gives up when every draw collides",
+			random: append(append(append([]byte{}, first...), first...), first...),
+			parts:  [][]byte{[]byte("--" + firstBoundary)},
+			err:    ErrBoundaryCollision,
+		},
+		{
+			name:     "boundary without its dashes is not a collision",
+			random:   third,
+			parts:    [][]byte{[]byte("tableau4go-33333333333333333333333333333333")},
+			expected: "tableau4go-33333333333333333333333333333333",
+		},
+	}
+	for _, test := range tests {
+		api := NewAPI(DEFAULT_SERVER, API_VERSION, BOUNDARY_STRING, "", true)
+		api.Random = bytes.NewReader(test.random)
+		boundary, err := api.newBoundary(test.parts...)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, expected %v", test.name, err, test.err)
+		}
+		if boundary != test.expected {
+			t.Errorf("%s: got %s, expected %s", test.name, boundary, test.expected)
+		}
+	}
+}
+
+func TestNewBoundaryRandomRunsOut(t *testing.T) {
+	api := NewAPI(DEFAULT_SERVER, API_VERSION, BOUNDARY_STRING, "", true)
+	api.Random = bytes.NewReader([]byte{1, 2, 3})
+	if _, err := api.newBoundary(); err == nil {
+		t.Error("expected an error from a short Random")
+	}
+}
+
+// a publish sent with a fixed Random has the same boundary, and so the same
+// body, every time
+func TestPublishBoundaryFromRandom(t *testing.T) {
+	var contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get(content_type_header)
+		body, _ = io.ReadAll(r.Body)
+		io.WriteString(w, `<tsResponse><workbook id="wb"/></tsResponse>`)
+	}))
+	defer server.Close()
+	api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+	api.Random = bytes.NewReader(bytes.Repeat([]byte{0xab}, 16))
+	if _, err := api.PublishWorkbook("s", Workbook{Name: "Sales", Project: &Project{ID: "p"}}, []byte("<workbook/>"), "twb", false); err != nil {
+		t.Fatal(err)
+	}
+	boundary := "tableau4go-abababababababababababababababab"
+	if expected := "multipart/mixed; boundary=" + boundary; contentType != expected {
+		t.Errorf("got Content-Type %s, expected %s", contentType, expected)
+	}
+	if !strings.HasPrefix(string(body), "--"+boundary+"\r\n") || !strings.HasSuffix(string(body), "\r\n--"+boundary+"--\r\n") {
+		t.Errorf("body is not delimited by %s: %q", boundary, body)
+	}
+	if !bytes.Contains(body, []byte("\r\n\r\n<workbook/>\r\n")) {
+		t.Errorf("body is missing the workbook: %q", body)
+	}
+}
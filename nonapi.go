@@ -0,0 +1,53 @@
+package tableau4go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+var ErrNonAPIResponse = errors.New("Non API Response")
+
+// how much of an unexpected body NonAPIResponseError keeps
+const snippet_length = 256
+
+// NonAPIResponseError is returned when the server, or a proxy or load
+// balancer in front of it, answers with something other than a REST API
+// response, typically an HTML maintenance or gateway error page. It matches
+// ErrNonAPIResponse.
+type NonAPIResponseError struct {
+	StatusCode  int
+	ContentType string
+	// Snippet is the start of the body with whitespace collapsed
+	Snippet string
+}
+
+func (e *NonAPIResponseError) Error() string {
+	return fmt.Sprintf("Non API Response (status %d, %s): %s", e.StatusCode, e.ContentType, e.Snippet)
+}
+
+func (e *NonAPIResponseError) Unwrap() error {
+	return ErrNonAPIResponse
+}
+
+func newNonAPIResponseError(resp *Response) *NonAPIResponseError {
+	snippet := strings.Join(strings.Fields(string(resp.Body)), " ")
+	if len(snippet) > snippet_length {
+		snippet = snippet[:snippet_length] + "..."
+	}
+	return &NonAPIResponseError{StatusCode: resp.StatusCode, ContentType: resp.Header.Get(content_type_header), Snippet: snippet}
+}
+
+// isHTML reports whether resp is a web page rather than an API response.
+func isHTML(resp *Response) bool {
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get(content_type_header)); err == nil && mediaType == "text/html" {
+		return true
+	}
+	start := bytes.ToLower(bytes.TrimSpace(resp.Body))
+	if len(start) > 32 {
+		start = start[:32]
+	}
+	return bytes.HasPrefix(start, []byte("<!doctype html")) || bytes.HasPrefix(start, []byte("<html"))
+}
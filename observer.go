@@ -0,0 +1,30 @@
+package tableau4go
+
+import "context"
+
+// RequestObserver is told about every HTTP request the client sends, with the
+// context of the call that sent it, so logs, metrics and traces can be
+// attributed using values the caller put in the context, such as a tenant or
+// request id. Pass that context to WithContext or Do.
+//
+// Begin may return a derived context, e.g. holding a trace span; the request
+// is sent with it and End receives it. End gets the request's CallInfo and
+// the transport error, if any; Tableau errors show in its StatusCode. Both
+// are called synchronously, on the goroutine making the call.
+type RequestObserver interface {
+	Begin(ctx context.Context, method, requestUrl string) context.Context
+	End(ctx context.Context, info CallInfo, err error)
+}
+
+// ContextAuditSink is an AuditSink that also wants the call's context. The
+// client calls AuditContext instead of Audit on sinks that implement it.
+type ContextAuditSink interface {
+	AuditSink
+	AuditContext(ctx context.Context, record AuditRecord)
+}
+
+func (api *API) observe(ctx context.Context, info CallInfo, err error) {
+	if api.Observer != nil {
+		api.Observer.End(ctx, info, err)
+	}
+}
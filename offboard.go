@@ -0,0 +1,140 @@
+package tableau4go
+
+import "errors"
+
+var ErrNoTransferTarget = errors.New("No User To Transfer Content To")
+
+// the group every user belongs to, which nobody can be removed from
+const all_users_group = "All Users"
+
+// OffboardOptions controls OffboardUser. TransferTo receives the user's
+// content and must be set. With RemoveFromSite the user is removed from the
+// site at the end, otherwise their site role is set to Unlicensed. With
+// DryRun nothing is changed and the report lists what would be.
+type OffboardOptions struct {
+	TransferTo     UserID
+	RemoveFromSite bool
+	DryRun         bool
+}
+
+// OffboardReport lists what OffboardUser did, or with DryRun would do.
+type OffboardReport struct {
+	User            UserID           `json:"user"`
+	DryRun          bool             `json:"dryRun"`
+	Ownership       OwnershipReport  `json:"ownership"`
+	Groups          []GroupID        `json:"groups"`
+	Subscriptions   []SubscriptionID `json:"subscriptions"`
+	DataAlerts      []DataAlertID    `json:"dataAlerts"`
+	Unlicensed      bool             `json:"unlicensed"`
+	RemovedFromSite bool             `json:"removedFromSite"`
+}
+
+// OffboardUser runs the steps for a user leaving the organization: their
+// content is reassigned to opts.TransferTo, they are removed from their
+// groups, their subscriptions and the data-driven alerts they own are
+// deleted, and finally they are unlicensed or removed from the site.
+//
+// Failures on individual items don't stop the other steps and are returned
+// as a *MultiError keyed by "<type>:<id>". The final step is skipped if
+// anything before it failed, so the user keeps their license until their
+// content is safe. Servers older than API 3.7 can't list a user's groups and
+// skip that step; on servers older than API 3.2 alerts are skipped.
+func (api *API) OffboardUser(siteId SiteID, userId UserID, opts OffboardOptions) (OffboardReport, error) {
+	report := OffboardReport{User: userId, DryRun: opts.DryRun}
+	if len(opts.TransferTo) == 0 {
+		return report, ErrNoTransferTarget
+	}
+	result := &MultiError{}
+	ownership, err := api.ReassignContentOwnership(siteId, userId, opts.TransferTo, opts.DryRun)
+	report.Ownership = ownership
+	if !result.merge(err) {
+		return report, err
+	}
+
+	if api.Supports("QueryGroupsForUser") {
+		groups, err := api.queryAllGroupsForUser(siteId, userId)
+		if err != nil {
+			return report, err
+		}
+		for _, group := range groups {
+			if group.Name == all_users_group {
+				continue
+			}
+			report.Groups = append(report.Groups, group.ID)
+			if !opts.DryRun {
+				result.record("group:"+string(group.ID), api.RemoveUserFromGroup(siteId, group.ID, userId))
+			}
+		}
+	}
+
+	subscriptions, err := api.userSubscriptions(siteId, userId)
+	if err != nil {
+		return report, err
+	}
+	for _, subscription := range subscriptions {
+		report.Subscriptions = append(report.Subscriptions, subscription.ID)
+		if !opts.DryRun {
+			result.record("subscription:"+string(subscription.ID), api.DeleteSubscription(siteId, subscription.ID))
+		}
+	}
+
+	if api.Supports("QueryDataAlerts") {
+		alerts, err := api.userDataAlerts(siteId, userId)
+		if err != nil {
+			return report, err
+		}
+		for _, alert := range alerts {
+			report.DataAlerts = append(report.DataAlerts, alert.ID)
+			if !opts.DryRun {
+				result.record("alert:"+string(alert.ID), api.DeleteDataAlert(siteId, alert.ID))
+			}
+		}
+	}
+
+	if opts.DryRun {
+		report.RemovedFromSite = opts.RemoveFromSite
+		report.Unlicensed = !opts.RemoveFromSite
+		return report, nil
+	}
+	if len(result.Failed) > 0 {
+		return report, result.err()
+	}
+	if opts.RemoveFromSite {
+		err = api.RemoveUserFromSite(siteId, userId, opts.TransferTo)
+		report.RemovedFromSite = err == nil
+		result.record("user:"+string(userId), err)
+	} else {
+		_, err = api.UpdateUser(siteId, User{ID: userId, SiteRole: SiteRoleUnlicensed})
+		report.Unlicensed = err == nil
+		result.record("user:"+string(userId), err)
+	}
+	return report, result.err()
+}
+
+func (api *API) userSubscriptions(siteId SiteID, userId UserID) ([]Subscription, error) {
+	subscriptions, err := api.queryAllSubscriptions(siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	owned := []Subscription{}
+	for _, subscription := range subscriptions {
+		if subscription.User != nil && subscription.User.ID == userId {
+			owned = append(owned, subscription)
+		}
+	}
+	return owned, nil
+}
+
+func (api *API) userDataAlerts(siteId SiteID, userId UserID) ([]DataAlert, error) {
+	alerts, err := api.queryAllDataAlerts(siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	owned := []DataAlert{}
+	for _, alert := range alerts {
+		if alert.Owner != nil && alert.Owner.ID == userId {
+			owned = append(owned, alert)
+		}
+	}
+	return owned, nil
+}
@@ -0,0 +1,121 @@
+package tableau4go
+
+const (
+	CONTENT_TYPE_WORKBOOK   = "workbook"
+	CONTENT_TYPE_DATASOURCE = "datasource"
+	CONTENT_TYPE_FLOW       = "flow"
+	CONTENT_TYPE_PROJECT    = "project"
+	CONTENT_TYPE_VIEW       = "view"
+)
+
+// OwnedContent identifies one item found by ReassignContentOwnership. Type is
+// one of the CONTENT_TYPE constants.
+type OwnedContent struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// key identifies the item in a MultiError
+func (c OwnedContent) key() string {
+	return c.Type + ":" + c.ID
+}
+
+// OwnershipReport lists the content ReassignContentOwnership found owned by
+// From. With DryRun set nothing was changed.
+type OwnershipReport struct {
+	From    UserID         `json:"from"`
+	To      UserID         `json:"to"`
+	DryRun  bool           `json:"dryRun"`
+	Content []OwnedContent `json:"content"`
+}
+
+// ReassignContentOwnership makes toUserId the owner of every workbook,
+// datasource, flow and project on the site owned by fromUserId, for example
+// before fromUserId is unlicensed, which would stop their extracts from
+// refreshing. With dryRun set it only reports what it would change.
+//
+// Flows are skipped on servers older than API 3.3. Content that can't be
+// reassigned does not stop the rest; the failures are returned as a
+// *MultiError keyed by "<type>:<id>".
+func (api *API) ReassignContentOwnership(siteId SiteID, fromUserId UserID, toUserId UserID, dryRun bool) (OwnershipReport, error) {
+	report := OwnershipReport{From: fromUserId, To: toUserId, DryRun: dryRun}
+	owned, err := api.ownedContent(siteId, fromUserId)
+	if err != nil {
+		return report, err
+	}
+	report.Content = owned
+	if dryRun {
+		return report, nil
+	}
+	result := &MultiError{}
+	owner := &User{ID: toUserId}
+	for _, content := range owned {
+		switch content.Type {
+		case CONTENT_TYPE_WORKBOOK:
+			_, err = api.UpdateWorkbook(siteId, Workbook{ID: WorkbookID(content.ID), Owner: owner})
+		case CONTENT_TYPE_DATASOURCE:
+			_, err = api.UpdateDatasource(siteId, Datasource{ID: DatasourceID(content.ID), Owner: owner})
+		case CONTENT_TYPE_FLOW:
+			_, err = api.UpdateFlow(siteId, Flow{ID: FlowID(content.ID), Owner: owner})
+		case CONTENT_TYPE_PROJECT:
+			_, err = api.UpdateProject(siteId, Project{ID: ProjectID(content.ID), Owner: owner})
+		}
+		result.record(content.key(), err)
+	}
+	return report, result.err()
+}
+
+// ownedContent finds the user's content with server side ownerName filters,
+// then checks the owner ID since names are only unique within a domain
+func (api *API) ownedContent(siteId SiteID, userId UserID) ([]OwnedContent, error) {
+	user, err := api.QueryUserOnSite(siteId, userId)
+	if err != nil {
+		return nil, err
+	}
+	opts := ListOptions{Filter: "ownerName:eq:" + FilterValue(user.Name)}
+	owned := []OwnedContent{}
+	isOwner := func(owner *User) bool {
+		return owner != nil && owner.ID == userId
+	}
+
+	workbooks, err := api.queryAllWorkbooks(siteId, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, wb := range workbooks {
+		if isOwner(wb.Owner) {
+			owned = append(owned, OwnedContent{Type: CONTENT_TYPE_WORKBOOK, ID: string(wb.ID), Name: wb.Name})
+		}
+	}
+	datasources, err := api.queryAllDatasources(siteId, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range datasources {
+		if isOwner(ds.Owner) {
+			owned = append(owned, OwnedContent{Type: CONTENT_TYPE_DATASOURCE, ID: string(ds.ID), Name: ds.Name})
+		}
+	}
+	if api.Supports("QueryFlows") {
+		flows, err := api.queryAllFlows(siteId, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, flow := range flows {
+			if isOwner(flow.Owner) {
+				owned = append(owned, OwnedContent{Type: CONTENT_TYPE_FLOW, ID: string(flow.ID), Name: flow.Name})
+			}
+		}
+	}
+	projects, err := api.queryAllProjects(siteId, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		if isOwner(project.Owner) {
+			owned = append(owned, OwnedContent{Type: CONTENT_TYPE_PROJECT, ID: string(project.ID), Name: project.Name})
+		}
+	}
+	return owned, nil
+}
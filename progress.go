@@ -0,0 +1,83 @@
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrJobFailed = errors.New("Job Failed")
+var ErrJobCancelled = errors.New("Job Cancelled")
+
+// ProgressStage is the part of publishing a PublishProgress reports on.
+type ProgressStage string
+
+const (
+	// a chunk of a file upload has been committed
+	ProgressUpload ProgressStage = "Upload"
+	// the publish request has been sent, or has been answered
+	ProgressPublish ProgressStage = "Publish"
+	// a background job, such as one started by an asJob publish, was polled
+	ProgressJob ProgressStage = "Job"
+)
+
+// PublishProgress is reported while content is published. Uploads fill in
+// BytesSent, TotalBytes, Chunk and Chunks; jobs fill in Job, whose Progress is
+// the percentage the server reports.
+type PublishProgress struct {
+	Stage      ProgressStage
+	BytesSent  int64
+	TotalBytes int64
+	Chunk      int
+	Chunks     int
+	Job        *Job
+}
+
+// ProgressReporter receives progress while publishing. Progress is called
+// from the goroutine doing the work, so it should return quickly.
+type ProgressReporter interface {
+	Progress(PublishProgress)
+}
+
+// ProgressFunc lets an ordinary function be used as a ProgressReporter.
+type ProgressFunc func(PublishProgress)
+
+func (f ProgressFunc) Progress(progress PublishProgress) {
+	f(progress)
+}
+
+func reportProgress(reporter ProgressReporter, progress PublishProgress) {
+	if reporter != nil {
+		reporter.Progress(progress)
+	}
+}
+
+// WaitForJob polls the job every interval until it completes and returns it,
+// reporting each poll to progress, which may be nil. A job that completes
+// without succeeding is returned with ErrJobFailed or ErrJobCancelled.
+func (api *API) WaitForJob(ctx context.Context, siteId SiteID, jobId JobID, interval time.Duration, progress ProgressReporter) (Job, error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	client := api.WithContext(ctx)
+	for {
+		job, err := client.QueryJob(siteId, jobId)
+		if err != nil {
+			return job, err
+		}
+		reportProgress(progress, PublishProgress{Stage: ProgressJob, Job: &job})
+		if len(job.CompletedAt) > 0 {
+			switch job.FinishCode {
+			case 0:
+				return job, nil
+			case 2:
+				return job, ErrJobCancelled
+			default:
+				return job, ErrJobFailed
+			}
+		}
+		if err := api.sleep(ctx, interval); err != nil {
+			return job, err
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tableau4go
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrCertifiedContent = errors.New("Project Contains Certified Datasources")
+
+// DeleteProjectOptions control DeleteProjectRecursive. With ArchiveDir set
+// every workbook, datasource and flow is downloaded to
+// ArchiveDir/<type>/<id>/<file name> before anything is deleted.
+// Certified datasources make the delete fail with ErrCertifiedContent unless
+// Force is set.
+type DeleteProjectOptions struct {
+	ArchiveDir      string
+	IncludeExtracts bool
+	Force           bool
+}
+
+// DeleteProjectRecursive deletes a project and everything below it: the
+// content of each project first, then the projects themselves, children
+// before their parents. It returns what it found to delete. If archiving
+// fails nothing is deleted; if some content can't be deleted the projects are
+// left in place and the failures are returned as a *MultiError keyed by
+// "<type>:<id>".
+func (api *API) DeleteProjectRecursive(siteId SiteID, projectId ProjectID, opts DeleteProjectOptions) (ProjectContent, error) {
+	content, err := api.ListProjectContent(siteId, projectId, true)
+	if err != nil {
+		return content, err
+	}
+	if !opts.Force {
+		for _, datasource := range content.Datasources {
+			if datasource.IsCertified {
+				return content, ErrCertifiedContent
+			}
+		}
+	}
+	if len(opts.ArchiveDir) > 0 {
+		if err := api.archiveProjectContent(siteId, content, opts); err != nil {
+			return content, err
+		}
+	}
+	result := &MultiError{}
+	for _, workbook := range content.Workbooks {
+		result.record(CONTENT_TYPE_WORKBOOK+":"+string(workbook.ID), api.DeleteWorkbook(siteId, workbook.ID))
+	}
+	for _, datasource := range content.Datasources {
+		result.record(CONTENT_TYPE_DATASOURCE+":"+string(datasource.ID), api.DeleteDatasource(siteId, datasource.ID))
+	}
+	for _, flow := range content.Flows {
+		result.record(CONTENT_TYPE_FLOW+":"+string(flow.ID), api.DeleteFlow(siteId, flow.ID))
+	}
+	if err := result.err(); err != nil {
+		return content, err
+	}
+	// Projects lists parents before children
+	for i := len(content.Projects) - 1; i >= 0; i-- {
+		project := content.Projects[i]
+		result.record(CONTENT_TYPE_PROJECT+":"+string(project.ID), api.DeleteProject(siteId, project.ID))
+	}
+	return content, result.err()
+}
+
+func (api *API) archiveProjectContent(siteId SiteID, content ProjectContent, opts DeleteProjectOptions) error {
+	for _, workbook := range content.Workbooks {
+		id := workbook.ID
+		_, err := archive(opts.ArchiveDir, CONTENT_TYPE_WORKBOOK, string(id), func(f *os.File) (string, error) {
+			return api.DownloadWorkbook(siteId, id, opts.IncludeExtracts, f)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, datasource := range content.Datasources {
+		id := datasource.ID
+		_, err := archive(opts.ArchiveDir, CONTENT_TYPE_DATASOURCE, string(id), func(f *os.File) (string, error) {
+			return api.DownloadDatasource(siteId, id, opts.IncludeExtracts, f)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, flow := range content.Flows {
+		id := flow.ID
+		_, err := archive(opts.ArchiveDir, CONTENT_TYPE_FLOW, string(id), func(f *os.File) (string, error) {
+			return api.DownloadFlow(siteId, id, f)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archive downloads into a temporary file and renames it to the file name the
+// server gave once that is known. It returns the path of the file.
+func archive(dir, contentType, id string, download func(*os.File) (string, error)) (string, error) {
+	target := filepath.Join(dir, contentType, id)
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(target, download_prefix+"*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	filename, err := download(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	filename = filepath.Base(filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		filename = contentType
+	}
+	path := filepath.Join(target, filename)
+	return path, os.Rename(f.Name(), path)
+}
+
+// temporary files archive has not finished writing start with this
+const download_prefix = ".download-"
+
+// archived returns the path of a file archive already wrote for the item, or
+// "" if there is none.
+func archived(dir, contentType, id string) string {
+	entries, err := os.ReadDir(filepath.Join(dir, contentType, id))
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && !strings.HasPrefix(entry.Name(), download_prefix) {
+			return filepath.Join(dir, contentType, id, entry.Name())
+		}
+	}
+	return ""
+}
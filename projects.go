@@ -0,0 +1,128 @@
+package tableau4go
+
+// ProjectContent is the content of a project and, when listed recursively, of
+// the projects nested in it. Projects lists the project itself first, then its
+// descendants with parents before their children.
+type ProjectContent struct {
+	Projects    []Project    `json:"projects"`
+	Workbooks   []Workbook   `json:"workbooks"`
+	Datasources []Datasource `json:"datasources"`
+	Flows       []Flow       `json:"flows"`
+}
+
+// ListProjectContent returns the workbooks, datasources and flows in a
+// project, and in every project below it when recursive is set. Content is
+// queried with a server side projectName filter; since nested projects can
+// share a name, results are also checked against the project ids. Flows are
+// skipped on servers older than API 3.3.
+func (api *API) ListProjectContent(siteId SiteID, projectId ProjectID, recursive bool) (ProjectContent, error) {
+	content := ProjectContent{}
+	all, err := api.queryAllProjects(siteId, ListOptions{})
+	if err != nil {
+		return content, err
+	}
+	content.Projects = projectTree(all, projectId, recursive)
+	if len(content.Projects) == 0 {
+		return content, ErrDoesNotExist
+	}
+	inTree := map[ProjectID]bool{}
+	names := []string{}
+	seen := map[string]bool{}
+	for _, project := range content.Projects {
+		inTree[project.ID] = true
+		if !seen[project.Name] {
+			seen[project.Name] = true
+			names = append(names, project.Name)
+		}
+	}
+	contains := func(project *Project) bool {
+		return project != nil && inTree[project.ID]
+	}
+	for _, name := range names {
+		opts := ListOptions{Filter: "projectName:eq:" + FilterValue(name)}
+		workbooks, err := api.queryAllWorkbooks(siteId, opts)
+		if err != nil {
+			return content, err
+		}
+		for _, workbook := range workbooks {
+			if contains(workbook.Project) {
+				content.Workbooks = append(content.Workbooks, workbook)
+			}
+		}
+		datasources, err := api.queryAllDatasources(siteId, opts)
+		if err != nil {
+			return content, err
+		}
+		for _, datasource := range datasources {
+			if contains(datasource.Project) {
+				content.Datasources = append(content.Datasources, datasource)
+			}
+		}
+		if !api.Supports("QueryFlows") {
+			continue
+		}
+		flows, err := api.queryAllFlows(siteId, opts)
+		if err != nil {
+			return content, err
+		}
+		for _, flow := range flows {
+			if contains(flow.Project) {
+				content.Flows = append(content.Flows, flow)
+			}
+		}
+	}
+	return content, nil
+}
+
+// projectTree returns the project with id rootId followed, if recursive, by
+// its descendants in breadth first order.
+func projectTree(projects []Project, rootId ProjectID, recursive bool) []Project {
+	children := map[ProjectID][]Project{}
+	tree := []Project{}
+	for _, project := range projects {
+		if project.ID == rootId {
+			tree = append(tree, project)
+		}
+		if len(project.ParentProjectID) > 0 {
+			children[project.ParentProjectID] = append(children[project.ParentProjectID], project)
+		}
+	}
+	if !recursive {
+		return tree
+	}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i].ID]...)
+	}
+	return tree
+}
+
+// projectPaths maps each project to its path from the top level, e.g.
+// "Finance/Reports", which unlike its id is the same on every site.
+func projectPaths(projects []Project) map[ProjectID]string {
+	byId := map[ProjectID]Project{}
+	for _, project := range projects {
+		byId[project.ID] = project
+	}
+	paths := map[ProjectID]string{}
+	var path func(id ProjectID, depth int) string
+	path = func(id ProjectID, depth int) string {
+		if p, ok := paths[id]; ok {
+			return p
+		}
+		project, ok := byId[id]
+		if !ok {
+			return ""
+		}
+		p := project.Name
+		// depth guards against a parent cycle in a bad response
+		if parent, ok := byId[project.ParentProjectID]; ok && depth < len(projects) {
+			p = path(parent.ID, depth+1) + "/" + project.Name
+		}
+		paths[id] = p
+		return p
+	}
+	for _, project := range projects {
+		path(project.ID, 0)
+	}
+	return paths
+}
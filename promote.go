@@ -0,0 +1,216 @@
+package tableau4go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/groundfoundation/tableau4go/tabdoc"
+)
+
+var ErrAmbiguousMatch = errors.New("More Than One Item Matches")
+
+// ConnectionRewrite repoints connections while promoting content.
+// FromServer limits it to connections to that server; empty matches every
+// database connection, but not extract connections or connections to
+// published datasources, which are only rewritten by naming their server.
+type ConnectionRewrite struct {
+	FromServer string
+	To         tabdoc.ConnectionTarget
+}
+
+// PromotionSpec selects the content Promote copies: workbooks and
+// datasources by name from SourceProject on the source site, published to
+// TargetProject on the destination site, which must already exist. Each
+// project is the one of that name under its parent ID, or at the top level
+// when that is empty, as with EnsureProject.
+//
+// Connections are rewritten in order, so later rewrites see the result of
+// earlier ones. Workbooks connected to published datasources reach them
+// through the server they were published to; add a rewrite from the source
+// server to the destination server when promoting across servers. With
+// Refresh set, every promoted item is refreshed afterwards to check the new
+// connections work; this only applies to extracts.
+type PromotionSpec struct {
+	SourceProject         string
+	SourceParentProjectID ProjectID
+	TargetProject         string
+	TargetParentProjectID ProjectID
+	Workbooks             []string
+	Datasources           []string
+	Connections           []ConnectionRewrite
+	Refresh               bool
+}
+
+// PromotionResult lists what Promote published and the refresh jobs it
+// started.
+type PromotionResult struct {
+	Datasources []Datasource
+	Workbooks   []Workbook
+	Jobs        []Job
+}
+
+// Promote copies content between environments, e.g. from a development site
+// to production: it downloads each selected item from src, rewrites its
+// connections as spec says and publishes it to dst, overwriting any item of
+// the same name. Datasources go first, so workbooks using them find them.
+//
+// Items that fail don't stop the rest; the failures are returned as a
+// *MultiError keyed by "<type>:<name>".
+func Promote(src SiteClient, dst SiteClient, spec PromotionSpec) (PromotionResult, error) {
+	result := PromotionResult{}
+	source, err := promotionProject(src, spec.SourceParentProjectID, spec.SourceProject)
+	if err != nil {
+		return result, err
+	}
+	target, err := promotionProject(dst, spec.TargetParentProjectID, spec.TargetProject)
+	if err != nil {
+		return result, err
+	}
+	failures := &MultiError{}
+	if len(spec.Datasources) > 0 {
+		datasources, err := src.QueryDatasources()
+		if err != nil {
+			return result, err
+		}
+		for _, name := range spec.Datasources {
+			published, err := promoteDatasource(src, dst, spec, source, target, datasources, name)
+			if err == nil {
+				result.Datasources = append(result.Datasources, *published)
+				if spec.Refresh {
+					var job Job
+					if job, err = dst.UpdateDatasourceNow(published.ID); err == nil {
+						result.Jobs = append(result.Jobs, job)
+					} else {
+						err = fmt.Errorf("Refresh Failed: %w", err)
+					}
+				}
+			}
+			failures.record(CONTENT_TYPE_DATASOURCE+":"+name, err)
+		}
+	}
+	if len(spec.Workbooks) > 0 {
+		workbooks, err := src.QueryWorkbooks()
+		if err != nil {
+			return result, err
+		}
+		for _, name := range spec.Workbooks {
+			published, err := promoteWorkbook(src, dst, spec, source, target, workbooks, name)
+			if err == nil {
+				result.Workbooks = append(result.Workbooks, *published)
+				if spec.Refresh {
+					var job Job
+					if job, err = dst.UpdateWorkbookNow(published.ID); err == nil {
+						result.Jobs = append(result.Jobs, job)
+					} else {
+						err = fmt.Errorf("Refresh Failed: %w", err)
+					}
+				}
+			}
+			failures.record(CONTENT_TYPE_WORKBOOK+":"+name, err)
+		}
+	}
+	return result, failures.err()
+}
+
+// promotionProject finds the project named name under parentId.
+func promotionProject(site SiteClient, parentId ProjectID, name string) (Project, error) {
+	projects, err := site.QueryProjectsWithOptions(ListOptions{Filter: "name:eq:" + FilterValue(name)})
+	if err != nil {
+		return Project{}, err
+	}
+	project, found := findProject(projects, parentId, name)
+	if !found {
+		return Project{}, fmt.Errorf("Project Named '%s' Not Found", name)
+	}
+	return project, nil
+}
+
+func promoteDatasource(src SiteClient, dst SiteClient, spec PromotionSpec, project Project, target Project, datasources []Datasource, name string) (*Datasource, error) {
+	var source *Datasource
+	for i := range datasources {
+		if datasources[i].Name == name && datasources[i].Project != nil && datasources[i].Project.ID == project.ID {
+			if source != nil {
+				return nil, ErrAmbiguousMatch
+			}
+			source = &datasources[i]
+		}
+	}
+	if source == nil {
+		return nil, ErrDoesNotExist
+	}
+	var buf bytes.Buffer
+	filename, err := src.DownloadDatasource(source.ID, true, &buf)
+	if err != nil {
+		return nil, err
+	}
+	content, contentType, err := spec.rewrite(buf.Bytes(), filename)
+	if err != nil {
+		return nil, err
+	}
+	metadata := Datasource{Name: source.Name, Description: source.Description, Project: &Project{ID: target.ID}}
+	return dst.PublishDatasource(metadata, content, contentType, true)
+}
+
+func promoteWorkbook(src SiteClient, dst SiteClient, spec PromotionSpec, project Project, target Project, workbooks []Workbook, name string) (*Workbook, error) {
+	var source *Workbook
+	for i := range workbooks {
+		if workbooks[i].Name == name && workbooks[i].Project != nil && workbooks[i].Project.ID == project.ID {
+			if source != nil {
+				return nil, ErrAmbiguousMatch
+			}
+			source = &workbooks[i]
+		}
+	}
+	if source == nil {
+		return nil, ErrDoesNotExist
+	}
+	var buf bytes.Buffer
+	filename, err := src.DownloadWorkbook(source.ID, true, &buf)
+	if err != nil {
+		return nil, err
+	}
+	content, contentType, err := spec.rewrite(buf.Bytes(), filename)
+	if err != nil {
+		return nil, err
+	}
+	metadata := Workbook{Name: source.Name, ShowTabs: source.ShowTabs, Project: &Project{ID: target.ID}}
+	return dst.PublishWorkbook(metadata, content, contentType, true)
+}
+
+// rewrite applies the connection rewrites to downloaded content, packaged or
+// not, and returns it with its file type
+func (spec PromotionSpec) rewrite(content []byte, filename string) ([]byte, string, error) {
+	contentType := strings.TrimPrefix(strings.ToLower(path.Ext(filename)), ".")
+	rewriteDocument := func(document []byte) ([]byte, error) {
+		doc, err := tabdoc.Parse(document)
+		if err != nil {
+			return nil, err
+		}
+		for _, rewrite := range spec.Connections {
+			from := rewrite.FromServer
+			doc.RepointConnections(func(c *tabdoc.Connection) bool {
+				if len(from) == 0 {
+					return !c.IsExtract() && !c.IsPublishedDatasource()
+				}
+				return strings.EqualFold(c.Server(), from)
+			}, rewrite.To)
+		}
+		return doc.Bytes(), nil
+	}
+	if len(spec.Connections) == 0 {
+		return content, contentType, nil
+	}
+	switch contentType {
+	case "tds", "twb":
+		rewritten, err := rewriteDocument(content)
+		return rewritten, contentType, err
+	case "tdsx", "twbx":
+		var out bytes.Buffer
+		err := tabdoc.RewritePackage(content, &out, rewriteDocument)
+		return out.Bytes(), contentType, err
+	}
+	return nil, contentType, fmt.Errorf("Unexpected Content Type '%s'", contentType)
+}
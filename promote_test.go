@@ -0,0 +1,84 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// two projects named Sales, one at the top level and one under p0, each with
+// a datasource named Orders
+const promote_projects = `<tsResponse><projects>
+<project id="p1" name="Sales"/>
+<project id="p2" name="Sales" parentProjectId="p0"/>
+<project id="p3" name="Prod"/>
+</projects></tsResponse>`
+
+func TestPromoteResolvesSourceProject(t *testing.T) {
+	tests := []struct {
+		name        string
+		parent      ProjectID
+		datasources string
+		downloaded  string
+		err         error
+	}{
+		{
+			name:        "top level project",
+			datasources: `<datasource id="d1" name="Orders"><project id="p1" name="Sales"/></datasource><datasource id="d2" name="Orders"><project id="p2" name="Sales"/></datasource>`,
+			downloaded:  "d1",
+		},
+		{
+			name:        "nested project of the same name",
+			parent:      "p0",
+			datasources: `<datasource id="d1" name="Orders"><project id="p1" name="Sales"/></datasource><datasource id="d2" name="Orders"><project id="p2" name="Sales"/></datasource>`,
+			downloaded:  "d2",
+		},
+		{
+			name:        "more than one match",
+			datasources: `<datasource id="d1" name="Orders"><project id="p1" name="Sales"/></datasource><datasource id="d3" name="Orders"><project id="p1" name="Sales"/></datasource>`,
+			err:         ErrAmbiguousMatch,
+		},
+		{
+			name:        "no match",
+			parent:      "p0",
+			datasources: `<datasource id="d1" name="Orders"><project id="p1" name="Sales"/></datasource>`,
+			err:         ErrDoesNotExist,
+		},
+	}
+	for _, test := range tests {
+		downloaded := ""
+		site := "/api/" + API_VERSION + "/sites/s/"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch path := strings.TrimPrefix(r.URL.Path, site); {
+			case path == "projects":
+				fmt.Fprint(w, promote_projects)
+			case path == "datasources" && r.Method == GET:
+				fmt.Fprintf(w, `<tsResponse><datasources>%s</datasources></tsResponse>`, test.datasources)
+			case path == "datasources":
+				fmt.Fprint(w, `<tsResponse><datasource id="published" name="Orders"/></tsResponse>`)
+			case strings.HasSuffix(path, "/content"):
+				downloaded = strings.TrimSuffix(strings.TrimPrefix(path, "datasources/"), "/content")
+				w.Header().Set("Content-Disposition", `attachment; filename="Orders.tds"`)
+				fmt.Fprint(w, `<datasource/>`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+		spec := PromotionSpec{SourceProject: "Sales", SourceParentProjectID: test.parent, TargetProject: "Prod", Datasources: []string{"Orders"}}
+		_, err := Promote(api.Site("s"), api.Site("s"), spec)
+		server.Close()
+		if test.err == nil && err != nil {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, expected %v", test.name, err, test.err)
+		}
+		if downloaded != test.downloaded {
+			t.Errorf("%s: downloaded %q, expected %q", test.name, downloaded, test.downloaded)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package tableau4go
+
+// AddUsersToSite adds each of users to the site, carrying on past failures.
+// Like AddUserToSite it doesn't email the new users unless opts.SendInvite is
+// set. It returns the users added and, if any failed, a *MultiError keyed by
+// user name.
+func (api *API) AddUsersToSite(siteId SiteID, users []User, opts AddUserOptions) ([]User, error) {
+	added := []User{}
+	result := &MultiError{}
+	for _, user := range users {
+		created, err := api.AddUserToSite(siteId, user, opts)
+		result.record(user.Name, err)
+		if err == nil {
+			added = append(added, created)
+		}
+	}
+	return added, result.err()
+}
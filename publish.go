@@ -0,0 +1,147 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNothingToPublish = errors.New("Nothing To Publish")
+
+// PublishSetup describes content to publish together with the project it goes
+// in and the permissions and tags it should end up with. Exactly one of
+// Workbook and Datasource must be set; its Project is filled in by
+// PublishWithSetup. ContentType is the file extension of Content, e.g. "twbx"
+// or "tds". The project is the one named ProjectName under ParentProjectID,
+// or at the top level when that is empty, as with EnsureProject.
+type PublishSetup struct {
+	ProjectName        string
+	ParentProjectID    ProjectID
+	ProjectDescription string
+	Workbook           *Workbook
+	Datasource         *Datasource
+	Content            []byte
+	ContentType        string
+	Overwrite          bool
+	Permissions        []GranteeCapabilities
+	Tags               []string
+}
+
+// PublishResult reports what PublishWithSetup did. Only one of Workbook and
+// Datasource is set, matching the PublishSetup.
+type PublishResult struct {
+	Project        Project
+	ProjectCreated bool
+	Workbook       *Workbook
+	Datasource     *Datasource
+}
+
+// PublishWithSetup finds or creates the target project, publishes the content,
+// then applies permissions and tags. If a step fails, whatever this call
+// created is deleted again: a project it created (with everything in it), or
+// else content it published that did not exist before. Content that was
+// overwritten cannot be restored, so with Overwrite set a failure after
+// publishing leaves the new revision in place.
+func (api *API) PublishWithSetup(siteId SiteID, setup PublishSetup) (PublishResult, error) {
+	result := PublishResult{}
+	if (setup.Workbook == nil) == (setup.Datasource == nil) {
+		return result, ErrNothingToPublish
+	}
+	projects, err := api.QueryProjects(siteId)
+	if err != nil {
+		return result, err
+	}
+	project, found := findProject(projects, setup.ParentProjectID, setup.ProjectName)
+	if !found {
+		created, createErr := api.CreateProject(siteId, Project{Name: setup.ProjectName, Description: setup.ProjectDescription, ParentProjectID: setup.ParentProjectID})
+		if createErr != nil {
+			return result, createErr
+		}
+		project = *created
+		result.ProjectCreated = true
+	}
+	result.Project = project
+
+	rollback := func() error { return nil }
+	if result.ProjectCreated {
+		rollback = func() error { return api.DeleteProject(siteId, project.ID) }
+	}
+	if setup.Workbook != nil {
+		err = api.publishWorkbookWithSetup(siteId, project, setup, &result, &rollback)
+	} else {
+		err = api.publishDatasourceWithSetup(siteId, project, setup, &result, &rollback)
+	}
+	if err != nil {
+		if rollbackErr := rollback(); rollbackErr != nil {
+			return result, fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+	}
+	return result, err
+}
+
+func (api *API) publishWorkbookWithSetup(siteId SiteID, project Project, setup PublishSetup, result *PublishResult, rollback *func() error) error {
+	metadata := *setup.Workbook
+	metadata.Project = &Project{ID: project.ID}
+	existed := false
+	if !result.ProjectCreated {
+		workbooks, err := api.QueryWorkbooks(siteId)
+		if err != nil {
+			return err
+		}
+		for _, wb := range workbooks {
+			if wb.Name == metadata.Name && wb.Project != nil && wb.Project.ID == project.ID {
+				existed = true
+			}
+		}
+	}
+	workbook, err := api.PublishWorkbook(siteId, metadata, setup.Content, setup.ContentType, setup.Overwrite)
+	if err != nil {
+		return err
+	}
+	result.Workbook = workbook
+	if !result.ProjectCreated && !existed {
+		*rollback = func() error { return api.DeleteWorkbook(siteId, workbook.ID) }
+	}
+	if len(setup.Permissions) > 0 {
+		if _, err := api.AddWorkbookPermissions(siteId, workbook.ID, setup.Permissions); err != nil {
+			return err
+		}
+	}
+	if len(setup.Tags) > 0 {
+		if _, err := api.AddTagsToWorkbook(siteId, workbook.ID, setup.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (api *API) publishDatasourceWithSetup(siteId SiteID, project Project, setup PublishSetup, result *PublishResult, rollback *func() error) error {
+	metadata := *setup.Datasource
+	metadata.Project = &Project{ID: project.ID}
+	existed := false
+	if !result.ProjectCreated {
+		_, err := api.GetDatasourceByName(siteId, project.ID, metadata.Name)
+		if err != nil && err != ErrDoesNotExist {
+			return err
+		}
+		existed = err == nil
+	}
+	datasource, err := api.PublishDatasource(siteId, metadata, setup.Content, setup.ContentType, setup.Overwrite)
+	if err != nil {
+		return err
+	}
+	result.Datasource = datasource
+	if !result.ProjectCreated && !existed {
+		*rollback = func() error { return api.DeleteDatasource(siteId, datasource.ID) }
+	}
+	if len(setup.Permissions) > 0 {
+		if _, err := api.AddDatasourcePermissions(siteId, datasource.ID, setup.Permissions); err != nil {
+			return err
+		}
+	}
+	if len(setup.Tags) > 0 {
+		if _, err := api.AddTagsToDatasource(siteId, datasource.ID, setup.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package tableau4go
+
+import (
+	"context"
+	"net/url"
+)
+
+type queryParamsKey struct{}
+
+// WithQueryParam returns a context that adds key=value to the query string of
+// every request made with it, replacing any value the library set for key. It
+// lets callers use query options the server supports before this package
+// models them. Pass it to WithContext or Do, and call it again to add more:
+//
+//	ctx = tableau4go.WithQueryParam(ctx, "fields", "_all_")
+//	workbooks, err := api.WithContext(ctx).QueryWorkbooks(siteId)
+func WithQueryParam(ctx context.Context, key, value string) context.Context {
+	params := url.Values{}
+	for k, v := range queryParams(ctx) {
+		params[k] = v
+	}
+	params.Set(key, value)
+	return context.WithValue(ctx, queryParamsKey{}, params)
+}
+
+func queryParams(ctx context.Context) url.Values {
+	params, _ := ctx.Value(queryParamsKey{}).(url.Values)
+	return params
+}
+
+// withQueryParams adds the context's extra query parameters to requestUrl
+func withQueryParams(ctx context.Context, requestUrl string) (string, error) {
+	params := queryParams(ctx)
+	if len(params) == 0 {
+		return requestUrl, nil
+	}
+	parsed, err := url.Parse(requestUrl)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
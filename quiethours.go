@@ -0,0 +1,367 @@
+package tableau4go
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var ErrNoOperationHandler = errors.New("No Handler For Operation")
+
+// Window is a daily stretch of wall clock time, e.g. Window{"01:00", "05:00"}.
+// Times are "15:04" or "15:04:05"; an End at or before Start runs past
+// midnight into the next day.
+type Window struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func (w Window) minutes() (int, int, error) {
+	start, err := windowMinutes(w.Start)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := windowMinutes(w.End)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end <= start {
+		end += 24 * 60
+	}
+	return start, end, nil
+}
+
+func windowMinutes(clock string) (int, error) {
+	if parsed, err := time.Parse("15:04", clock); err == nil {
+		return parsed.Hour()*60 + parsed.Minute(), nil
+	}
+	return clockMinutes(clock)
+}
+
+// QueuedOperation is one unit of work for a BatchScheduler. Kind picks the
+// handler that runs it and Args are its parameters; both are plain strings so
+// the queue can be saved and picked up again by another process.
+type QueuedOperation struct {
+	ID         string            `json:"id"`
+	Kind       string            `json:"kind"`
+	Args       map[string]string `json:"args,omitempty"`
+	EnqueuedAt time.Time         `json:"enqueuedAt"`
+}
+
+// OperationHandler runs queued operations of one kind.
+type OperationHandler func(ctx context.Context, op QueuedOperation) error
+
+// QueueStore persists a BatchScheduler's queue between runs.
+type QueueStore interface {
+	Load() ([]QueuedOperation, error)
+	Save(queue []QueuedOperation) error
+}
+
+// FileQueueStore keeps the queue in a JSON file, replaced atomically on every
+// change.
+type FileQueueStore struct {
+	Path string
+}
+
+func (s FileQueueStore) Load() ([]QueuedOperation, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	queue := []QueuedOperation{}
+	err = json.Unmarshal(data, &queue)
+	return queue, err
+}
+
+func (s FileQueueStore) Save(queue []QueuedOperation) error {
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// BatchScheduler runs queued operations one at a time, starting them only
+// inside its Windows, so heavy automation stays out of business hours. An
+// operation that is running when a window closes is left to finish; the rest
+// wait for the next window. With no Windows operations run at any time.
+//
+// Window times are read in Location, which should be the server's time zone;
+// UTC when nil. A window lasts as long as its times say in elapsed time, so
+// across a daylight saving change it ends an hour later or earlier on the
+// clock. The queue is saved to Store after every change, so operations
+// not yet run survive a restart. Operations that fail are reported to
+// OnError and dropped; one interrupted by Run's context being done stays
+// queued and runs again. Clock and Random, when set, replace the system clock
+// and crypto/rand as the source of windows and operation IDs.
+type BatchScheduler struct {
+	Windows  []Window
+	Location *time.Location
+	Store    QueueStore
+	Clock    Clock
+	Random   io.Reader
+	OnError  func(op QueuedOperation, err error)
+
+	mu       sync.Mutex
+	loaded   bool
+	queue    []QueuedOperation
+	handlers map[string]OperationHandler
+	wake     chan struct{}
+}
+
+func NewBatchScheduler(store QueueStore, loc *time.Location, windows ...Window) *BatchScheduler {
+	return &BatchScheduler{Windows: windows, Location: loc, Store: store}
+}
+
+// Handle registers the handler for operations of kind.
+func (s *BatchScheduler) Handle(kind string, handler OperationHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handlers == nil {
+		s.handlers = map[string]OperationHandler{}
+	}
+	s.handlers[kind] = handler
+}
+
+// Enqueue adds op to the end of the queue and saves it, giving it an ID if it
+// has none. It can be called while Run is running.
+func (s *BatchScheduler) Enqueue(op QueuedOperation) (QueuedOperation, error) {
+	if len(op.ID) == 0 {
+		id := make([]byte, 8)
+		if _, err := io.ReadFull(s.random(), id); err != nil {
+			return op, err
+		}
+		op.ID = hex.EncodeToString(id)
+	}
+	if op.EnqueuedAt.IsZero() {
+		op.EnqueuedAt = s.now().UTC()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return op, err
+	}
+	s.queue = append(s.queue, op)
+	if err := s.saveLocked(); err != nil {
+		s.queue = s.queue[:len(s.queue)-1]
+		return op, err
+	}
+	s.signalLocked()
+	return op, nil
+}
+
+// Pending returns the operations still queued, in the order they will run.
+func (s *BatchScheduler) Pending() ([]QueuedOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.loadLocked(); err != nil {
+		return nil, err
+	}
+	return append([]QueuedOperation{}, s.queue...), nil
+}
+
+// Run works through the queue until ctx is done, waiting for a window to open
+// before each operation and for more to be enqueued when the queue is empty.
+func (s *BatchScheduler) Run(ctx context.Context) error {
+	for _, window := range s.Windows {
+		if _, _, err := window.minutes(); err != nil {
+			return fmt.Errorf("Invalid Window %s-%s: %w", window.Start, window.End, err)
+		}
+	}
+	s.mu.Lock()
+	err := s.loadLocked()
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for {
+		open, next := s.window(s.now())
+		if !open {
+			if err := s.wait(ctx, next.Sub(s.now()), false); err != nil {
+				return err
+			}
+			continue
+		}
+		op, handler, ok := s.peek()
+		if !ok {
+			// wake at the window's end too, so its close is noticed
+			if err := s.wait(ctx, next.Sub(s.now()), true); err != nil {
+				return err
+			}
+			continue
+		}
+		err := ErrNoOperationHandler
+		if handler != nil {
+			err = handler(ctx, op)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if saveErr := s.remove(op.ID); saveErr != nil {
+			return saveErr
+		}
+		if err != nil && s.OnError != nil {
+			s.OnError(op, err)
+		}
+	}
+}
+
+// NextWindow returns when the window containing after ends, if it falls in
+// one, or else when the next window starts; open says which.
+func (s *BatchScheduler) NextWindow(after time.Time) (open bool, at time.Time) {
+	return s.window(after)
+}
+
+func (s *BatchScheduler) window(now time.Time) (bool, time.Time) {
+	if len(s.Windows) == 0 {
+		return true, time.Time{}
+	}
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	var next time.Time
+	for _, window := range s.Windows {
+		startMinutes, endMinutes, err := window.minutes()
+		if err != nil {
+			continue
+		}
+		// a window that started yesterday may still be open
+		for day := -1; day <= 1; day++ {
+			date := local.AddDate(0, 0, day)
+			start := time.Date(date.Year(), date.Month(), date.Day(), 0, startMinutes, 0, 0, loc)
+			// elapsed rather than wall clock, so the end never lands in the
+			// hour skipped when clocks go forward
+			end := start.Add(time.Duration(endMinutes-startMinutes) * time.Minute)
+			if !now.Before(start) && now.Before(end) {
+				return true, end
+			}
+			if start.After(now) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+	}
+	return false, next
+}
+
+// wait sleeps for d, or until ctx is done or, with wakeOnEnqueue, until an
+// operation is enqueued. A d of 0 or less with wakeOnEnqueue waits for the
+// enqueue alone.
+func (s *BatchScheduler) wait(ctx context.Context, d time.Duration, wakeOnEnqueue bool) error {
+	var wake chan struct{}
+	if wakeOnEnqueue {
+		s.mu.Lock()
+		if s.wake == nil {
+			s.wake = make(chan struct{}, 1)
+		}
+		wake = s.wake
+		s.mu.Unlock()
+	}
+	var timeout <-chan time.Time
+	if d > 0 || !wakeOnEnqueue {
+		timeout = s.clock().After(d)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+	case <-wake:
+	}
+	return nil
+}
+
+func (s *BatchScheduler) peek() (QueuedOperation, OperationHandler, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return QueuedOperation{}, nil, false
+	}
+	op := s.queue[0]
+	return op, s.handlers[op.Kind], true
+}
+
+func (s *BatchScheduler) remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, op := range s.queue {
+		if op.ID == id {
+			s.queue = append(s.queue[:i:i], s.queue[i+1:]...)
+			return s.saveLocked()
+		}
+	}
+	return nil
+}
+
+func (s *BatchScheduler) loadLocked() error {
+	if s.loaded {
+		return nil
+	}
+	if s.Store != nil {
+		queue, err := s.Store.Load()
+		if err != nil {
+			return err
+		}
+		s.queue = append(queue, s.queue...)
+	}
+	s.loaded = true
+	return nil
+}
+
+func (s *BatchScheduler) saveLocked() error {
+	if s.Store == nil {
+		return nil
+	}
+	return s.Store.Save(s.queue)
+}
+
+func (s *BatchScheduler) signalLocked() {
+	if s.wake == nil {
+		s.wake = make(chan struct{}, 1)
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *BatchScheduler) clock() Clock {
+	if s.Clock == nil {
+		return systemClock{}
+	}
+	return s.Clock
+}
+
+func (s *BatchScheduler) now() time.Time {
+	return s.clock().Now()
+}
+
+func (s *BatchScheduler) random() io.Reader {
+	if s.Random == nil {
+		return rand.Reader
+	}
+	return s.Random
+}
@@ -0,0 +1,120 @@
+package tableau4go
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBatchSchedulerNextWindow(t *testing.T) {
+	newYork, zoneErr := time.LoadLocation("America/New_York")
+	if zoneErr != nil {
+		newYork = time.UTC
+	}
+	at := func(loc *time.Location, year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, loc)
+	}
+	tests := []struct {
+		name     string
+		windows  []Window
+		loc      *time.Location
+		now      time.Time
+		open     bool
+		expected time.Time
+		// needs the time zone database
+		zone bool
+	}{
+		{
+			name:     "open before midnight in a window past midnight",
+			windows:  []Window{{"22:00", "02:00"}},
+			now:      at(time.UTC, 2026, 1, 1, 23, 30),
+			open:     true,
+			expected: at(time.UTC, 2026, 1, 2, 2, 0),
+		},
+		{
+			name:     "open after midnight in a window that started yesterday",
+			windows:  []Window{{"22:00", "02:00"}},
+			now:      at(time.UTC, 2026, 1, 2, 1, 0),
+			open:     true,
+			expected: at(time.UTC, 2026, 1, 2, 2, 0),
+		},
+		{
+			name:     "closed at the end of the window",
+			windows:  []Window{{"22:00", "02:00"}},
+			now:      at(time.UTC, 2026, 1, 2, 2, 0),
+			expected: at(time.UTC, 2026, 1, 2, 22, 0),
+		},
+		{
+			name:     "open at the start of the window",
+			windows:  []Window{{"22:00", "02:00"}},
+			now:      at(time.UTC, 2026, 1, 1, 22, 0),
+			open:     true,
+			expected: at(time.UTC, 2026, 1, 2, 2, 0),
+		},
+		{
+			name:     "the earliest of several windows opens next",
+			windows:  []Window{{"12:00", "13:00"}, {"01:00", "03:00"}},
+			now:      at(time.UTC, 2026, 1, 1, 14, 0),
+			expected: at(time.UTC, 2026, 1, 2, 1, 0),
+		},
+		{
+			name:     "window times are read in Location",
+			windows:  []Window{{"22:00", "02:00"}},
+			loc:      time.FixedZone("UTC-5", -5*60*60),
+			now:      at(time.UTC, 2026, 1, 2, 4, 0),
+			open:     true,
+			expected: at(time.UTC, 2026, 1, 2, 7, 0),
+		},
+		{
+			name:     "a window over the spring forward lasts its elapsed time",
+			windows:  []Window{{"01:00", "04:00"}},
+			loc:      newYork,
+			now:      at(newYork, 2026, 3, 8, 3, 30),
+			open:     true,
+			expected: at(newYork, 2026, 3, 8, 5, 0),
+			zone:     true,
+		},
+		{
+			name: "always open without windows",
+			now:  at(time.UTC, 2026, 1, 1, 12, 0),
+			open: true,
+		},
+	}
+	for _, test := range tests {
+		if test.zone && zoneErr != nil {
+			t.Logf("%s: skipped: %v", test.name, zoneErr)
+			continue
+		}
+		scheduler := NewBatchScheduler(nil, test.loc, test.windows...)
+		open, next := scheduler.NextWindow(test.now)
+		if open != test.open || !next.Equal(test.expected) {
+			t.Errorf("%s: got %v %v, expected %v %v", test.name, open, next, test.open, test.expected)
+		}
+	}
+}
+
+func TestBatchSchedulerRunWaitsForWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 21, 0, 0, 0, time.UTC)}
+	scheduler := NewBatchScheduler(nil, time.UTC, Window{"22:00", "02:00"})
+	scheduler.Clock = clock
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ranAt time.Time
+	scheduler.Handle("refresh", func(ctx context.Context, op QueuedOperation) error {
+		ranAt = clock.Now()
+		cancel()
+		return nil
+	})
+	if _, err := scheduler.Enqueue(QueuedOperation{Kind: "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := scheduler.Run(ctx); err != context.Canceled {
+		t.Fatalf("got %v, expected the run to end with its context", err)
+	}
+	if expected := time.Date(2026, 1, 1, 22, 0, 0, 0, time.UTC); !ranAt.Equal(expected) {
+		t.Errorf("operation ran at %v, expected %v", ranAt, expected)
+	}
+	if len(clock.waits) != 1 || clock.waits[0] != time.Hour {
+		t.Errorf("got waits %v, expected one of an hour", clock.waits)
+	}
+}
@@ -0,0 +1,104 @@
+package tableau4go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var ErrForeignHost = errors.New("URL Is Not On The Signed In Server")
+
+// Response is the unprocessed result of a call made with Do.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	codec Codec
+}
+
+// Err returns the Tableau error carried by an unsuccessful response, or nil if
+// the call succeeded. The error is decoded with the client's Codec.
+func (r *Response) Err() error {
+	if r.StatusCode < 300 {
+		return nil
+	}
+	tErrorResponse := ErrorResponse{}
+	if isHTML(r) {
+		return newNonAPIResponseError(r)
+	}
+	codec := r.codec
+	if codec == nil {
+		codec = XMLCodec{}
+	}
+	if err := codec.Unmarshal(r.Body, &tErrorResponse); err != nil || len(tErrorResponse.Error.Code) == 0 {
+		return newNonAPIResponseError(r)
+	}
+	return tErrorResponse.Error
+}
+
+// Do calls an endpoint this package doesn't wrap yet, using the client's auth
+// token and re-authentication. path is relative to the versioned API root, so
+// "sites/<site-id>/webhooks" calls <server>/api/<version>/sites/<site-id>/webhooks;
+// a full URL is used as is, but only on the client's own server, since the
+// call carries its auth token: other hosts fail with ErrForeignHost. A
+// non-empty body is sent as XML unless a Content-Type is set on the call with
+// headers, and the response is asked for in the Codec's format unless an
+// Accept header is.
+//
+// Unlike the wrapped calls, Do does not turn error statuses into errors: the
+// returned error only reports transport failures. Use Response.Err to get the
+// Tableau error from an unsuccessful response.
+func (api *API) Do(ctx context.Context, method, path string, body []byte, headers ...map[string]string) (*Response, error) {
+	requestUrl := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		requestUrl = fmt.Sprintf("%s/api/%s/%s", api.Server, api.Version, strings.TrimPrefix(path, "/"))
+	} else if !api.sameServer(path) {
+		return nil, ErrForeignHost
+	}
+	requestHeaders := make(map[string]string)
+	if codec := api.codec(); codec.ContentType() != application_xml_content_type {
+		requestHeaders[accept_header] = codec.ContentType()
+	}
+	if len(body) > 0 {
+		requestHeaders[content_type_header] = application_xml_content_type
+	}
+	for _, h := range headers {
+		for header, headerValue := range h {
+			requestHeaders[header] = headerValue
+		}
+	}
+	return api.do(ctx, method, requestUrl, body, requestHeaders)
+}
+
+// do sends the request, signing in again and resending it once if the session
+// has expired.
+func (api *API) do(ctx context.Context, method, requestUrl string, body []byte, headers map[string]string) (*Response, error) {
+	token := api.authToken()
+	resp, err := api.roundTrip(ctx, method, requestUrl, bytes.NewReader(body), headers)
+	if err == nil && api.CredentialProvider != nil && isUnauthorized(resp.Err()) {
+		if err := api.resignin(token); err != nil {
+			return resp, err
+		}
+		resp, err = api.roundTrip(ctx, method, requestUrl, bytes.NewReader(body), headers)
+	}
+	return resp, err
+}
+
+// sameServer reports whether requestUrl has the scheme and host of
+// api.Server.
+func (api *API) sameServer(requestUrl string) bool {
+	target, err := url.Parse(requestUrl)
+	if err != nil {
+		return false
+	}
+	server, err := url.Parse(api.Server)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(target.Scheme, server.Scheme) && strings.EqualFold(target.Host, server.Host)
+}
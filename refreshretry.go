@@ -0,0 +1,112 @@
+package tableau4go
+
+import (
+	"context"
+	"time"
+)
+
+// the jobType of extract refreshes in job listings
+const JOB_TYPE_REFRESH_EXTRACTS = "refresh_extracts"
+
+// RetryRefreshOptions tunes RetryFailedRefreshes. Zero values take the
+// defaults: failures from the last 12 hours, at most 50 refreshes started, 10
+// seconds between them and 3 attempts to start each one.
+type RetryRefreshOptions struct {
+	Since    time.Duration
+	Max      int
+	Backoff  time.Duration
+	Attempts int
+}
+
+// RetryFailedRefreshes finds extract refreshes that failed within
+// opts.Since and starts them again with UpdateDatasourceNow or
+// UpdateWorkbookNow, once per datasource or workbook however often it failed.
+// Refreshes are started opts.Backoff apart so the backgrounder queue is not
+// flooded; a refresh the server refuses to start is retried with the backoff
+// doubling each time. It returns the jobs it started.
+//
+// Failures are returned as a *MultiError keyed by the ID of the failed job.
+func (api *API) RetryFailedRefreshes(ctx context.Context, siteId SiteID, opts RetryRefreshOptions) ([]Job, error) {
+	if opts.Since <= 0 {
+		opts.Since = 12 * time.Hour
+	}
+	if opts.Max <= 0 {
+		opts.Max = 50
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 10 * time.Second
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = 3
+	}
+	client := api.WithContext(ctx)
+	since := api.now().UTC().Add(-opts.Since).Format(job_time_format)
+	listOpts := ListOptions{
+		Filter: "jobType:eq:" + JOB_TYPE_REFRESH_EXTRACTS + ",status:eq:" + JOB_STATUS_FAILED + ",endedAt:gte:" + since,
+		Sort:   "endedAt:desc",
+	}
+	failed, err := client.queryAllJobs(siteId, listOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	started := []Job{}
+	retried := map[string]bool{}
+	result := &MultiError{}
+	for _, failure := range failed {
+		if len(started) >= opts.Max {
+			break
+		}
+		details, err := client.QueryJob(siteId, failure.ID)
+		if err != nil {
+			result.fail(string(failure.ID), err)
+			continue
+		}
+		refresh := details.ExtractRefreshJob
+		var run func() (Job, error)
+		key := ""
+		switch {
+		case refresh != nil && refresh.Datasource != nil:
+			key = "datasource:" + string(refresh.Datasource.ID)
+			run = func() (Job, error) { return client.UpdateDatasourceNow(siteId, refresh.Datasource.ID) }
+		case refresh != nil && refresh.Workbook != nil:
+			key = "workbook:" + string(refresh.Workbook.ID)
+			run = func() (Job, error) { return client.UpdateWorkbookNow(siteId, refresh.Workbook.ID) }
+		default:
+			// the refreshed content has been deleted since
+			continue
+		}
+		if retried[key] {
+			continue
+		}
+		retried[key] = true
+		if len(started) > 0 {
+			if err := api.sleep(ctx, opts.Backoff); err != nil {
+				return started, err
+			}
+		}
+		job, err := api.retryWithBackoff(ctx, opts.Attempts, opts.Backoff, run)
+		result.record(string(failure.ID), err)
+		if err == nil {
+			started = append(started, job)
+		}
+	}
+	return started, result.err()
+}
+
+func (api *API) retryWithBackoff(ctx context.Context, attempts int, backoff time.Duration, run func() (Job, error)) (Job, error) {
+	var job Job
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if sleepErr := api.sleep(ctx, backoff); sleepErr != nil {
+				return job, sleepErr
+			}
+			backoff *= 2
+		}
+		if job, err = run(); err == nil {
+			return job, nil
+		}
+	}
+	return job, err
+}
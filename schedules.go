@@ -0,0 +1,91 @@
+package tableau4go
+
+import "strings"
+
+// ScheduleFilter selects schedules by type and state. Empty fields match
+// every schedule.
+type ScheduleFilter struct {
+	Type  ScheduleType
+	State ScheduleState
+}
+
+func (f ScheduleFilter) expression() string {
+	terms := []string{}
+	if len(f.Type) > 0 {
+		terms = append(terms, "type:eq:"+string(f.Type))
+	}
+	if len(f.State) > 0 {
+		terms = append(terms, "state:eq:"+string(f.State))
+	}
+	return strings.Join(terms, ",")
+}
+
+func (f ScheduleFilter) matches(schedule Schedule) bool {
+	return (len(f.Type) == 0 || f.Type == schedule.Type) && (len(f.State) == 0 || f.State == schedule.State)
+}
+
+// FindSchedules returns every schedule matching filter. The filter is applied
+// by the server, and again locally since older servers ignore it.
+func (api *API) FindSchedules(filter ScheduleFilter) ([]Schedule, error) {
+	if len(filter.Type) > 0 {
+		if err := filter.Type.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if len(filter.State) > 0 {
+		if err := filter.State.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	schedules, err := api.queryAllSchedules(ListOptions{Filter: filter.expression()})
+	if err != nil {
+		return nil, err
+	}
+	found := []Schedule{}
+	for _, schedule := range schedules {
+		if filter.matches(schedule) {
+			found = append(found, schedule)
+		}
+	}
+	return found, nil
+}
+
+func (api *API) DisableSchedule(scheduleId ScheduleID) (Schedule, error) {
+	return api.UpdateSchedule(Schedule{ID: scheduleId, State: ScheduleStateSuspended})
+}
+
+func (api *API) EnableSchedule(scheduleId ScheduleID) (Schedule, error) {
+	return api.UpdateSchedule(Schedule{ID: scheduleId, State: ScheduleStateActive})
+}
+
+// SuspendSchedules suspends every active schedule of the given type, e.g. all
+// extract refreshes for a maintenance window, and returns the ones it
+// suspended so exactly those can be passed to ResumeSchedules afterwards.
+// Failures are returned as a *MultiError keyed by schedule ID.
+func (api *API) SuspendSchedules(scheduleType ScheduleType) ([]ScheduleID, error) {
+	schedules, err := api.FindSchedules(ScheduleFilter{Type: scheduleType, State: ScheduleStateActive})
+	if err != nil {
+		return nil, err
+	}
+	suspended := []ScheduleID{}
+	result := &MultiError{}
+	for _, schedule := range schedules {
+		_, err := api.DisableSchedule(schedule.ID)
+		result.record(string(schedule.ID), err)
+		if err == nil {
+			suspended = append(suspended, schedule.ID)
+		}
+	}
+	return suspended, result.err()
+}
+
+// ResumeSchedules re-activates the given schedules. Failures are returned as
+// a *MultiError keyed by schedule ID.
+func (api *API) ResumeSchedules(scheduleIds []ScheduleID) error {
+	result := &MultiError{}
+	for _, scheduleId := range scheduleIds {
+		_, err := api.EnableSchedule(scheduleId)
+		result.record(string(scheduleId), err)
+	}
+	return result.err()
+}
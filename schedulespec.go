@@ -0,0 +1,179 @@
+package tableau4go
+
+// ScheduleTaskSpec is a workbook or datasource refreshed by a schedule,
+// identified by project path and name so it can be found on another site.
+type ScheduleTaskSpec struct {
+	ContentType string `json:"contentType"`
+	ProjectPath string `json:"projectPath"`
+	Name        string `json:"name"`
+}
+
+// ScheduleSpec is a schedule and the extract refreshes it runs on one site,
+// without the ids and timestamps that only mean something on the server it
+// came from.
+type ScheduleSpec struct {
+	Schedule Schedule           `json:"schedule"`
+	Tasks    []ScheduleTaskSpec `json:"tasks"`
+}
+
+// ExportSchedules returns the site's schedules with the extract refreshes
+// each runs there. Schedules are server wide on Tableau Server, so this
+// needs a server administrator; schedules with nothing on the site are
+// included too, since other sites on the target server may need them.
+func (api *API) ExportSchedules(siteId SiteID) ([]ScheduleSpec, error) {
+	schedules, err := api.FindSchedules(ScheduleFilter{})
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := api.QueryExtractRefreshTasks(siteId)
+	if err != nil {
+		return nil, err
+	}
+	content, err := api.contentByID(siteId)
+	if err != nil {
+		return nil, err
+	}
+	specs := []ScheduleSpec{}
+	index := map[ScheduleID]int{}
+	for _, schedule := range schedules {
+		index[schedule.ID] = len(specs)
+		portable := schedule
+		portable.ID, portable.CreatedAt, portable.UpdatedAt, portable.NextRunAt = "", "", "", ""
+		specs = append(specs, ScheduleSpec{Schedule: portable, Tasks: []ScheduleTaskSpec{}})
+	}
+	for _, task := range tasks {
+		if task.Schedule == nil {
+			continue
+		}
+		i, ok := index[task.Schedule.ID]
+		if !ok {
+			continue
+		}
+		var spec ScheduleTaskSpec
+		switch {
+		case task.Workbook != nil:
+			spec, ok = content[CONTENT_TYPE_WORKBOOK+":"+string(task.Workbook.ID)]
+		case task.Datasource != nil:
+			spec, ok = content[CONTENT_TYPE_DATASOURCE+":"+string(task.Datasource.ID)]
+		}
+		if ok {
+			specs[i].Tasks = append(specs[i].Tasks, spec)
+		}
+	}
+	return specs, nil
+}
+
+// ImportSchedules recreates specs on the target server, reusing schedules
+// that already exist there with the same name, and adds each task's
+// workbook or datasource on the site to its schedule, matching content by
+// project path and name. Tasks already on the site for the same content and
+// schedule are left alone, so importing again only adds what is missing.
+// Failures don't stop the rest and are returned as a
+// *MultiError keyed by "schedule:<name>" or "<type>:<project path>/<name>";
+// content that isn't on the site fails with ErrDoesNotExist.
+func (api *API) ImportSchedules(siteId SiteID, specs []ScheduleSpec) error {
+	existing, err := api.FindSchedules(ScheduleFilter{})
+	if err != nil {
+		return err
+	}
+	scheduleIds := map[string]ScheduleID{}
+	for _, schedule := range existing {
+		scheduleIds[schedule.Name] = schedule.ID
+	}
+	content, err := api.contentByID(siteId)
+	if err != nil {
+		return err
+	}
+	contentIds := map[ScheduleTaskSpec]string{}
+	for key, spec := range content {
+		contentIds[spec] = key[len(spec.ContentType)+1:]
+	}
+	tasks, err := api.QueryExtractRefreshTasks(siteId)
+	if err != nil {
+		return err
+	}
+	// "<schedule id>/<type>:<id>" of the refreshes already scheduled
+	scheduled := map[string]bool{}
+	for _, task := range tasks {
+		if task.Schedule == nil {
+			continue
+		}
+		switch {
+		case task.Workbook != nil:
+			scheduled[string(task.Schedule.ID)+"/"+CONTENT_TYPE_WORKBOOK+":"+string(task.Workbook.ID)] = true
+		case task.Datasource != nil:
+			scheduled[string(task.Schedule.ID)+"/"+CONTENT_TYPE_DATASOURCE+":"+string(task.Datasource.ID)] = true
+		}
+	}
+	result := &MultiError{}
+	for _, spec := range specs {
+		scheduleId, ok := scheduleIds[spec.Schedule.Name]
+		if !ok {
+			// new schedules start active; the server doesn't take a state
+			create := spec.Schedule
+			create.State = ""
+			created, err := api.CreateSchedule(create)
+			result.record("schedule:"+spec.Schedule.Name, err)
+			if err != nil {
+				continue
+			}
+			scheduleId = created.ID
+		}
+		for _, task := range spec.Tasks {
+			key := task.ContentType + ":" + task.ProjectPath + "/" + task.Name
+			id, ok := contentIds[task]
+			if !ok {
+				result.fail(key, ErrDoesNotExist)
+				continue
+			}
+			taskKey := string(scheduleId) + "/" + task.ContentType + ":" + id
+			if scheduled[taskKey] {
+				continue
+			}
+			if task.ContentType == CONTENT_TYPE_WORKBOOK {
+				_, err = api.AddWorkbookToSchedule(siteId, scheduleId, WorkbookID(id))
+			} else {
+				_, err = api.AddDatasourceToSchedule(siteId, scheduleId, DatasourceID(id))
+			}
+			if err == nil {
+				scheduled[taskKey] = true
+			}
+			result.record(key, err)
+		}
+	}
+	return result.err()
+}
+
+// contentByID maps "<type>:<id>" of the site's workbooks and datasources to
+// their portable description
+func (api *API) contentByID(siteId SiteID) (map[string]ScheduleTaskSpec, error) {
+	projects, err := api.queryAllProjects(siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	paths := projectPaths(projects)
+	content := map[string]ScheduleTaskSpec{}
+	workbooks, err := api.queryAllWorkbooks(siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, workbook := range workbooks {
+		spec := ScheduleTaskSpec{ContentType: CONTENT_TYPE_WORKBOOK, Name: workbook.Name}
+		if workbook.Project != nil {
+			spec.ProjectPath = paths[workbook.Project.ID]
+		}
+		content[CONTENT_TYPE_WORKBOOK+":"+string(workbook.ID)] = spec
+	}
+	datasources, err := api.queryAllDatasources(siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, datasource := range datasources {
+		spec := ScheduleTaskSpec{ContentType: CONTENT_TYPE_DATASOURCE, Name: datasource.Name}
+		if datasource.Project != nil {
+			spec.ProjectPath = paths[datasource.Project.ID]
+		}
+		content[CONTENT_TYPE_DATASOURCE+":"+string(datasource.ID)] = spec
+	}
+	return content, nil
+}
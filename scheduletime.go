@@ -0,0 +1,140 @@
+package tableau4go
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNoFrequencyDetails = errors.New("Schedule Has No Frequency Details")
+
+// the wall clock format of FrequencyDetails start and end times
+const schedule_clock_format = "15:04:05"
+
+// how far ahead NextRunAfter looks before giving up on a schedule that never
+// runs, e.g. monthly on the 31st of no month
+const schedule_search_days = 366 * 4
+
+// ParseScheduleTime parses the UTC timestamps the server puts on schedules,
+// jobs and content, e.g. NextRunAt.
+func ParseScheduleTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339, value)
+}
+
+// NextRun returns when the server will next run the schedule, in UTC, or the
+// zero time if the server didn't say.
+func (s Schedule) NextRun() (time.Time, error) {
+	if len(s.NextRunAt) == 0 {
+		return time.Time{}, nil
+	}
+	return ParseScheduleTime(s.NextRunAt)
+}
+
+// NextRunIn returns NextRun in loc, e.g. for display to users in their own
+// time zone.
+func (s Schedule) NextRunIn(loc *time.Location) (time.Time, error) {
+	next, err := s.NextRun()
+	if err != nil || next.IsZero() {
+		return next, err
+	}
+	return next.In(loc), nil
+}
+
+// NextRunAfter computes the first run of the schedule after after from its
+// FrequencyDetails, reading their wall clock times in loc, which should be
+// the server's time zone. Days are stepped on the calendar rather than in
+// 24 hour increments, so runs stay at the same wall clock time across
+// daylight saving changes; a run in the hour skipped when clocks go forward
+// happens an hour later, as time.Date normalizes it.
+func (s Schedule) NextRunAfter(after time.Time, loc *time.Location) (time.Time, error) {
+	details := s.FrequencyDetails
+	if details == nil {
+		return time.Time{}, ErrNoFrequencyDetails
+	}
+	start, err := clockMinutes(details.Start)
+	if err != nil {
+		return time.Time{}, err
+	}
+	end := 24 * 60
+	if len(details.End) > 0 {
+		if end, err = clockMinutes(details.End); err != nil {
+			return time.Time{}, err
+		}
+		if end <= start {
+			end += 24 * 60
+		}
+	}
+	step, weekDays, monthDays := 0, map[time.Weekday]bool{}, map[string]bool{}
+	for _, interval := range details.intervals() {
+		if hours, err := strconv.Atoi(interval.Hours); err == nil {
+			step = hours * 60
+		}
+		if minutes, err := strconv.Atoi(interval.Minutes); err == nil {
+			step = minutes
+		}
+		if day, ok := weekDayNames[strings.ToLower(interval.WeekDay)]; ok {
+			weekDays[day] = true
+		}
+		if len(interval.MonthDay) > 0 {
+			monthDays[interval.MonthDay] = true
+		}
+	}
+	local := after.In(loc)
+	// start the day before, in case a run late on it ends after midnight
+	year, month, day := local.AddDate(0, 0, -1).Date()
+	for i := 0; i < schedule_search_days; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, loc)
+		if !runsOn(s.Frequency, date, weekDays, monthDays) {
+			continue
+		}
+		for minute := start; minute < end || minute == start; minute += step {
+			run := time.Date(date.Year(), date.Month(), date.Day(), 0, minute, 0, 0, loc)
+			if run.After(after) {
+				return run, nil
+			}
+			if step <= 0 || s.Frequency != ScheduleFrequencyHourly && s.Frequency != ScheduleFrequencyDaily {
+				break
+			}
+		}
+	}
+	return time.Time{}, ErrDoesNotExist
+}
+
+func (d *FrequencyDetails) intervals() []Interval {
+	if d.Intervals == nil {
+		return nil
+	}
+	return d.Intervals.Intervals
+}
+
+var weekDayNames = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// runsOn reports whether a schedule runs at all on date. Hourly and daily
+// schedules without week days run every day.
+func runsOn(frequency ScheduleFrequency, date time.Time, weekDays map[time.Weekday]bool, monthDays map[string]bool) bool {
+	if frequency == ScheduleFrequencyMonthly {
+		if monthDays[strconv.Itoa(date.Day())] {
+			return true
+		}
+		return monthDays["LastDay"] && date.AddDate(0, 0, 1).Month() != date.Month()
+	}
+	return len(weekDays) == 0 || weekDays[date.Weekday()]
+}
+
+// clockMinutes returns the minutes after midnight of a "15:04:05" time
+func clockMinutes(clock string) (int, error) {
+	parsed, err := time.Parse(schedule_clock_format, clock)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,197 @@
+// Package scim provisions Tableau Cloud users and groups through the site's
+// SCIM 2.0 endpoints, the interface identity providers use. It authenticates
+// with the site's SCIM secret rather than a REST API session, so it works
+// where the REST user calls are reserved for the identity provider.
+//
+// Client's BaseURL is the base URL Tableau Cloud shows next to the secret
+// when SCIM is turned on for the site; the resource paths are appended to it.
+package scim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrNotFound = errors.New("SCIM Resource Not Found")
+
+const scim_content_type = "application/scim+json"
+
+type Client struct {
+	BaseURL string
+	Secret  string
+	// HTTPClient is http.DefaultClient when nil
+	HTTPClient *http.Client
+}
+
+func NewClient(baseURL, secret string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), Secret: secret}
+}
+
+func (c *Client) CreateUser(ctx context.Context, user User) (User, error) {
+	created := User{}
+	err := c.do(ctx, http.MethodPost, "/Users", user, &created)
+	return created, err
+}
+
+func (c *Client) GetUser(ctx context.Context, id string) (User, error) {
+	user := User{}
+	err := c.do(ctx, http.MethodGet, "/Users/"+url.PathEscape(id), nil, &user)
+	return user, err
+}
+
+// FindUser returns the user with the given user name, or ErrNotFound.
+func (c *Client) FindUser(ctx context.Context, userName string) (User, error) {
+	list, err := c.ListUsers(ctx, ListOptions{Filter: "userName eq " + Quote(userName)})
+	if err != nil {
+		return User{}, err
+	}
+	if len(list.Resources) == 0 {
+		return User{}, ErrNotFound
+	}
+	return list.Resources[0], nil
+}
+
+func (c *Client) ListUsers(ctx context.Context, opts ListOptions) (UserList, error) {
+	list := UserList{}
+	err := c.do(ctx, http.MethodGet, "/Users"+opts.query(), nil, &list)
+	return list, err
+}
+
+// ReplaceUser replaces every attribute of the user; attributes left out are
+// cleared.
+func (c *Client) ReplaceUser(ctx context.Context, user User) (User, error) {
+	replaced := User{}
+	err := c.do(ctx, http.MethodPut, "/Users/"+url.PathEscape(user.ID), user, &replaced)
+	return replaced, err
+}
+
+func (c *Client) PatchUser(ctx context.Context, id string, operations ...Operation) (User, error) {
+	patched := User{}
+	err := c.do(ctx, http.MethodPatch, "/Users/"+url.PathEscape(id), patchRequest{Schemas: []string{PatchOpSchema}, Operations: operations}, &patched)
+	return patched, err
+}
+
+// DeactivateUser sets the user inactive, which unlicenses them on the site
+// but keeps their content.
+func (c *Client) DeactivateUser(ctx context.Context, id string) (User, error) {
+	return c.PatchUser(ctx, id, Operation{Op: "replace", Path: "active", Value: false})
+}
+
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/Users/"+url.PathEscape(id), nil, nil)
+}
+
+func (c *Client) CreateGroup(ctx context.Context, group Group) (Group, error) {
+	created := Group{}
+	err := c.do(ctx, http.MethodPost, "/Groups", group, &created)
+	return created, err
+}
+
+func (c *Client) GetGroup(ctx context.Context, id string) (Group, error) {
+	group := Group{}
+	err := c.do(ctx, http.MethodGet, "/Groups/"+url.PathEscape(id), nil, &group)
+	return group, err
+}
+
+func (c *Client) ListGroups(ctx context.Context, opts ListOptions) (GroupList, error) {
+	list := GroupList{}
+	err := c.do(ctx, http.MethodGet, "/Groups"+opts.query(), nil, &list)
+	return list, err
+}
+
+func (c *Client) PatchGroup(ctx context.Context, id string, operations ...Operation) error {
+	return c.do(ctx, http.MethodPatch, "/Groups/"+url.PathEscape(id), patchRequest{Schemas: []string{PatchOpSchema}, Operations: operations}, nil)
+}
+
+func (c *Client) AddGroupMembers(ctx context.Context, groupId string, userIds ...string) error {
+	members := make([]Member, 0, len(userIds))
+	for _, id := range userIds {
+		members = append(members, Member{Value: id})
+	}
+	return c.PatchGroup(ctx, groupId, Operation{Op: "add", Path: "members", Value: members})
+}
+
+func (c *Client) RemoveGroupMembers(ctx context.Context, groupId string, userIds ...string) error {
+	operations := make([]Operation, 0, len(userIds))
+	for _, id := range userIds {
+		operations = append(operations, Operation{Op: "remove", Path: fmt.Sprintf("members[value eq %s]", Quote(id))})
+	}
+	return c.PatchGroup(ctx, groupId, operations...)
+}
+
+func (c *Client) DeleteGroup(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/Groups/"+url.PathEscape(id), nil, nil)
+}
+
+func (o ListOptions) query() string {
+	params := url.Values{}
+	if len(o.Filter) > 0 {
+		params.Set("filter", o.Filter)
+	}
+	if o.StartIndex > 0 {
+		params.Set("startIndex", strconv.Itoa(o.StartIndex))
+	}
+	if o.Count > 0 {
+		params.Set("count", strconv.Itoa(o.Count))
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + params.Encode()
+}
+
+func (c *Client) do(ctx context.Context, method, path string, payload interface{}, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Secret)
+	req.Header.Set("Accept", scim_content_type)
+	if payload != nil {
+		req.Header.Set("Content-Type", scim_content_type)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		scimErr := &Error{}
+		if json.Unmarshal(data, scimErr) != nil || len(scimErr.Status) == 0 {
+			scimErr.Status = strconv.Itoa(resp.StatusCode)
+			scimErr.Detail = strings.Join(strings.Fields(string(data)), " ")
+		}
+		return scimErr
+	}
+	if result != nil && len(data) > 0 {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
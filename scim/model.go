@@ -0,0 +1,134 @@
+package scim
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	UserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	GroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	TableauUserSchema  = "urn:ietf:params:scim:schemas:extension:tableau:2.0:User"
+	ListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PatchOpSchema      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ErrorSchema        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+type Name struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	Formatted  string `json:"formatted,omitempty"`
+}
+
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// TableauUser is Tableau's extension to the SCIM user, carrying the site role
+// the user is given and how they sign in.
+type TableauUser struct {
+	SiteRole    string `json:"siteRole,omitempty"`
+	AuthSetting string `json:"authSetting,omitempty"`
+}
+
+// User is a SCIM user. UserName is the Tableau user name, normally the
+// user's email address. Active false removes the user's access without
+// deleting them.
+type User struct {
+	Schemas    []string     `json:"schemas"`
+	ID         string       `json:"id,omitempty"`
+	ExternalID string       `json:"externalId,omitempty"`
+	UserName   string       `json:"userName"`
+	Name       *Name        `json:"name,omitempty"`
+	Emails     []Email      `json:"emails,omitempty"`
+	Active     *bool        `json:"active,omitempty"`
+	Tableau    *TableauUser `json:"urn:ietf:params:scim:schemas:extension:tableau:2.0:User,omitempty"`
+}
+
+// NewUser returns a user with the schemas set, given the site role.
+func NewUser(userName, siteRole string) User {
+	return User{
+		Schemas:  []string{UserSchema, TableauUserSchema},
+		UserName: userName,
+		Tableau:  &TableauUser{SiteRole: siteRole},
+	}
+}
+
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type Group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []Member `json:"members,omitempty"`
+}
+
+func NewGroup(displayName string) Group {
+	return Group{Schemas: []string{GroupSchema}, DisplayName: displayName}
+}
+
+type UserList struct {
+	TotalResults int    `json:"totalResults"`
+	StartIndex   int    `json:"startIndex"`
+	ItemsPerPage int    `json:"itemsPerPage"`
+	Resources    []User `json:"Resources"`
+}
+
+type GroupList struct {
+	TotalResults int     `json:"totalResults"`
+	StartIndex   int     `json:"startIndex"`
+	ItemsPerPage int     `json:"itemsPerPage"`
+	Resources    []Group `json:"Resources"`
+}
+
+// Operation is one change in a PATCH request, e.g. adding members to a
+// group.
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type patchRequest struct {
+	Schemas    []string    `json:"schemas"`
+	Operations []Operation `json:"Operations"`
+}
+
+// ListOptions pages and filters list calls. StartIndex is 1 based; zero
+// values leave the server defaults. Filter uses SCIM syntax, e.g.
+// `userName eq "ana@example.com"`.
+type ListOptions struct {
+	Filter     string
+	StartIndex int
+	Count      int
+}
+
+// Error is a SCIM error response. Status is the HTTP status as the server
+// reports it, a string per RFC 7644.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+func (e *Error) Error() string {
+	parts := []string{"SCIM Error " + e.Status}
+	if len(e.ScimType) > 0 {
+		parts = append(parts, e.ScimType)
+	}
+	if len(e.Detail) > 0 {
+		parts = append(parts, e.Detail)
+	}
+	return strings.Join(parts, ": ")
+}
+
+// Quote quotes a value for use in a filter.
+func Quote(value string) string {
+	return fmt.Sprintf("%q", value)
+}
@@ -0,0 +1,164 @@
+package tableau4go
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tableau Server's default idle session timeout; used when the server does not
+// report how long a new session will last
+const DEFAULT_SESSION_TIMEOUT = 240 * time.Minute
+
+var ErrSessionExpired = errors.New("Session Expired")
+var ErrSessionServerMismatch = errors.New("Session Belongs To A Different Server")
+
+// Session is the serializable part of a signed in client. Persisting it lets
+// short lived processes reuse a sign in instead of creating a new session (and
+// consuming a personal access token session slot) on every run.
+type Session struct {
+	Server         string        `json:"server"`
+	AuthToken      string        `json:"authToken"`
+	SiteID         SiteID        `json:"siteId"`
+	SiteContentUrl string        `json:"siteContentUrl"`
+	UserID         UserID        `json:"userId"`
+	IssuedAt       time.Time     `json:"issuedAt,omitempty"`
+	ExpiresAt      time.Time     `json:"expiresAt"`
+	IdleTimeout    time.Duration `json:"idleTimeout,omitempty"`
+}
+
+// sessionGuard guards a client's AuthToken, SiteID, SiteContentUrl, UserID
+// and session clock, which a sign in made on one goroutine replaces while
+// others send requests with them. It also lets goroutines whose requests
+// were refused with a 401 at the same time share one sign in, since each
+// sign in ends the session the others would retry with.
+type sessionGuard struct {
+	mu sync.RWMutex
+	// signin is the sign in after a 401 that is running, if any
+	signin *pendingSignin
+}
+
+type pendingSignin struct {
+	done chan struct{}
+	err  error
+}
+
+// guard returns the session guard, creating it for clients that were not
+// built with NewAPI; like lifecycle, that is not safe for concurrent use.
+func (api *API) guard() *sessionGuard {
+	if api.session == nil {
+		api.session = &sessionGuard{}
+	}
+	return api.session
+}
+
+// currentSession returns the session fields, read under the session lock.
+func (api *API) currentSession() (Session, *sessionClock) {
+	g := api.guard()
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return Session{Server: api.Server, AuthToken: api.AuthToken, SiteID: api.SiteID, SiteContentUrl: api.SiteContentUrl, UserID: api.UserID}, api.clock
+}
+
+// authToken returns the token requests are sent with.
+func (api *API) authToken() string {
+	session, _ := api.currentSession()
+	return session.AuthToken
+}
+
+// setSession replaces the session fields under the session lock.
+func (api *API) setSession(session Session, clock *sessionClock) {
+	g := api.guard()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	api.AuthToken = session.AuthToken
+	api.SiteID = session.SiteID
+	api.SiteContentUrl = session.SiteContentUrl
+	api.UserID = session.UserID
+	api.clock = clock
+}
+
+// resignin signs in again through the CredentialProvider after a request sent
+// with the token rejected was refused. When that token has already been
+// replaced it returns at once so the request is retried with the new one, and
+// when another goroutine is already signing in it waits for that sign in.
+func (api *API) resignin(rejected string) error {
+	g := api.guard()
+	g.mu.Lock()
+	if api.AuthToken != rejected {
+		g.mu.Unlock()
+		return nil
+	}
+	if pending := g.signin; pending != nil {
+		g.mu.Unlock()
+		<-pending.done
+		return pending.err
+	}
+	pending := &pendingSignin{done: make(chan struct{})}
+	g.signin = pending
+	g.mu.Unlock()
+
+	pending.err = api.SigninWithProvider()
+	g.mu.Lock()
+	g.signin = nil
+	g.mu.Unlock()
+	close(pending.done)
+	return pending.err
+}
+
+// SaveSession writes the current auth token, site and expiry to w. The output
+// contains a live credential and should be stored accordingly.
+func (api *API) SaveSession(w io.Writer) error {
+	session, clock := api.currentSession()
+	if clock != nil {
+		session.IssuedAt = clock.issuedAt
+		session.ExpiresAt = clock.expiry()
+		session.IdleTimeout = clock.idle
+	}
+	return json.NewEncoder(w).Encode(session)
+}
+
+// LoadSession restores a session written by SaveSession. It refuses sessions
+// for another server and sessions that have already expired, in which case
+// the caller should sign in again. The server may still reject a restored
+// token, for example if it was signed out elsewhere.
+func (api *API) LoadSession(r io.Reader) error {
+	session := Session{}
+	if err := json.NewDecoder(r).Decode(&session); err != nil {
+		return err
+	}
+	if session.Server != api.Server {
+		return ErrSessionServerMismatch
+	}
+	if len(session.AuthToken) == 0 || !api.now().Before(session.ExpiresAt) {
+		return ErrSessionExpired
+	}
+	idle := session.IdleTimeout
+	if idle <= 0 {
+		idle = api.sessionIdleTimeout("")
+	}
+	api.setSession(session, &sessionClock{issuedAt: session.IssuedAt, expiresAt: session.ExpiresAt, idle: idle})
+	return nil
+}
+
+// sessionLifetime parses the "hours:minutes:seconds" estimatedTimeToExpiration
+// returned by newer servers, falling back to DEFAULT_SESSION_TIMEOUT.
+func sessionLifetime(estimate string) time.Duration {
+	parts := strings.Split(estimate, ":")
+	if len(parts) != 3 {
+		return DEFAULT_SESSION_TIMEOUT
+	}
+	var lifetime time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 {
+			return DEFAULT_SESSION_TIMEOUT
+		}
+		lifetime += time.Duration(n) * unit
+	}
+	return lifetime
+}
@@ -0,0 +1,159 @@
+package tableau4go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrent requests refused because the token expired must share one sign
+// in, or each would end the session the others just got
+func TestConcurrentUnauthorizedShareSignin(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(api *API) error
+	}{
+		{
+			name: "makeRequest",
+			call: func(api *API) error {
+				_, err := api.QuerySite("s", false)
+				return err
+			},
+		},
+		{
+			name: "Do",
+			call: func(api *API) error {
+				resp, err := api.Do(context.Background(), GET, "sites/s", nil)
+				if err == nil {
+					err = resp.Err()
+				}
+				return err
+			},
+		},
+	}
+	for _, test := range tests {
+		var signins atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/"+API_VERSION+"/auth/signin" {
+				n := signins.Add(1)
+				fmt.Fprintf(w, `<tsResponse><credentials token="fresh%d"><site id="s" contentUrl=""/></credentials></tsResponse>`, n)
+				return
+			}
+			if r.Header.Get(auth_header) != "fresh1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `<tsResponse><error code="401002"><summary>Unauthorized</summary></error></tsResponse>`)
+				return
+			}
+			fmt.Fprint(w, `<tsResponse><site id="s"/></tsResponse>`)
+		}))
+		api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+		api.AuthToken = "expired"
+		api.CredentialProvider = CredentialProviderFunc(func() (Credentials, error) {
+			return Credentials{PersonalAccessTokenName: "name", PersonalAccessTokenSecret: "secret"}, nil
+		})
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 16)
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- test.call(&api)
+			}()
+		}
+		wg.Wait()
+		server.Close()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Errorf("%s: %v", test.name, err)
+			}
+		}
+		if n := signins.Load(); n != 1 {
+			t.Errorf("%s: signed in %d times, expected once", test.name, n)
+		}
+		if token := api.authToken(); token != "fresh1" {
+			t.Errorf("%s: got token %s, expected fresh1", test.name, token)
+		}
+	}
+}
+
+func TestLoadSessionExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		session Session
+		err     error
+	}{
+		{"live", Session{Server: DEFAULT_SERVER, AuthToken: "t", ExpiresAt: now.Add(time.Minute)}, nil},
+		{"expired", Session{Server: DEFAULT_SERVER, AuthToken: "t", ExpiresAt: now}, ErrSessionExpired},
+		{"no token", Session{Server: DEFAULT_SERVER, ExpiresAt: now.Add(time.Minute)}, ErrSessionExpired},
+		{"another server", Session{Server: "https://elsewhere", AuthToken: "t", ExpiresAt: now.Add(time.Minute)}, ErrSessionServerMismatch},
+	}
+	for _, test := range tests {
+		data, err := json.Marshal(test.session)
+		if err != nil {
+			t.Fatal(err)
+		}
+		api := NewAPI(DEFAULT_SERVER, API_VERSION, BOUNDARY_STRING, "", true)
+		api.Clock = &fakeClock{now: now}
+		if err := api.LoadSession(bytes.NewReader(data)); err != test.err {
+			t.Errorf("%s: got error %v, expected %v", test.name, err, test.err)
+		}
+	}
+}
+
+// authenticated requests push the expiry back by the idle timeout; refused
+// ones don't
+func TestSessionExpiryMovesWithActivity(t *testing.T) {
+	refuse := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/"+API_VERSION+"/auth/signin" {
+			fmt.Fprint(w, `<tsResponse><credentials token="t" estimatedTimeToExpiration="0:30:0"><site id="s" contentUrl=""/></credentials></tsResponse>`)
+			return
+		}
+		if refuse {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `<tsResponse><error code="401002"><summary>Unauthorized</summary></error></tsResponse>`)
+			return
+		}
+		fmt.Fprint(w, `<tsResponse><site id="s"/></tsResponse>`)
+	}))
+	defer server.Close()
+	api := NewAPI(server.URL, API_VERSION, BOUNDARY_STRING, "", true)
+	signedIn := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: signedIn}
+	api.Clock = clock
+	if err := api.Signin("user", "password", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if issued := api.TokenIssuedAt(); !issued.Equal(signedIn) {
+		t.Errorf("got issued at %v, expected %v", issued, signedIn)
+	}
+	steps := []struct {
+		advance time.Duration
+		refused bool
+		expires time.Time
+	}{
+		{0, false, signedIn.Add(30 * time.Minute)},
+		{20 * time.Minute, false, signedIn.Add(50 * time.Minute)},
+		{20 * time.Minute, true, signedIn.Add(50 * time.Minute)},
+	}
+	for i, step := range steps {
+		clock.advance(step.advance)
+		refuse = step.refused
+		if i > 0 {
+			api.QuerySite("s", false)
+		}
+		if expires := api.TokenExpiresAt(); !expires.Equal(step.expires) {
+			t.Errorf("step %d: got expiry %v, expected %v", i, expires, step.expires)
+		}
+	}
+}
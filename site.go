@@ -0,0 +1,299 @@
+package tableau4go
+
+import "io"
+
+// SiteClient is an API bound to one site, so multi-call workflows don't have to
+// thread the site ID through every call. An empty ID means the site the client
+// is signed in to. It shares the underlying API, including its auth token.
+type SiteClient struct {
+	api *API
+	ID  SiteID
+}
+
+func (api *API) Site(siteID SiteID) SiteClient {
+	return SiteClient{api: api, ID: siteID}
+}
+
+func (site SiteClient) API() *API {
+	return site.api
+}
+
+func (site SiteClient) Query(includeStorage bool) (Site, error) {
+	return site.api.QuerySite(site.ID, includeStorage)
+}
+
+func (site SiteClient) QueryUser(userId UserID) (User, error) {
+	return site.api.QueryUserOnSite(site.ID, userId)
+}
+
+func (site SiteClient) QueryProjects() ([]Project, error) {
+	return site.api.QueryProjects(site.ID)
+}
+
+func (site SiteClient) QueryProjectsWithOptions(opts ListOptions) ([]Project, error) {
+	return site.api.QueryProjectsWithOptions(site.ID, opts)
+}
+
+func (site SiteClient) GetProjectByName(name string) (Project, error) {
+	return site.api.GetProjectByName(site.ID, name)
+}
+
+func (site SiteClient) GetProjectByID(ID ProjectID) (Project, error) {
+	return site.api.GetProjectByID(site.ID, ID)
+}
+
+func (site SiteClient) CreateProject(project Project) (*Project, error) {
+	return site.api.CreateProject(site.ID, project)
+}
+
+func (site SiteClient) CreateProjectWithOptions(project Project, opts CreateOptions) (*Project, error) {
+	return site.api.CreateProjectWithOptions(site.ID, project, opts)
+}
+
+func (site SiteClient) EnsureProject(name string, opts EnsureProjectOptions) (Project, error) {
+	return site.api.EnsureProject(site.ID, name, opts)
+}
+
+func (site SiteClient) EnsureProjectPath(path string, opts EnsureProjectOptions) (Project, error) {
+	return site.api.EnsureProjectPath(site.ID, path, opts)
+}
+
+func (site SiteClient) DeleteProject(projectId ProjectID) error {
+	return site.api.DeleteProject(site.ID, projectId)
+}
+
+func (site SiteClient) QueryDatasources() ([]Datasource, error) {
+	return site.api.QueryDatasources(site.ID)
+}
+
+func (site SiteClient) PublishTDS(tdsMetadata Datasource, fullTds string, overwrite bool) (*Datasource, error) {
+	return site.api.PublishTDS(site.ID, tdsMetadata, fullTds, overwrite)
+}
+
+func (site SiteClient) PublishDatasourceFromUpload(metadata Datasource, uploadSessionId string, datasourceType string, overwrite bool) (*Datasource, error) {
+	return site.api.PublishDatasourceFromUpload(site.ID, metadata, uploadSessionId, datasourceType, overwrite)
+}
+
+func (site SiteClient) DeleteDatasource(datasourceId DatasourceID) error {
+	return site.api.DeleteDatasource(site.ID, datasourceId)
+}
+
+func (site SiteClient) UploadFile(path string, opts UploadOptions) (UploadSession, error) {
+	return site.api.UploadFile(site.ID, path, opts)
+}
+
+func (site SiteClient) PublishDatasource(metadata Datasource, content []byte, datasourceType string, overwrite bool) (*Datasource, error) {
+	return site.api.PublishDatasource(site.ID, metadata, content, datasourceType, overwrite)
+}
+
+func (site SiteClient) AppendDatasource(metadata Datasource, content []byte, datasourceType string) (*Datasource, error) {
+	return site.api.AppendDatasource(site.ID, metadata, content, datasourceType)
+}
+
+func (site SiteClient) AppendDatasourceFromUpload(metadata Datasource, uploadSessionId string, datasourceType string) (*Datasource, error) {
+	return site.api.AppendDatasourceFromUpload(site.ID, metadata, uploadSessionId, datasourceType)
+}
+
+func (site SiteClient) UpdateHyperData(datasourceId DatasourceID, requestId string, uploadSessionId string, actions []HyperAction) (Job, error) {
+	return site.api.UpdateHyperData(site.ID, datasourceId, requestId, uploadSessionId, actions)
+}
+
+func (site SiteClient) DownloadDatasource(datasourceId DatasourceID, includeExtract bool, w io.Writer) (string, error) {
+	return site.api.DownloadDatasource(site.ID, datasourceId, includeExtract, w)
+}
+
+func (site SiteClient) UpdateDatasourceNow(datasourceId DatasourceID) (Job, error) {
+	return site.api.UpdateDatasourceNow(site.ID, datasourceId)
+}
+
+func (site SiteClient) QueryWorkbooks() ([]Workbook, error) {
+	return site.api.QueryWorkbooks(site.ID)
+}
+
+func (site SiteClient) QueryWorkbook(workbookId WorkbookID) (Workbook, error) {
+	return site.api.QueryWorkbook(site.ID, workbookId)
+}
+
+func (site SiteClient) PublishWorkbook(metadata Workbook, content []byte, workbookType string, overwrite bool) (*Workbook, error) {
+	return site.api.PublishWorkbook(site.ID, metadata, content, workbookType, overwrite)
+}
+
+func (site SiteClient) PublishWorkbookWithOptions(metadata Workbook, content []byte, workbookType string, opts WorkbookPublishOptions) (*Workbook, *Job, error) {
+	return site.api.PublishWorkbookWithOptions(site.ID, metadata, content, workbookType, opts)
+}
+
+func (site SiteClient) DownloadWorkbook(workbookId WorkbookID, includeExtract bool, w io.Writer) (string, error) {
+	return site.api.DownloadWorkbook(site.ID, workbookId, includeExtract, w)
+}
+
+func (site SiteClient) QueryWorkbookConnections(workbookId WorkbookID) ([]Connection, error) {
+	return site.api.QueryWorkbookConnections(site.ID, workbookId)
+}
+
+func (site SiteClient) WorkbookDependencies(workbookId WorkbookID) (WorkbookDependencies, error) {
+	return site.api.WorkbookDependencies(site.ID, workbookId)
+}
+
+func (site SiteClient) UpdateWorkbookNow(workbookId WorkbookID) (Job, error) {
+	return site.api.UpdateWorkbookNow(site.ID, workbookId)
+}
+
+func (site SiteClient) DeleteWorkbook(workbookId WorkbookID) error {
+	return site.api.DeleteWorkbook(site.ID, workbookId)
+}
+
+func (site SiteClient) QueryGroups(opts ListOptions) ([]Group, Pagination, error) {
+	return site.api.QueryGroups(site.ID, opts)
+}
+
+func (site SiteClient) GetUsersInGroup(groupId GroupID, opts GroupUsersOptions) ([]User, Pagination, error) {
+	return site.api.GetUsersInGroup(site.ID, groupId, opts)
+}
+
+func (site SiteClient) SyncGroupMembership(groupId GroupID, desired []UserID) ([]UserID, []UserID, error) {
+	return site.api.SyncGroupMembership(site.ID, groupId, desired)
+}
+
+func (site SiteClient) CreateExtractForDatasource(datasourceId DatasourceID, encrypt bool) (Job, error) {
+	return site.api.CreateExtractForDatasource(site.ID, datasourceId, encrypt)
+}
+
+func (site SiteClient) DeleteExtractFromDatasource(datasourceId DatasourceID) error {
+	return site.api.DeleteExtractFromDatasource(site.ID, datasourceId)
+}
+
+func (site SiteClient) CreateExtractsForWorkbook(workbookId WorkbookID, encrypt bool, datasourceIds []DatasourceID) (Job, error) {
+	return site.api.CreateExtractsForWorkbook(site.ID, workbookId, encrypt, datasourceIds)
+}
+
+func (site SiteClient) DeleteExtractsFromWorkbook(workbookId WorkbookID, datasourceIds []DatasourceID) error {
+	return site.api.DeleteExtractsFromWorkbook(site.ID, workbookId, datasourceIds)
+}
+
+func (site SiteClient) QueryViews(opts ListOptions) ([]View, Pagination, error) {
+	return site.api.QueryViews(site.ID, opts)
+}
+
+func (site SiteClient) GetViewByPath(path string) (View, error) {
+	return site.api.GetViewByPath(site.ID, path)
+}
+
+func (site SiteClient) DownloadViewImage(viewId ViewID, opts ViewExportOptions, w io.Writer) error {
+	return site.api.DownloadViewImage(site.ID, viewId, opts, w)
+}
+
+func (site SiteClient) DownloadViewPDF(viewId ViewID, opts ViewExportOptions, w io.Writer) error {
+	return site.api.DownloadViewPDF(site.ID, viewId, opts, w)
+}
+
+func (site SiteClient) DownloadViewData(viewId ViewID, opts ViewExportOptions, w io.Writer) error {
+	return site.api.DownloadViewData(site.ID, viewId, opts, w)
+}
+
+func (site SiteClient) QueryFlows(opts ListOptions) ([]Flow, Pagination, error) {
+	return site.api.QueryFlows(site.ID, opts)
+}
+
+func (site SiteClient) ReassignContentOwnership(fromUserId UserID, toUserId UserID, dryRun bool) (OwnershipReport, error) {
+	return site.api.ReassignContentOwnership(site.ID, fromUserId, toUserId, dryRun)
+}
+
+func (site SiteClient) OffboardUser(userId UserID, opts OffboardOptions) (OffboardReport, error) {
+	return site.api.OffboardUser(site.ID, userId, opts)
+}
+
+func (site SiteClient) CertifyDatasources(filter DatasourceFilter, certified bool, note string) ([]Datasource, error) {
+	return site.api.CertifyDatasources(site.ID, filter, certified, note)
+}
+
+func (site SiteClient) CertifiedDatasources() ([]Datasource, error) {
+	return site.api.CertifiedDatasources(site.ID)
+}
+
+func (site SiteClient) QueryDatasource(datasourceId DatasourceID) (Datasource, error) {
+	return site.api.QueryDatasource(site.ID, datasourceId)
+}
+
+func (site SiteClient) GetDatasourceByName(projectId ProjectID, name string) (Datasource, error) {
+	return site.api.GetDatasourceByName(site.ID, projectId, name)
+}
+
+func (site SiteClient) GetWorkbookDowngradeInfo(workbookId WorkbookID, productVersion string) (DowngradeInfo, error) {
+	return site.api.GetWorkbookDowngradeInfo(site.ID, workbookId, productVersion)
+}
+
+func (site SiteClient) TopContentByUsage(n int) ([]WorkbookUsage, error) {
+	return site.api.TopContentByUsage(site.ID, n)
+}
+
+func (site SiteClient) AddTagsToDatasource(datasourceId DatasourceID, tags []string) ([]Tag, error) {
+	return site.api.AddTagsToDatasource(site.ID, datasourceId, tags)
+}
+
+func (site SiteClient) AddTagsToWorkbook(workbookId WorkbookID, tags []string) ([]Tag, error) {
+	return site.api.AddTagsToWorkbook(site.ID, workbookId, tags)
+}
+
+func (site SiteClient) AddTagsToFlow(flowId FlowID, tags []string) ([]Tag, error) {
+	return site.api.AddTagsToFlow(site.ID, flowId, tags)
+}
+
+func (site SiteClient) DeleteTagsFromDatasource(datasourceId DatasourceID, tags []string) error {
+	return site.api.DeleteTagsFromDatasource(site.ID, datasourceId, tags)
+}
+
+func (site SiteClient) DeleteTagsFromWorkbook(workbookId WorkbookID, tags []string) error {
+	return site.api.DeleteTagsFromWorkbook(site.ID, workbookId, tags)
+}
+
+func (site SiteClient) DeleteTagsFromFlow(flowId FlowID, tags []string) error {
+	return site.api.DeleteTagsFromFlow(site.ID, flowId, tags)
+}
+
+func (site SiteClient) FindByTag(tag string) (TaggedContent, error) {
+	return site.api.FindByTag(site.ID, tag)
+}
+
+func (site SiteClient) ListProjectContent(projectId ProjectID, recursive bool) (ProjectContent, error) {
+	return site.api.ListProjectContent(site.ID, projectId, recursive)
+}
+
+func (site SiteClient) DeleteProjectRecursive(projectId ProjectID, opts DeleteProjectOptions) (ProjectContent, error) {
+	return site.api.DeleteProjectRecursive(site.ID, projectId, opts)
+}
+
+func (site SiteClient) TakeInventory(checksums bool) (Inventory, error) {
+	return site.api.TakeInventory(site.ID, checksums)
+}
+
+func (site SiteClient) BackupSite(dir string, opts BackupOptions) (BackupManifest, error) {
+	return site.api.BackupSite(site.ID, dir, opts)
+}
+
+func (site SiteClient) RestoreSite(dir string, opts RestoreOptions) error {
+	return site.api.RestoreSite(site.ID, dir, opts)
+}
+
+func (site SiteClient) AddUser(user User, opts AddUserOptions) (User, error) {
+	return site.api.AddUserToSite(site.ID, user, opts)
+}
+
+func (site SiteClient) AddUsers(users []User, opts AddUserOptions) ([]User, error) {
+	return site.api.AddUsersToSite(site.ID, users, opts)
+}
+
+func (site SiteClient) TakeAccessSnapshot() (AccessSnapshot, error) {
+	return site.api.TakeAccessSnapshot(site.ID)
+}
+
+func (site SiteClient) MoveWorkbookToProject(workbookId WorkbookID, projectId ProjectID) (*Workbook, error) {
+	return site.api.MoveWorkbookToProject(site.ID, workbookId, projectId)
+}
+
+func (site SiteClient) MoveDatasourceToProject(datasourceId DatasourceID, projectId ProjectID) (*Datasource, error) {
+	return site.api.MoveDatasourceToProject(site.ID, datasourceId, projectId)
+}
+
+func (site SiteClient) MoveProjectContents(srcProjectId, dstProjectId ProjectID) error {
+	return site.api.MoveProjectContents(site.ID, srcProjectId, dstProjectId)
+}
@@ -0,0 +1,77 @@
+package tableau4go
+
+import (
+	"strings"
+	"sync"
+)
+
+// how many site lookups ResolveSiteIDs sends at once
+const site_resolve_concurrency = 8
+
+// the error code Tableau answers with when the site in the URL doesn't exist
+const site_not_found_code = "404000"
+
+// ResolveSiteIDs looks up the ids of the named sites, several at once, using
+// and filling the same cache as GetSiteID. Names that can't be resolved are
+// left out of the result and returned as a *MultiError keyed by name.
+func (api *API) ResolveSiteIDs(names []string) (map[string]SiteID, error) {
+	ids := map[string]SiteID{}
+	var mu sync.Mutex
+	result := &MultiError{}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, site_resolve_concurrency)
+	for _, name := range names {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			id, err := api.GetSiteID(name)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				ids[name] = id
+			}
+			result.record(name, err)
+		}(name)
+	}
+	wg.Wait()
+	return ids, result.err()
+}
+
+// ForgetSiteIDs empties the GetSiteID cache, e.g. after sites were deleted
+// and recreated under the same names.
+func (api *API) ForgetSiteIDs() {
+	s := api.lifecycle()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.siteIds = nil
+}
+
+func (s *clientState) cachedSiteID(name string) (SiteID, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.siteIds[name]
+	return id, ok
+}
+
+func (s *clientState) cacheSiteID(name string, id SiteID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.siteIds == nil {
+		s.siteIds = map[string]SiteID{}
+	}
+	s.siteIds[name] = id
+}
+
+// forgetSite drops the cached names of the site whose id appears in
+// requestUrl, once the server has said the site doesn't exist.
+func (s *clientState) forgetSite(requestUrl string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, id := range s.siteIds {
+		if strings.Contains(requestUrl, "/sites/"+string(id)) {
+			delete(s.siteIds, name)
+		}
+	}
+}
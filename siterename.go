@@ -0,0 +1,55 @@
+package tableau4go
+
+import "errors"
+
+var ErrSigninRequired = errors.New("Sign In Again To Continue")
+
+// renaming reports whether update changes the content URL of the site the
+// client is signed in to.
+func (api *API) renaming(update Site) bool {
+	session, _ := api.currentSession()
+	signedIn := len(session.AuthToken) > 0 && (len(update.ID) == 0 || update.ID == session.SiteID)
+	return signedIn && len(update.ContentUrl) > 0 && update.ContentUrl != session.SiteContentUrl
+}
+
+// siteRenamed follows a rename of the signed in site. The server ends the
+// session's hold on the old content URL, so the client signs in again to the
+// new one through its CredentialProvider, whose credentials name the old site
+// and are redirected from now on. Without a provider the rename is recorded
+// and ErrSigninRequired returned; sign in again with the new content URL.
+func (api *API) siteRenamed(from, to string) error {
+	api.lifecycle().renameSite(from, to)
+	g := api.guard()
+	g.mu.Lock()
+	api.SiteContentUrl = to
+	g.mu.Unlock()
+	if api.CredentialProvider == nil {
+		return ErrSigninRequired
+	}
+	return api.SigninWithProvider()
+}
+
+func (s *clientState) renameSite(from, to string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.renamedSites == nil {
+		s.renamedSites = map[string]string{}
+	}
+	for old, current := range s.renamedSites {
+		if current == from {
+			s.renamedSites[old] = to
+		}
+	}
+	s.renamedSites[from] = to
+}
+
+// siteContentUrl returns the current content URL of a site that may have been
+// renamed since credentials naming it were made.
+func (s *clientState) siteContentUrl(contentUrl string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if renamed, ok := s.renamedSites[contentUrl]; ok {
+		return renamed
+	}
+	return contentUrl
+}
@@ -0,0 +1,62 @@
+package tableau4go
+
+// RetargetSubscriptions moves every subscription to the view or workbook from
+// onto its replacement to, keeping subject, schedule, recipient and
+// attachment options, e.g. after a dashboard was republished under a new
+// LUID. Content types are "View" or "Workbook", as the server reports them.
+//
+// By default each subscription is updated in place. With recreate set a copy
+// pointing at the replacement is created and the original deleted, for
+// servers that don't accept a content change in an update; a recreated
+// subscription gets a new ID. It returns the updated or new subscriptions.
+// Failures are returned as a *MultiError keyed by original subscription ID.
+func (api *API) RetargetSubscriptions(siteId SiteID, from SubscriptionContent, to SubscriptionContent, recreate bool) ([]Subscription, error) {
+	subscriptions, err := api.queryAllSubscriptions(siteId, ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	matching := []Subscription{}
+	for _, subscription := range subscriptions {
+		if subscription.Content != nil && subscription.Content.ID == from.ID && subscription.Content.Type == from.Type {
+			matching = append(matching, subscription)
+		}
+	}
+
+	retargeted := []Subscription{}
+	result := &MultiError{}
+	for _, original := range matching {
+		moved := original
+		moved.Content = &SubscriptionContent{ID: to.ID, Type: to.Type}
+		// listings carry schedule and user names, but requests only take IDs
+		if moved.Schedule != nil {
+			moved.Schedule = &Schedule{ID: moved.Schedule.ID}
+		}
+		if moved.User != nil {
+			moved.User = &User{ID: moved.User.ID}
+		}
+		var updated Subscription
+		var err error
+		if recreate {
+			updated, err = api.recreateSubscription(siteId, moved)
+		} else {
+			// the recipient of a subscription can't be changed
+			moved.User = nil
+			updated, err = api.UpdateSubscription(siteId, moved)
+		}
+		result.record(string(original.ID), err)
+		if err == nil {
+			retargeted = append(retargeted, updated)
+		}
+	}
+	return retargeted, result.err()
+}
+
+// recreateSubscription creates the copy before deleting the original, so a
+// failure never leaves the recipient without their subscription
+func (api *API) recreateSubscription(siteId SiteID, subscription Subscription) (Subscription, error) {
+	created, err := api.CreateSubscription(siteId, subscription)
+	if err != nil {
+		return created, err
+	}
+	return created, api.DeleteSubscription(siteId, subscription.ID)
+}
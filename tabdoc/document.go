@@ -0,0 +1,282 @@
+// Package tabdoc reads and rewrites Tableau datasource (.tds) and workbook
+// (.twb) XML before it is published, and builds and unpacks the packaged
+// .tdsx and .twbx forms.
+//
+// Only the connection elements are parsed. Everything else in the document is
+// kept byte for byte, so a rewritten file differs from the original only in
+// the attributes that were changed.
+package tabdoc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+var ErrNotTableauDocument = errors.New("Not A Tableau Datasource Or Workbook")
+
+const (
+	connection_element       = "connection"
+	named_connection_element = "named-connection"
+	federated_class          = "federated"
+	hyper_class              = "hyper"
+	dataengine_class         = "dataengine"
+	sqlproxy_class           = "sqlproxy"
+	password_attribute       = "password"
+)
+
+// Document is a parsed .tds or .twb file.
+type Document struct {
+	data        []byte
+	connections []*Connection
+}
+
+// Connection is one connection element. Inside a federated connection each
+// underlying database connection is wrapped in a named-connection, whose name
+// is given in NamedConnection.
+type Connection struct {
+	NamedConnection string
+	start, end      int
+	name            xml.Name
+	selfClosing     bool
+	attrs           []xml.Attr
+	modified        bool
+}
+
+// Parse reads a .tds or .twb document.
+func Parse(data []byte) (*Document, error) {
+	doc := &Document{data: data}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	namedConnection := ""
+	rootSeen := false
+	for {
+		start := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !rootSeen {
+				rootSeen = true
+				if t.Name.Local != "datasource" && t.Name.Local != "workbook" {
+					return nil, ErrNotTableauDocument
+				}
+			}
+			switch t.Name.Local {
+			case named_connection_element:
+				namedConnection = attr(t.Attr, "name")
+			case connection_element:
+				end := int(decoder.InputOffset())
+				doc.connections = append(doc.connections, &Connection{
+					NamedConnection: namedConnection,
+					start:           start,
+					end:             end,
+					name:            t.Name,
+					selfClosing:     bytes.HasSuffix(data[start:end], []byte("/>")),
+					attrs:           t.Copy().Attr,
+				})
+			}
+		case xml.EndElement:
+			if t.Name.Local == named_connection_element {
+				namedConnection = ""
+			}
+		}
+	}
+	if !rootSeen {
+		return nil, ErrNotTableauDocument
+	}
+	return doc, nil
+}
+
+// Connections returns every connection in the document, in document order,
+// including those nested inside federated connections.
+func (doc *Document) Connections() []*Connection {
+	return doc.connections
+}
+
+// Bytes returns the document with all connection changes applied.
+func (doc *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	last := 0
+	for _, c := range doc.connections {
+		if !c.modified {
+			continue
+		}
+		buf.Write(doc.data[last:c.start])
+		c.writeTag(&buf)
+		last = c.end
+	}
+	buf.Write(doc.data[last:])
+	return buf.Bytes()
+}
+
+// ConnectionTarget is where RepointConnections sends matching connections.
+// Empty fields are left as they are.
+type ConnectionTarget struct {
+	Server   string
+	Port     string
+	DBName   string
+	Username string
+}
+
+// RepointConnections applies target to every database connection for which
+// match returns true. Federated wrapper connections are skipped since their
+// server details live on the named connections inside them. If match is nil
+// every database connection is changed, but not extract connections or
+// connections to published datasources, which a match has to select
+// explicitly. It returns the number of connections changed.
+func (doc *Document) RepointConnections(match func(*Connection) bool, target ConnectionTarget) int {
+	changed := 0
+	for _, c := range doc.connections {
+		if c.Class() == federated_class {
+			continue
+		}
+		if match == nil && (c.IsExtract() || c.IsPublishedDatasource()) {
+			continue
+		}
+		if match != nil && !match(c) {
+			continue
+		}
+		if len(target.Server) > 0 {
+			c.SetAttr("server", target.Server)
+		}
+		if len(target.Port) > 0 {
+			c.SetAttr("port", target.Port)
+		}
+		if len(target.DBName) > 0 {
+			c.SetAttr("dbname", target.DBName)
+		}
+		if len(target.Username) > 0 {
+			c.SetAttr("username", target.Username)
+		}
+		changed++
+	}
+	return changed
+}
+
+// SanitizeCredentials removes embedded passwords from every connection, so the
+// document can be stored or published without them. It returns the number of
+// connections that had a password.
+func (doc *Document) SanitizeCredentials() int {
+	sanitized := 0
+	for _, c := range doc.connections {
+		if c.RemoveAttr(password_attribute) {
+			sanitized++
+		}
+	}
+	return sanitized
+}
+
+func (c *Connection) Class() string {
+	return c.Attr("class")
+}
+
+// IsExtract reports whether the connection is to an extract stored with the
+// document rather than to a database.
+func (c *Connection) IsExtract() bool {
+	return c.Class() == hyper_class || c.Class() == dataengine_class
+}
+
+// IsPublishedDatasource reports whether the connection is to a datasource
+// published on Tableau Server, whose server is the Tableau Server itself.
+func (c *Connection) IsPublishedDatasource() bool {
+	return c.Class() == sqlproxy_class
+}
+
+func (c *Connection) Server() string {
+	return c.Attr("server")
+}
+
+func (c *Connection) Port() string {
+	return c.Attr("port")
+}
+
+func (c *Connection) DBName() string {
+	return c.Attr("dbname")
+}
+
+func (c *Connection) Username() string {
+	return c.Attr("username")
+}
+
+// Attr returns the value of the named attribute, or "" if it is not set.
+func (c *Connection) Attr(name string) string {
+	return attr(c.attrs, name)
+}
+
+// SetAttr sets the named attribute, adding it if it is not already present.
+func (c *Connection) SetAttr(name, value string) {
+	c.modified = true
+	for i := range c.attrs {
+		if qualifiedName(c.attrs[i].Name) == name {
+			c.attrs[i].Value = value
+			return
+		}
+	}
+	c.attrs = append(c.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// RemoveAttr removes the named attribute and reports whether it was present.
+func (c *Connection) RemoveAttr(name string) bool {
+	for i := range c.attrs {
+		if qualifiedName(c.attrs[i].Name) == name {
+			c.attrs = append(c.attrs[:i], c.attrs[i+1:]...)
+			c.modified = true
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Connection) writeTag(buf *bytes.Buffer) {
+	buf.WriteString("<" + qualifiedName(c.name))
+	for _, a := range c.attrs {
+		buf.WriteString(" " + qualifiedName(a.Name) + "='")
+		buf.WriteString(escapeAttr(a.Value))
+		buf.WriteString("'")
+	}
+	if c.selfClosing {
+		buf.WriteString(" />")
+	} else {
+		buf.WriteString(">")
+	}
+}
+
+func attr(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if qualifiedName(a.Name) == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// RawToken leaves the prefix of a qualified name in Space
+func qualifiedName(name xml.Name) string {
+	if len(name.Space) > 0 {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
+// Tableau quotes attributes with single quotes and escapes newlines in them
+var attrEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"'", "&apos;",
+	"\"", "&quot;",
+	"\n", "&#10;",
+	"\r", "&#13;",
+	"\t", "&#9;",
+)
+
+func escapeAttr(value string) string {
+	return attrEscaper.Replace(value)
+}
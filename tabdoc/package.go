@@ -0,0 +1,229 @@
+package tabdoc
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoDocumentInPackage = errors.New("No Datasource Or Workbook In Package")
+
+// directory Tableau Desktop puts extracts in inside a .twbx/.tdsx
+const EXTRACTS_DIR = "Data/Extracts"
+
+// PackageFile is a file on disk to include in a packaged workbook or
+// datasource. Name is its path inside the package; extracts normally go in
+// EXTRACTS_DIR, e.g. "Data/Extracts/sales.hyper".
+type PackageFile struct {
+	Name string
+	Path string
+}
+
+// WritePackage writes a .twbx or .tdsx to w. documentName is the name of the
+// .twb or .tds at the root of the package and document its XML. Files are
+// streamed from disk, so large extracts are never held in memory.
+func WritePackage(w io.Writer, documentName string, document []byte, files ...PackageFile) error {
+	if !isDocument(documentName) || strings.Contains(documentName, "/") {
+		return fmt.Errorf("Invalid Document Name '%s'", documentName)
+	}
+	archive := zip.NewWriter(w)
+	entry, err := archive.Create(documentName)
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(document); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := addPackageFile(archive, file); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func addPackageFile(archive *zip.Writer, file PackageFile) error {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	header := &zip.FileHeader{Name: path.Clean(filepath.ToSlash(file.Name)), Method: zip.Deflate}
+	switch strings.ToLower(path.Ext(header.Name)) {
+	case ".hyper", ".tde":
+		// extracts are already compressed, deflating them only costs time
+		header.Method = zip.Store
+	}
+	if info, err := f.Stat(); err == nil {
+		header.Modified = info.ModTime()
+	}
+	entry, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, f)
+	return err
+}
+
+// ReadPackageDocument returns the name and XML of the .twb or .tds inside the
+// .twbx or .tdsx at path.
+func ReadPackageDocument(packagePath string) (string, []byte, error) {
+	archive, err := zip.OpenReader(packagePath)
+	if err != nil {
+		return "", nil, err
+	}
+	defer archive.Close()
+	return packageDocument(archive.File)
+}
+
+// PackageDocument is ReadPackageDocument for a package held in memory.
+func PackageDocument(data []byte) (string, []byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", nil, err
+	}
+	return packageDocument(archive.File)
+}
+
+func packageDocument(files []*zip.File) (string, []byte, error) {
+	for _, file := range files {
+		if strings.Contains(file.Name, "/") || !isDocument(file.Name) {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		defer r.Close()
+		document, err := io.ReadAll(r)
+		return file.Name, document, err
+	}
+	return "", nil, ErrNoDocumentInPackage
+}
+
+// Unpack extracts every file in the .twbx or .tdsx at packagePath into dir and
+// returns their paths, the document first.
+func Unpack(packagePath, dir string) ([]string, error) {
+	archive, err := zip.OpenReader(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	var paths []string
+	for _, file := range archive.File {
+		if strings.HasSuffix(file.Name, "/") {
+			continue
+		}
+		name, err := entryName(file)
+		if err != nil {
+			return paths, err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := unpackFile(file, target); err != nil {
+			return paths, err
+		}
+		if !strings.Contains(name, "/") && isDocument(name) {
+			paths = append([]string{target}, paths...)
+		} else {
+			paths = append(paths, target)
+		}
+	}
+	return paths, nil
+}
+
+// entryName returns the cleaned name of a package entry, refusing names such
+// as "../x" or "/x" that would land outside the directory the package is
+// unpacked in.
+func entryName(file *zip.File) (string, error) {
+	name := path.Clean(file.Name)
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("Invalid Package Entry '%s'", file.Name)
+	}
+	return name, nil
+}
+
+func unpackFile(file *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func isDocument(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".twb", ".tds":
+		return true
+	}
+	return false
+}
+
+// RewritePackage copies the .twbx or .tdsx in data to w, passing the XML of
+// its .twb or .tds through rewrite and keeping every other file as is.
+// Packages with entries Unpack would refuse are refused too, so a rewritten
+// package is no more dangerous to unpack than the original.
+func RewritePackage(data []byte, w io.Writer, rewrite func(document []byte) ([]byte, error)) error {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, file := range archive.File {
+		if _, err := entryName(file); err != nil {
+			return err
+		}
+	}
+	out := zip.NewWriter(w)
+	rewritten := false
+	for _, file := range archive.File {
+		if rewritten || strings.Contains(file.Name, "/") || !isDocument(file.Name) {
+			// copy without recompressing
+			if err := out.Copy(file); err != nil {
+				return err
+			}
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return err
+		}
+		document, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+		if document, err = rewrite(document); err != nil {
+			return err
+		}
+		header := file.FileHeader
+		entry, err := out.CreateHeader(&header)
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(document); err != nil {
+			return err
+		}
+		rewritten = true
+	}
+	if !rewritten {
+		return ErrNoDocumentInPackage
+	}
+	return out.Close()
+}
@@ -0,0 +1,143 @@
+package tabdoc
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type entry struct {
+	name    string
+	content string
+}
+
+func zipOf(t *testing.T, entries ...entry) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := archive.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, e.content)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func entriesOf(t *testing.T, data []byte) []entry {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []entry{}
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry{file.Name, string(content)})
+	}
+	return entries
+}
+
+func TestRewritePackage(t *testing.T) {
+	upper := func(document []byte) ([]byte, error) {
+		return bytes.ToUpper(document), nil
+	}
+	tests := []struct {
+		name     string
+		entries  []entry
+		expected []entry
+		err      string
+	}{
+		{
+			name:     "rewrites the root document only",
+			entries:  []entry{{"sales.twb", "<workbook/>"}, {"Data/Extracts/sales.hyper", "data"}, {"Data/other.twb", "<nested/>"}},
+			expected: []entry{{"sales.twb", "<WORKBOOK/>"}, {"Data/Extracts/sales.hyper", "data"}, {"Data/other.twb", "<nested/>"}},
+		},
+		{
+			name:    "refuses entries outside the package",
+			entries: []entry{{"sales.twb", "<workbook/>"}, {"../../.bashrc", "evil"}},
+			err:     "Invalid Package Entry",
+		},
+		{
+			name:    "refuses entries that climb out of a directory",
+			entries: []entry{{"sales.twb", "<workbook/>"}, {"Data/../../evil", "evil"}},
+			err:     "Invalid Package Entry",
+		},
+		{
+			name:    "refuses absolute entries",
+			entries: []entry{{"sales.twb", "<workbook/>"}, {"/etc/passwd", "evil"}},
+			err:     "Invalid Package Entry",
+		},
+		{
+			name:    "no document",
+			entries: []entry{{"Data/Extracts/sales.hyper", "data"}},
+			err:     ErrNoDocumentInPackage.Error(),
+		},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		err := RewritePackage(zipOf(t, test.entries...), &out, upper)
+		if len(test.err) > 0 {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: got error %v, expected %q", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got := entriesOf(t, out.Bytes())
+		if len(got) != len(test.expected) {
+			t.Errorf("%s: got %v, expected %v", test.name, got, test.expected)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.expected[i] {
+				t.Errorf("%s: got %v, expected %v", test.name, got[i], test.expected[i])
+			}
+		}
+	}
+}
+
+func TestUnpackRefusesEntriesOutsideDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		failed  bool
+	}{
+		{"document and extract", []entry{{"sales.twb", "<workbook/>"}, {"Data/Extracts/sales.hyper", "data"}}, false},
+		{"parent directory", []entry{{"sales.twb", "<workbook/>"}, {"../evil", "evil"}}, true},
+		{"absolute", []entry{{"/evil", "evil"}}, true},
+	}
+	for _, test := range tests {
+		root := t.TempDir()
+		packagePath := filepath.Join(root, "package.twbx")
+		if err := os.WriteFile(packagePath, zipOf(t, test.entries...), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dir := filepath.Join(root, "out")
+		_, err := Unpack(packagePath, dir)
+		if (err != nil) != test.failed {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+		if _, statErr := os.Stat(filepath.Join(root, "evil")); !errors.Is(statErr, os.ErrNotExist) {
+			t.Errorf("%s: wrote outside the target directory", test.name)
+		}
+	}
+}
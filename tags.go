@@ -0,0 +1,54 @@
+package tableau4go
+
+import "sync"
+
+// TaggedContent is everything on a site carrying a tag.
+type TaggedContent struct {
+	Workbooks   []Workbook   `json:"workbooks"`
+	Views       []View       `json:"views"`
+	Datasources []Datasource `json:"datasources"`
+	Flows       []Flow       `json:"flows"`
+}
+
+// FindByTag lists the workbooks, views, datasources and flows tagged with tag,
+// querying each content type at the same time with a server side filter.
+// Flows are skipped on servers older than API 3.3. If some queries fail the
+// others' results are still returned, with the failures as a *MultiError
+// keyed by content type.
+func (api *API) FindByTag(siteId SiteID, tag string) (TaggedContent, error) {
+	found := TaggedContent{}
+	opts := ListOptions{Filter: "tags:eq:" + FilterValue(tag)}
+	result := &MultiError{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	run := func(contentType string, query func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := query()
+			mu.Lock()
+			defer mu.Unlock()
+			result.record(contentType, err)
+		}()
+	}
+	run(CONTENT_TYPE_WORKBOOK, func() (err error) {
+		found.Workbooks, err = api.queryAllWorkbooks(siteId, opts)
+		return err
+	})
+	run(CONTENT_TYPE_VIEW, func() (err error) {
+		found.Views, err = api.queryAllViews(siteId, opts)
+		return err
+	})
+	run(CONTENT_TYPE_DATASOURCE, func() (err error) {
+		found.Datasources, err = api.queryAllDatasources(siteId, opts)
+		return err
+	})
+	if api.Supports("QueryFlows") {
+		run(CONTENT_TYPE_FLOW, func() (err error) {
+			found.Flows, err = api.queryAllFlows(siteId, opts)
+			return err
+		})
+	}
+	wg.Wait()
+	return found, result.err()
+}
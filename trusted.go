@@ -0,0 +1,69 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const form_content_type = "application/x-www-form-urlencoded"
+
+// the trusted endpoint answers "-1" rather than an error status when the
+// server is not configured to trust the caller or the user is unknown
+const trusted_ticket_denied = "-1"
+
+var ErrTrustedTicketDenied = errors.New("Trusted Ticket Request Denied")
+
+//https://help.tableau.com/current/server/en-us/trusted_auth_webrequ.htm
+func (api *API) GetTrustedTicket(username, siteContentUrl, clientIP string) (string, error) {
+	if err := api.requireVersion("GetTrustedTicket"); err != nil {
+		return "", err
+	}
+	requestUrl := fmt.Sprintf("%s/trusted", api.Server)
+	form := url.Values{}
+	form.Set("username", username)
+	if len(siteContentUrl) > 0 && !api.isDefaultSite(siteContentUrl) {
+		form.Set("target_site", siteContentUrl)
+	}
+	if len(clientIP) > 0 {
+		form.Set("client_ip", clientIP)
+	}
+	headers := make(map[string]string)
+	headers[content_type_header] = form_content_type
+	// sent like any other call, but never as a dry run: the ticket is the point
+	resp, err := api.send(api.context(), POST, requestUrl, strings.NewReader(form.Encode()), headers)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("Trusted Ticket Request Failed: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	ticket := strings.TrimSpace(string(resp.Body))
+	if ticket == trusted_ticket_denied || len(ticket) == 0 {
+		return "", ErrTrustedTicketDenied
+	}
+	return ticket, nil
+}
+
+// TrustedEmbedURL forms the URL that redeems ticket and displays the view at
+// viewPath ("WorkbookName/SheetName") embedded on the given site. Each part
+// of viewPath is escaped, so names may hold spaces, '?' or '#'.
+func (api *API) TrustedEmbedURL(ticket, siteContentUrl, viewPath string) string {
+	sitePath := ""
+	if len(siteContentUrl) > 0 && !api.isDefaultSite(siteContentUrl) {
+		sitePath = fmt.Sprintf("/t/%s", url.PathEscape(siteContentUrl))
+	}
+	segments := strings.Split(strings.Trim(viewPath, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("%s/trusted/%s%s/views/%s?:embed=yes", api.Server, url.PathEscape(ticket), sitePath, strings.Join(segments, "/"))
+}
+
+// the default site has no contentUrl of its own, but callers commonly refer to
+// it by the configured DefaultSiteName
+func (api *API) isDefaultSite(contentUrl string) bool {
+	return api.OmitDefaultSiteName && contentUrl == api.DefaultSiteName
+}
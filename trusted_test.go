@@ -0,0 +1,24 @@
+package tableau4go
+
+import "testing"
+
+func TestTrustedEmbedURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		ticket   string
+		site     string
+		viewPath string
+		expected string
+	}{
+		{"default site", "abc", "", "Sales/Overview", "https://tableau.example.com/trusted/abc/views/Sales/Overview?:embed=yes"},
+		{"named site", "abc", "marketing", "/Sales/Overview/", "https://tableau.example.com/trusted/abc/t/marketing/views/Sales/Overview?:embed=yes"},
+		{"spaces", "abc", "", "Sales Report/Q1 Overview", "https://tableau.example.com/trusted/abc/views/Sales%20Report/Q1%20Overview?:embed=yes"},
+		{"query and fragment characters", "abc", "", "What?/Top #10", "https://tableau.example.com/trusted/abc/views/What%3F/Top%20%2310?:embed=yes"},
+	}
+	api := NewAPI("https://tableau.example.com", API_VERSION, BOUNDARY_STRING, "", true)
+	for _, test := range tests {
+		if got := api.TrustedEmbedURL(test.ticket, test.site, test.viewPath); got != test.expected {
+			t.Errorf("%s: got %s, expected %s", test.name, got, test.expected)
+		}
+	}
+}
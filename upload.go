@@ -0,0 +1,210 @@
+package tableau4go
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Tableau recommends chunks of no more than 64MB; smaller chunks lose less
+// work when a connection drops mid-upload.
+const DEFAULT_CHUNK_SIZE = 5 * 1024 * 1024
+
+var ErrUploadFileChanged = errors.New("Upload File Changed Since Session Was Created")
+
+// UploadSession records how far a chunked file upload has progressed. It can be
+// persisted with Save and restored with LoadUploadSession so an interrupted
+// upload resumes from the last committed chunk instead of restarting.
+//
+// Offset only advances once the server has acknowledged a chunk. If a chunk
+// reached the server but the acknowledgement was lost, resuming will send it
+// again, so callers that cannot tolerate that should restart the upload.
+// Tableau also expires idle upload sessions; resuming an expired session
+// returns ErrDoesNotExist.
+type UploadSession struct {
+	SiteID          SiteID `json:"siteId"`
+	UploadSessionID string `json:"uploadSessionId"`
+	Path            string `json:"path"`
+	Size            int64  `json:"size"`
+	Offset          int64  `json:"offset"`
+	ChunkSize       int64  `json:"chunkSize"`
+}
+
+func (s UploadSession) Complete() bool {
+	return s.Offset >= s.Size
+}
+
+func (s UploadSession) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
+
+func LoadUploadSession(r io.Reader) (UploadSession, error) {
+	session := UploadSession{}
+	err := json.NewDecoder(r).Decode(&session)
+	return session, err
+}
+
+// CheckpointToFile returns an UploadOptions.Checkpoint function that persists
+// the session to path after every committed chunk. The file is replaced
+// atomically so a crash never leaves a truncated session behind.
+func CheckpointToFile(path string) func(UploadSession) error {
+	return func(session UploadSession) error {
+		tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+		if err != nil {
+			return err
+		}
+		if err := session.Save(tmp); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+		return os.Rename(tmp.Name(), path)
+	}
+}
+
+// UploadOptions tunes how UploadFile and ResumeUpload send a file.
+//
+// ChunkSize is the number of bytes sent per append; it only applies to new
+// sessions, resumed sessions keep the chunk size they were started with.
+// ReadAhead is how many chunks are read and held in memory ahead of the one
+// being sent, 1 when not set. Chunks are still sent one at a time: Tableau
+// appends them to an upload session in the order they arrive, so parallel
+// appends would corrupt the file. Reading ahead overlaps disk reads with the
+// network instead. Memory use is roughly ChunkSize * (ReadAhead + 1).
+//
+// Checkpoint, if not nil, is called with the session once the upload has been
+// initiated and again after every committed chunk. Progress, if not nil, is
+// told about every committed chunk too.
+type UploadOptions struct {
+	ChunkSize  int64
+	ReadAhead  int
+	Checkpoint func(UploadSession) error
+	Progress   ProgressReporter
+}
+
+func DefaultUploadOptions() UploadOptions {
+	return UploadOptions{ChunkSize: DEFAULT_CHUNK_SIZE, ReadAhead: 1}
+}
+
+// UploadFile starts a chunked upload of the file at path and sends it to the
+// server. With UseDeploymentLimits, files over the Deployment's
+// MaxPublishSize fail with a BodyTooLargeError before anything is sent.
+func (api *API) UploadFile(siteId SiteID, path string, opts UploadOptions) (UploadSession, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return UploadSession{}, err
+	}
+	if limit := api.Deployment.Limits().MaxPublishSize; api.UseDeploymentLimits && limit > 0 && info.Size() > limit {
+		return UploadSession{}, &BodyTooLargeError{Limit: limit, Size: info.Size()}
+	}
+	if len(siteId) == 0 {
+		// the session must keep working if it is resumed after a sign in to another site
+		siteId = api.CurrentSite().ID
+	}
+	fileUpload, err := api.InitiateFileUpload(siteId)
+	if err != nil {
+		return UploadSession{}, err
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DEFAULT_CHUNK_SIZE
+	}
+	session := UploadSession{
+		SiteID:          siteId,
+		UploadSessionID: fileUpload.UploadSessionID,
+		Path:            path,
+		Size:            info.Size(),
+		ChunkSize:       chunkSize,
+	}
+	if opts.Checkpoint != nil {
+		if err := opts.Checkpoint(session); err != nil {
+			return session, err
+		}
+	}
+	return api.ResumeUpload(session, opts)
+}
+
+type uploadChunk struct {
+	data []byte
+	err  error
+}
+
+// ResumeUpload sends the remainder of the file described by session, starting
+// at session.Offset. The returned session reflects the progress made, even
+// when an error is returned, so it can be resumed again later.
+func (api *API) ResumeUpload(session UploadSession, opts UploadOptions) (UploadSession, error) {
+	f, err := os.Open(session.Path)
+	if err != nil {
+		return session, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return session, err
+	}
+	if info.Size() != session.Size {
+		return session, ErrUploadFileChanged
+	}
+	if _, err := f.Seek(session.Offset, io.SeekStart); err != nil {
+		return session, err
+	}
+	chunkSize := session.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DEFAULT_CHUNK_SIZE
+	}
+	readAhead := opts.ReadAhead
+	if readAhead < 1 {
+		readAhead = 1
+	}
+	chunks := make(chan uploadChunk, readAhead)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(chunks)
+		for offset := session.Offset; offset < session.Size; {
+			buf := make([]byte, chunkSize)
+			n, err := io.ReadFull(f, buf)
+			if err == io.ErrUnexpectedEOF {
+				err = nil
+			}
+			select {
+			case chunks <- uploadChunk{data: buf[:n], err: err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+			offset += int64(n)
+		}
+	}()
+	for chunk := range chunks {
+		if chunk.err != nil {
+			return session, chunk.err
+		}
+		_, err := api.AppendToFileUpload(session.SiteID, session.UploadSessionID, chunk.data)
+		if err != nil {
+			return session, err
+		}
+		session.Offset += int64(len(chunk.data))
+		reportProgress(opts.Progress, PublishProgress{
+			Stage:      ProgressUpload,
+			BytesSent:  session.Offset,
+			TotalBytes: session.Size,
+			Chunk:      int((session.Offset + chunkSize - 1) / chunkSize),
+			Chunks:     int((session.Size + chunkSize - 1) / chunkSize),
+		})
+		if opts.Checkpoint != nil {
+			if err := opts.Checkpoint(session); err != nil {
+				return session, err
+			}
+		}
+	}
+	return session, nil
+}
@@ -0,0 +1,60 @@
+package tableau4go
+
+import "sort"
+
+// WorkbookUsage is the view count of a workbook, summed over its views.
+type WorkbookUsage struct {
+	Workbook       Workbook `json:"workbook"`
+	Views          int      `json:"views"`
+	TotalViewCount int      `json:"totalViewCount"`
+}
+
+// TopContentByUsage returns the n most viewed workbooks on the site, most
+// viewed first. With n 0 every workbook is returned, so the least used, the
+// candidates for cleanup, are at the end.
+func (api *API) TopContentByUsage(siteId SiteID, n int) ([]WorkbookUsage, error) {
+	usage := map[WorkbookID]*WorkbookUsage{}
+	views, err := api.queryAllViews(siteId, ListOptions{IncludeUsageStatistics: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range views {
+		if view.Workbook == nil {
+			continue
+		}
+		wu, ok := usage[view.Workbook.ID]
+		if !ok {
+			wu = &WorkbookUsage{Workbook: Workbook{ID: view.Workbook.ID}}
+			usage[view.Workbook.ID] = wu
+		}
+		wu.Views++
+		if view.Usage != nil {
+			wu.TotalViewCount += view.Usage.TotalViewCount
+		}
+	}
+	workbooks, err := api.QueryWorkbooks(siteId)
+	if err != nil {
+		return nil, err
+	}
+	for _, workbook := range workbooks {
+		if wu, ok := usage[workbook.ID]; ok {
+			wu.Workbook = workbook
+		} else {
+			usage[workbook.ID] = &WorkbookUsage{Workbook: workbook}
+		}
+	}
+	top := make([]WorkbookUsage, 0, len(usage))
+	for _, wu := range usage {
+		top = append(top, *wu)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].TotalViewCount != top[j].TotalViewCount {
+			return top[i].TotalViewCount > top[j].TotalViewCount
+		}
+		return top[i].Workbook.Name < top[j].Workbook.Name
+	})
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top, nil
+}
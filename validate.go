@@ -0,0 +1,183 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidModel = errors.New("Invalid Request Model")
+
+// FieldError is one field of a model that failed validation. Err is set when
+// a more specific error explains it, e.g. a *ContentUrlError.
+type FieldError struct {
+	Field  string
+	Reason string
+	Err    error
+}
+
+// ValidationError lists every field of a model that would be rejected,
+// checked before the request is sent since Tableau's 400 responses rarely say
+// which field was wrong. It matches ErrInvalidModel and the Err of each field.
+type ValidationError struct {
+	Model  string
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		reasons = append(reasons, field.Field+" "+field.Reason)
+	}
+	return fmt.Sprintf("Invalid %s: %s", e.Model, strings.Join(reasons, "; "))
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := []error{ErrInvalidModel}
+	for _, field := range e.Fields {
+		if field.Err != nil {
+			errs = append(errs, field.Err)
+		}
+	}
+	return errs
+}
+
+// validator collects the problems with one model
+type validator struct {
+	model  string
+	fields []FieldError
+}
+
+func (v *validator) fail(field, reason string, args ...interface{}) {
+	v.fields = append(v.fields, FieldError{Field: field, Reason: fmt.Sprintf(reason, args...)})
+}
+
+func (v *validator) require(field, value string) {
+	if len(strings.TrimSpace(value)) == 0 {
+		v.fail(field, "is required")
+	}
+}
+
+// oneOf checks an optional enum field, listing the values it may take
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	if len(value) == 0 {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.fail(field, "'%s' must be one of %s", value, strings.Join(allowed, ", "))
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Model: v.model, Fields: v.fields}
+}
+
+// Validate checks the site can be created: it needs a name and a valid
+// content URL, and its enum and quota fields must hold values the server
+// accepts. UpdateSite only checks the fields that are set.
+func (s Site) Validate() error {
+	return s.validate(true)
+}
+
+func (s Site) validate(create bool) error {
+	v := &validator{model: "Site"}
+	if create {
+		v.require("Name", s.Name)
+	}
+	if create || len(s.ContentUrl) > 0 {
+		var cuErr *ContentUrlError
+		if err := ValidateContentUrl(s.ContentUrl); errors.As(err, &cuErr) {
+			v.fields = append(v.fields, FieldError{Field: "ContentUrl", Reason: cuErr.Reason, Err: cuErr})
+		}
+	}
+	v.oneOf("AdminMode", s.AdminMode, "ContentAndUsers", "ContentOnly")
+	v.oneOf("State", s.State, "Active", "Suspended")
+	if len(s.UserQuota) > 0 {
+		if quota, err := strconv.Atoi(s.UserQuota); err != nil || quota < 0 {
+			v.fail("UserQuota", "'%s' must be a whole number of users", s.UserQuota)
+		}
+	}
+	if s.StorageQuota < 0 {
+		v.fail("StorageQuota", "%d must not be negative", s.StorageQuota)
+	}
+	return v.err()
+}
+
+// Validate checks the project can be created: it needs a name, a valid
+// ContentPermissions if one is set, and can't be its own parent.
+// UpdateProject only checks the fields that are set.
+func (p Project) Validate() error {
+	return p.validate(true)
+}
+
+func (p Project) validate(create bool) error {
+	v := &validator{model: "Project"}
+	if create {
+		v.require("Name", p.Name)
+	}
+	v.oneOf("ContentPermissions", string(p.ContentPermissions), string(ContentPermissionsLockedToProject), string(ContentPermissionsLockedToProjectWithoutNested), string(ContentPermissionsManagedByOwner))
+	if len(p.ID) > 0 && p.ParentProjectID == p.ID {
+		v.fail("ParentProjectID", "is the project itself")
+	}
+	return v.err()
+}
+
+// Validate checks the datasource can be published: it needs a name and the
+// project to publish to, and embedded credentials need a user name.
+// UpdateDatasource only checks the fields that are set.
+func (d Datasource) Validate() error {
+	return d.validate(true)
+}
+
+func (d Datasource) validate(create bool) error {
+	v := &validator{model: "Datasource"}
+	if create {
+		v.require("Name", d.Name)
+		if d.Project == nil || len(d.Project.ID) == 0 {
+			v.fail("Project.ID", "is required")
+		}
+	}
+	if c := d.ConnectionCredentials; c != nil && len(c.Password) > 0 && len(c.Name) == 0 {
+		v.fail("ConnectionCredentials.Name", "is required with a password")
+	}
+	return v.err()
+}
+
+// Validate checks the user can be added to a site: it needs a name and a
+// valid site role, and the email and auth setting must be valid if set.
+// UpdateUser only checks the fields that are set.
+func (u User) Validate() error {
+	return u.validate(true)
+}
+
+func (u User) validate(create bool) error {
+	v := &validator{model: "User"}
+	if create {
+		v.require("Name", u.Name)
+		v.require("SiteRole", string(u.SiteRole))
+	}
+	if len(u.SiteRole) > 0 && u.SiteRole.Validate() != nil {
+		roles := make([]string, 0, len(siteRoles))
+		for role := range siteRoles {
+			roles = append(roles, string(role))
+		}
+		sort.Strings(roles)
+		v.oneOf("SiteRole", string(u.SiteRole), roles...)
+	}
+	v.oneOf("AuthSetting", u.AuthSetting, "ServerDefault", "SAML", "OpenID", "TableauIDWithMFA")
+	if len(u.Email) > 0 {
+		if address, err := mail.ParseAddress(u.Email); err != nil || address.Address != u.Email {
+			v.fail("Email", "'%s' is not an email address", u.Email)
+		}
+	}
+	return v.err()
+}
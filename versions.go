@@ -0,0 +1,130 @@
+package tableau4go
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrUnsupportedVersion = errors.New("Unsupported API Version")
+
+// minimumVersions lists the REST API version each wrapped endpoint first
+// appeared in, for those newer than API_VERSION. Older servers answer calls
+// to them with a bare 404, so they are checked before the request is sent.
+var minimumVersions = map[string]string{
+	"DownloadViewImage":                 "2.5",
+	"UpdateDatasourceNow":               "2.8",
+	"UpdateWorkbookNow":                 "2.8",
+	"AddDatasourceToSchedule":           "2.8",
+	"AddWorkbookToSchedule":             "2.8",
+	"DownloadViewPDF":                   "2.8",
+	"DownloadViewData":                  "2.8",
+	"QueryJobs":                         "3.1",
+	"CancelJob":                         "3.1",
+	"QueryDataAlerts":                   "3.2",
+	"DeleteDataAlert":                   "3.2",
+	"QueryFlows":                        "3.3",
+	"UpdateFlow":                        "3.3",
+	"DownloadFlow":                      "3.3",
+	"DeleteFlow":                        "3.3",
+	"CreateExtractForDatasource":        "3.5",
+	"DeleteExtractFromDatasource":       "3.5",
+	"CreateExtractsForWorkbook":         "3.5",
+	"DeleteExtractsFromWorkbook":        "3.5",
+	"CreateWebhook":                     "3.6",
+	"QueryWebhooks":                     "3.6",
+	"DeleteWebhook":                     "3.6",
+	"QueryGroupsForUser":                "3.7",
+	"OrderFavorites":                    "3.8",
+	"AddTagsToFlow":                     "3.9",
+	"DeleteTagFromFlow":                 "3.9",
+	"UpdateHyperData":                   "3.12",
+	"QueryEmbeddingSettings":            "3.16",
+	"UpdateEmbeddingSettings":           "3.16",
+	"QueryServerMobileSecuritySettings": "3.19",
+	"QuerySiteMobileSecuritySettings":   "3.19",
+	"UpdateSiteMobileSecuritySettings":  "3.19",
+	"QueryServerExtensionsSettings":     "3.21",
+	"UpdateServerExtensionsSettings":    "3.21",
+	"QuerySiteExtensionsSettings":       "3.21",
+	"UpdateSiteExtensionsSettings":      "3.21",
+	"CreateGroupSet":                    "3.22",
+	"QueryGroupSets":                    "3.22",
+	"QueryGroupSet":                     "3.22",
+	"UpdateGroupSet":                    "3.22",
+	"DeleteGroupSet":                    "3.22",
+	"AddGroupToGroupSet":                "3.22",
+	"RemoveGroupFromGroupSet":           "3.22",
+}
+
+// VersionError is returned when an endpoint needs a newer REST API version
+// than the client is configured for. It matches ErrUnsupportedVersion.
+type VersionError struct {
+	Endpoint string
+	Required string
+	Version  string
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%s requires API %s; client uses API %s", e.Endpoint, e.Required, e.Version)
+}
+
+func (e *VersionError) Unwrap() error {
+	return ErrUnsupportedVersion
+}
+
+// MinimumVersion returns the REST API version the named method needs, or
+// API_VERSION if it is available in every version this package supports.
+func MinimumVersion(endpoint string) string {
+	if required, ok := minimumVersions[endpoint]; ok {
+		return required
+	}
+	return API_VERSION
+}
+
+// Supports reports whether the named method can be used with the client's
+// API version.
+func (api *API) Supports(endpoint string) bool {
+	return api.requireVersion(endpoint) == nil
+}
+
+// requireVersion also checks the endpoint is available on the client's
+// Deployment, so Supports answers for both.
+func (api *API) requireVersion(endpoint string) error {
+	if err := api.requireDeployment(endpoint); err != nil {
+		return err
+	}
+	required, ok := minimumVersions[endpoint]
+	if !ok || compareVersions(api.Version, required) >= 0 {
+		return nil
+	}
+	return &VersionError{Endpoint: endpoint, Required: required, Version: api.Version}
+}
+
+// compareVersions orders dotted version strings numerically. A version that
+// can't be parsed compares as new enough, so the server gets to decide.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := 0, 0
+		var err error
+		if i < len(as) {
+			if x, err = strconv.Atoi(as[i]); err != nil {
+				return 1
+			}
+		}
+		if i < len(bs) {
+			if y, err = strconv.Atoi(bs[i]); err != nil {
+				return 1
+			}
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}